@@ -0,0 +1,26 @@
+/*
+Package calendars
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package calendars
+
+import (
+	"errors"
+	baseAppErrors "github.com/iliaskaras/fare-estimation/app/infrastructure/errors"
+)
+
+type CalendarError struct {
+	baseAppErrors.BaseAppError
+}
+
+func NewCalendarError(err error, additionalInfo string) CalendarError {
+	return CalendarError{
+		BaseAppError: baseAppErrors.NewBaseAppError(err, additionalInfo),
+	}
+}
+
+var (
+	UnsupportedCalendarFileType = errors.New("unsupported calendar file type")
+	InvalidHolidayDate          = errors.New("invalid holiday date")
+	UnsupportedRecurrence       = errors.New("unsupported holiday recurrence")
+)
@@ -0,0 +1,32 @@
+/*
+Package calendars
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package calendars
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+var supportedCalendarFileTypes = []string{".csv", ".ics"}
+
+// GetCalendarService is responsible for returning the correct CalendarService implementor,
+// based on the calendar file type provided.
+func GetCalendarService(filePath string) (CalendarService, error) {
+	fileExtension := strings.ToLower(filepath.Ext(filePath))
+
+	if fileExtension == ".csv" {
+		return newCSVCalendarService(), nil
+	}
+
+	if fileExtension == ".ics" {
+		return newICSCalendarService(), nil
+	}
+
+	return nil, NewCalendarError(
+		UnsupportedCalendarFileType,
+		"provided calendar file type: "+fileExtension+", "+
+			"must be one of the: "+strings.Join(supportedCalendarFileTypes[:], ",")+" \n",
+	)
+}
@@ -0,0 +1,41 @@
+/*
+Package calendars
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package calendars
+
+import (
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Tests the GetCalendarService return the implementor matching the provided calendar file type.
+func TestGetCalendarServiceReturnServiceByFileType(t *testing.T) {
+	calendarService, err := GetCalendarService("holidays.csv")
+	assert.NoError(t, err)
+	assert.Equal(t, "*calendars.csvCalendarService", reflect.TypeOf(calendarService).String())
+
+	calendarService, err = GetCalendarService("holidays.ics")
+	assert.NoError(t, err)
+	assert.Equal(t, "*calendars.icsCalendarService", reflect.TypeOf(calendarService).String())
+}
+
+// Tests the GetCalendarService return a CalendarError when the calendar file type is unsupported.
+func TestGetCalendarServiceReturnCalendarErrorWhenFileTypeIsInvalid(t *testing.T) {
+	calendarService, err := GetCalendarService("holidays.txt")
+	assert.Error(t, err)
+
+	_, ok := err.(CalendarError)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, nil, calendarService)
+	assert.Equal(
+		t,
+		NewCalendarError(
+			UnsupportedCalendarFileType,
+			"provided calendar file type: .txt, "+
+				"must be one of the: "+strings.Join(supportedCalendarFileTypes[:], ",")+" \n",
+		), err,
+	)
+}
@@ -0,0 +1,68 @@
+/*
+Package calendars
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package calendars
+
+import (
+	"time"
+)
+
+const (
+	dateLayout         = "2006-01-02"
+	monthDayLayout     = "01-02"
+	defaultHolidayName = "holiday"
+)
+
+// yearlyHoliday is a holiday that recurs every year on the same month and day, from its first year onwards.
+type yearlyHoliday struct {
+	name      string
+	firstYear int
+}
+
+// Calendar holds the public holidays, keyed by their calendar date.
+type Calendar struct {
+	holidays       map[string]string
+	yearlyHolidays map[string]yearlyHoliday
+}
+
+func NewCalendar() *Calendar {
+	return &Calendar{
+		holidays:       make(map[string]string),
+		yearlyHolidays: make(map[string]yearlyHoliday),
+	}
+}
+
+// Add adds the calendar date of the provided date as a holiday.
+func (c *Calendar) Add(date time.Time, name string) {
+	if name == "" {
+		name = defaultHolidayName
+	}
+	c.holidays[date.Format(dateLayout)] = name
+}
+
+// AddYearly adds the month and day of the provided date as a holiday that recurs every year,
+// starting from the year of the provided date.
+func (c *Calendar) AddYearly(date time.Time, name string) {
+	if name == "" {
+		name = defaultHolidayName
+	}
+	c.yearlyHolidays[date.Format(monthDayLayout)] = yearlyHoliday{
+		name:      name,
+		firstYear: date.Year(),
+	}
+}
+
+// Holiday returns the name of the holiday that falls on the calendar date of t, in t's location,
+// and whether there is one.
+func (c *Calendar) Holiday(t time.Time) (string, bool) {
+	if name, ok := c.holidays[t.Format(dateLayout)]; ok {
+		return name, true
+	}
+
+	if holiday, ok := c.yearlyHolidays[t.Format(monthDayLayout)]; ok && t.Year() >= holiday.firstYear {
+		return holiday.name, true
+	}
+
+	return "", false
+}
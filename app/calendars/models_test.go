@@ -0,0 +1,47 @@
+/*
+Package calendars
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package calendars
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// Tests the Calendar Holiday method finds the holidays on the calendar date of the provided time.
+func TestCalendarHoliday(t *testing.T) {
+	athens, _ := time.LoadLocation("Europe/Athens")
+	calendar := NewCalendar()
+	calendar.Add(time.Date(2022, 4, 25, 0, 0, 0, 0, time.UTC), "Easter Monday")
+	calendar.Add(time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC), "")
+
+	name, ok := calendar.Holiday(time.Date(2022, 4, 25, 23, 59, 0, 0, athens))
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "Easter Monday", name)
+
+	name, ok = calendar.Holiday(time.Date(2022, 5, 1, 12, 0, 0, 0, athens))
+	assert.Equal(t, true, ok)
+	assert.Equal(t, defaultHolidayName, name)
+
+	// 2022-04-25 22:30 UTC is already 2022-04-26 in Athens.
+	_, ok = calendar.Holiday(time.Date(2022, 4, 25, 22, 30, 0, 0, time.UTC).In(athens))
+	assert.Equal(t, false, ok)
+}
+
+// Tests the Calendar Holiday method finds the yearly holidays from their first year onwards.
+func TestCalendarYearlyHoliday(t *testing.T) {
+	calendar := NewCalendar()
+	calendar.AddYearly(time.Date(2020, 12, 25, 0, 0, 0, 0, time.UTC), "Christmas Day")
+
+	name, ok := calendar.Holiday(time.Date(2022, 12, 25, 10, 0, 0, 0, time.UTC))
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "Christmas Day", name)
+
+	_, ok = calendar.Holiday(time.Date(2019, 12, 25, 10, 0, 0, 0, time.UTC))
+	assert.Equal(t, false, ok)
+
+	_, ok = calendar.Holiday(time.Date(2022, 12, 24, 10, 0, 0, 0, time.UTC))
+	assert.Equal(t, false, ok)
+}
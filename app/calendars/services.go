@@ -0,0 +1,245 @@
+/*
+Package calendars
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package calendars
+
+import (
+	"bufio"
+	"encoding/csv"
+	baseAppErrors "github.com/iliaskaras/fare-estimation/app/infrastructure/errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	icsDateLayout = "20060102"
+)
+
+type CalendarService interface {
+	Load(filePath string) (*Calendar, error)
+}
+
+// csvCalendarService is the CalendarService implementor responsible for .csv holiday calendars.
+type csvCalendarService struct{}
+
+func newCSVCalendarService() CalendarService {
+	return &csvCalendarService{}
+}
+
+// Load parses a .csv file that contains a holiday per row, in the format: date,name
+// with the date formatted as YYYY-MM-DD and the name being optional. A header row
+// starting with "date" is skipped.
+func (cs *csvCalendarService) Load(filePath string) (*Calendar, error) {
+	file, err := openCalendarFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	calendar := NewCalendar()
+	line := 0
+
+	for {
+		fileRecord, err := reader.Read()
+		line += 1
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, NewCalendarError(err, "failure on reading calendar records")
+		}
+
+		rawDate := strings.TrimSpace(fileRecord[0])
+		if line == 1 && strings.EqualFold(rawDate, "date") {
+			continue
+		}
+
+		date, err := time.Parse(dateLayout, rawDate)
+		if err != nil {
+			return nil, NewCalendarError(
+				InvalidHolidayDate,
+				"holiday date: "+rawDate+" must be formatted as YYYY-MM-DD",
+			)
+		}
+
+		name := ""
+		if len(fileRecord) > 1 {
+			name = strings.TrimSpace(fileRecord[1])
+		}
+		calendar.Add(date, name)
+	}
+
+	return calendar, nil
+}
+
+// icsCalendarService is the CalendarService implementor responsible for .ics (iCalendar) holiday calendars.
+type icsCalendarService struct{}
+
+func newICSCalendarService() CalendarService {
+	return &icsCalendarService{}
+}
+
+// icsEvent holds the properties of a VEVENT that are needed for a holiday.
+type icsEvent struct {
+	start   string
+	end     string
+	summary string
+	rrule   string
+}
+
+// Load parses an .ics file, adding the dates of each of its VEVENT as holidays. An event
+// spans from its DTSTART date up to, but not including, its DTEND date. The events that
+// recur with a yearly RRULE are added as yearly holidays, any other recurrence is rejected.
+func (cs *icsCalendarService) Load(filePath string) (*Calendar, error) {
+	file, err := openCalendarFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	lines, err := unfoldICSLines(file)
+	if err != nil {
+		return nil, NewCalendarError(err, "failure on reading calendar lines")
+	}
+
+	calendar := NewCalendar()
+	var event *icsEvent
+
+	for _, line := range lines {
+		name, value := splitICSProperty(line)
+
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			event = &icsEvent{}
+		case name == "END" && value == "VEVENT":
+			if event != nil {
+				if err := addICSEvent(calendar, event); err != nil {
+					return nil, err
+				}
+			}
+			event = nil
+		case event == nil:
+			continue
+		case name == "DTSTART":
+			event.start = value
+		case name == "DTEND":
+			event.end = value
+		case name == "SUMMARY":
+			event.summary = value
+		case name == "RRULE":
+			event.rrule = value
+		}
+	}
+
+	return calendar, nil
+}
+
+// addICSEvent adds the dates that the icsEvent spans to the Calendar.
+func addICSEvent(calendar *Calendar, event *icsEvent) error {
+	start, err := parseICSDate(event.start)
+	if err != nil {
+		return err
+	}
+
+	end := start.AddDate(0, 0, 1)
+	if event.end != "" {
+		if end, err = parseICSDate(event.end); err != nil {
+			return err
+		}
+	}
+
+	yearly := false
+	if event.rrule != "" {
+		if !strings.Contains(strings.ToUpper(event.rrule), "FREQ=YEARLY") {
+			return NewCalendarError(
+				UnsupportedRecurrence,
+				"holiday: "+event.summary+" recurrence: "+event.rrule+" is not yearly",
+			)
+		}
+		yearly = true
+	}
+
+	for date := start; date.Before(end) || date.Equal(start); date = date.AddDate(0, 0, 1) {
+		if yearly {
+			calendar.AddYearly(date, event.summary)
+		} else {
+			calendar.Add(date, event.summary)
+		}
+	}
+
+	return nil
+}
+
+// parseICSDate parses the date part of an iCalendar DATE or DATE-TIME value, e.g. 20220101 or 20220101T000000Z.
+func parseICSDate(value string) (time.Time, error) {
+	if len(value) < len(icsDateLayout) {
+		return time.Time{}, NewCalendarError(InvalidHolidayDate, "holiday date: "+value+" is not an iCalendar date")
+	}
+
+	date, err := time.Parse(icsDateLayout, value[:len(icsDateLayout)])
+	if err != nil {
+		return time.Time{}, NewCalendarError(InvalidHolidayDate, "holiday date: "+value+" is not an iCalendar date")
+	}
+
+	return date, nil
+}
+
+// splitICSProperty splits an iCalendar content line into its property name, without
+// any parameters, and its value. E.g. DTSTART;VALUE=DATE:20220101 -> DTSTART, 20220101.
+func splitICSProperty(line string) (string, string) {
+	separator := strings.Index(line, ":")
+	if separator < 0 {
+		return strings.ToUpper(line), ""
+	}
+
+	name := line[:separator]
+	if parameters := strings.Index(name, ";"); parameters >= 0 {
+		name = name[:parameters]
+	}
+
+	return strings.ToUpper(name), strings.TrimSpace(line[separator+1:])
+}
+
+// unfoldICSLines reads the iCalendar content lines, joining the folded lines that
+// continue on the next line starting with a space or a tab.
+func unfoldICSLines(reader io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(reader)
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, scanner.Err()
+}
+
+// openCalendarFile opens the calendar file found in filePath for reading.
+func openCalendarFile(filePath string) (*os.File, error) {
+	if filePath == "" {
+		return nil, baseAppErrors.NewBaseAppError(
+			baseAppErrors.InvalidInputError,
+			"calendar file path is missing",
+		)
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, NewCalendarError(err, "unable to open the calendar file")
+	}
+
+	return file, nil
+}
@@ -0,0 +1,115 @@
+/*
+Package calendars
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package calendars
+
+import (
+	"github.com/Flaque/filet"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// Tests the csvCalendarService.Load loads the holidays of a .csv file, skipping its header.
+func TestCSVCalendarServiceLoadSuccessfulExecution(t *testing.T) {
+	defer filet.CleanUp(t)
+	testCalendarFile := filet.TmpFile(
+		t,
+		"",
+		"date,name\n"+
+			"2022-03-25,Independence Day\n"+
+			"2022-04-25\n",
+	)
+
+	calendar, err := newCSVCalendarService().Load(testCalendarFile.Name())
+	assert.NoError(t, err)
+
+	expectedCalendar := NewCalendar()
+	expectedCalendar.Add(time.Date(2022, 3, 25, 0, 0, 0, 0, time.UTC), "Independence Day")
+	expectedCalendar.Add(time.Date(2022, 4, 25, 0, 0, 0, 0, time.UTC), "")
+
+	assert.Equal(t, expectedCalendar, calendar)
+}
+
+// Tests the csvCalendarService.Load return a CalendarError when a date is not formatted as YYYY-MM-DD.
+func TestCSVCalendarServiceLoadReturnErrorWhenDateIsInvalid(t *testing.T) {
+	defer filet.CleanUp(t)
+	testCalendarFile := filet.TmpFile(t, "", "25/03/2022,Independence Day\n")
+
+	calendar, err := newCSVCalendarService().Load(testCalendarFile.Name())
+	assert.Error(t, err)
+
+	assert.Nil(t, calendar)
+	assert.Equal(
+		t,
+		NewCalendarError(InvalidHolidayDate, "holiday date: 25/03/2022 must be formatted as YYYY-MM-DD"),
+		err,
+	)
+}
+
+// Tests the icsCalendarService.Load loads the holidays of the VEVENTs of an .ics file.
+func TestICSCalendarServiceLoadSuccessfulExecution(t *testing.T) {
+	defer filet.CleanUp(t)
+	testCalendarFile := filet.TmpFile(
+		t,
+		"",
+		"BEGIN:VCALENDAR\r\n"+
+			"VERSION:2.0\r\n"+
+			"BEGIN:VEVENT\r\n"+
+			"DTSTART;VALUE=DATE:20220422\r\n"+
+			"DTEND;VALUE=DATE:20220426\r\n"+
+			"SUMMARY:Orthodox Easter\r\n"+
+			"  Holidays\r\n"+
+			"END:VEVENT\r\n"+
+			"BEGIN:VEVENT\r\n"+
+			"DTSTART:20220815T000000Z\r\n"+
+			"SUMMARY:Assumption Day\r\n"+
+			"END:VEVENT\r\n"+
+			"BEGIN:VEVENT\r\n"+
+			"DTSTART;VALUE=DATE:20201225\r\n"+
+			"RRULE:FREQ=YEARLY\r\n"+
+			"SUMMARY:Christmas Day\r\n"+
+			"END:VEVENT\r\n"+
+			"END:VCALENDAR\r\n",
+	)
+
+	calendar, err := newICSCalendarService().Load(testCalendarFile.Name())
+	assert.NoError(t, err)
+
+	expectedCalendar := NewCalendar()
+	expectedCalendar.Add(time.Date(2022, 4, 22, 0, 0, 0, 0, time.UTC), "Orthodox Easter Holidays")
+	expectedCalendar.Add(time.Date(2022, 4, 23, 0, 0, 0, 0, time.UTC), "Orthodox Easter Holidays")
+	expectedCalendar.Add(time.Date(2022, 4, 24, 0, 0, 0, 0, time.UTC), "Orthodox Easter Holidays")
+	expectedCalendar.Add(time.Date(2022, 4, 25, 0, 0, 0, 0, time.UTC), "Orthodox Easter Holidays")
+	expectedCalendar.Add(time.Date(2022, 8, 15, 0, 0, 0, 0, time.UTC), "Assumption Day")
+	expectedCalendar.AddYearly(time.Date(2020, 12, 25, 0, 0, 0, 0, time.UTC), "Christmas Day")
+
+	assert.Equal(t, expectedCalendar, calendar)
+}
+
+// Tests the icsCalendarService.Load return a CalendarError when an event recurs other than yearly.
+func TestICSCalendarServiceLoadReturnErrorWhenRecurrenceIsUnsupported(t *testing.T) {
+	defer filet.CleanUp(t)
+	testCalendarFile := filet.TmpFile(
+		t,
+		"",
+		"BEGIN:VCALENDAR\n"+
+			"BEGIN:VEVENT\n"+
+			"DTSTART;VALUE=DATE:20220101\n"+
+			"RRULE:FREQ=WEEKLY\n"+
+			"SUMMARY:Every Saturday\n"+
+			"END:VEVENT\n"+
+			"END:VCALENDAR\n",
+	)
+
+	calendar, err := newICSCalendarService().Load(testCalendarFile.Name())
+	assert.Error(t, err)
+
+	assert.Nil(t, calendar)
+	assert.Equal(
+		t,
+		NewCalendarError(UnsupportedRecurrence, "holiday: Every Saturday recurrence: FREQ=WEEKLY is not yearly"),
+		err,
+	)
+}
@@ -0,0 +1,179 @@
+/*
+Package cmd
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"github.com/iliaskaras/fare-estimation/app/comparisons"
+	"github.com/iliaskaras/fare-estimation/app/fares"
+	"github.com/iliaskaras/fare-estimation/app/files"
+	"github.com/iliaskaras/fare-estimation/app/rides"
+	"github.com/spf13/cobra"
+	"os"
+	"time"
+)
+
+var compareCmd = &cobra.Command{
+	Use:   "compare",
+	Short: "Compare the fares of the rides under two tariffs.",
+	Long: `Modelling the effect of a new tariff on the fares of historical rides.
+
+The following steps are executed:
+
+- Filtering the provided file out of erroneous entries once, with the max speed of
+  the --tariff-a tariff.
+- Pricing the filtered ride segments under both the --tariff-a and the --tariff-b
+  tariffs (.yaml, .yml or .json), the built-in tariff being used for the omitted one,
+  each along with its own --tariff-versions-a/b and --zones-a/b, and the --holidays
+  shared by both.
+- Writing the fare of each ride under both tariffs, along with their difference and
+  its percentage, in the --output file, or printing them when it is omitted, sorted
+  by ride.
+- Printing the totals and the means of the fares under both tariffs, along with the
+  percentiles of the fare changes.
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		start := time.Now()
+
+		filePath, _ := cmd.Flags().GetString("filepath")
+		output, _ := cmd.Flags().GetString("output")
+		tariffAPath, _ := cmd.Flags().GetString("tariff-a")
+		tariffBPath, _ := cmd.Flags().GetString("tariff-b")
+		tariffVersionsAPath, _ := cmd.Flags().GetString("tariff-versions-a")
+		tariffVersionsBPath, _ := cmd.Flags().GetString("tariff-versions-b")
+		zonesAPath, _ := cmd.Flags().GetString("zones-a")
+		zonesBPath, _ := cmd.Flags().GetString("zones-b")
+		timezone, _ := cmd.Flags().GetString("timezone")
+		holidaysPath, _ := cmd.Flags().GetString("holidays")
+		fareStrategy, _ := cmd.Flags().GetString("fare-strategy")
+
+		if filePath == "" {
+			fmt.Println("You need to provide the file path, -h for more information")
+			os.Exit(1)
+		}
+
+		fileService, err := files.GetFileService(filePath)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		var outputService files.FileService
+		if output != "" {
+			if outputService, err = files.GetFileService(output); err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+		}
+
+		// The tariffs are loaded and validated before any of the rows is processed.
+		tariffA, tariffVersionsA, tariffZonesA, err := loadTariffs(
+			tariffAPath,
+			tariffVersionsAPath,
+			zonesAPath,
+			timezone,
+			"",
+			holidaysPath,
+		)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		tariffB, tariffVersionsB, tariffZonesB, err := loadTariffs(
+			tariffBPath,
+			tariffVersionsBPath,
+			zonesBPath,
+			timezone,
+			"",
+			holidaysPath,
+		)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+
+		comparisonService, err := comparisons.GetComparisonService(
+			fareStrategy,
+			fares.FareCalculatorConfig{Tariff: tariffA, TariffVersions: tariffVersionsA, TariffZones: tariffZonesA},
+			fares.FareCalculatorConfig{Tariff: tariffB, TariffVersions: tariffVersionsB, TariffZones: tariffZonesB},
+		)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+
+		rideSegmentsChan := filterRides(
+			fileService,
+			filePath,
+			getRidePositionService(
+				rides.SpeedPositionFilter,
+				rides.PositionFilterConfig{MaxKMPerHour: tariffA.MaxSpeedKMH, RecoveryRejections: rides.RecoveryRejections},
+			),
+			nil,
+		)
+		rideComparisons := comparisonService.Compare(rideSegmentsChan)
+
+		records := make([][]string, len(rideComparisons))
+		for i, rideComparison := range rideComparisons {
+			records[i] = rideComparison.ToStrings()
+		}
+
+		if outputService != nil {
+			err = outputService.WriteRecords(output, comparisons.RideComparisonHeader(), records)
+		} else {
+			err = files.PrintRecords(comparisons.RideComparisonHeader(), records)
+		}
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+
+		for _, line := range comparisonService.Summarize(rideComparisons).Report() {
+			fmt.Println(line)
+		}
+
+		t := time.Now()
+		elapsed := t.Sub(start)
+
+		fmt.Println("Fare comparison took:", elapsed.Milliseconds(), "ms")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(compareCmd)
+
+	compareCmd.Flags().StringP(
+		"filepath", "f", "", "The file path contains information about rides",
+	)
+	compareCmd.Flags().StringP(
+		"output", "o", "", "The output .csv file path of the fare of each ride under both tariffs, printed if omitted",
+	)
+	compareCmd.Flags().String(
+		"tariff-a", "", "The current tariff file path (.yaml, .yml or .json), the built-in tariff is used if omitted",
+	)
+	compareCmd.Flags().String(
+		"tariff-b", "", "The new tariff file path (.yaml, .yml or .json), the built-in tariff is used if omitted",
+	)
+	compareCmd.Flags().String(
+		"tariff-versions-a", "", "The current tariff versions file path (.yaml, .yml or .json)",
+	)
+	compareCmd.Flags().String(
+		"tariff-versions-b", "", "The new tariff versions file path (.yaml, .yml or .json)",
+	)
+	compareCmd.Flags().String(
+		"timezone", "", "The IANA timezone that day and night time are decided in, overrides the tariffs' timezone",
+	)
+	compareCmd.Flags().String(
+		"holidays", "", "The public holidays calendar file path (.csv or .ics)",
+	)
+	compareCmd.Flags().String(
+		"zones-a", "", "The current tariff zones GeoJSON file path, each zone polygon having a name and a tariff property",
+	)
+	compareCmd.Flags().String(
+		"zones-b", "", "The new tariff zones GeoJSON file path, each zone polygon having a name and a tariff property",
+	)
+	compareCmd.Flags().String(
+		"fare-strategy", fares.TariffStrategy, "The registered pricing model that the fares are estimated with",
+	)
+}
@@ -6,12 +6,16 @@ package cmd
 
 import (
 	"fmt"
-	"github.com/iliaskaras/fare-estimation/app/distances"
 	"github.com/iliaskaras/fare-estimation/app/fares"
 	"github.com/iliaskaras/fare-estimation/app/files"
+	"github.com/iliaskaras/fare-estimation/app/payouts"
+	"github.com/iliaskaras/fare-estimation/app/pools"
+	"github.com/iliaskaras/fare-estimation/app/promotions"
 	"github.com/iliaskaras/fare-estimation/app/rides"
+	"github.com/iliaskaras/fare-estimation/app/uncertainties"
 	"github.com/spf13/cobra"
 	"os"
+	"strings"
 	"sync"
 	"time"
 )
@@ -23,17 +27,63 @@ var estimateCmd = &cobra.Command{
 
 The following steps are executed:
 
-- Filtering the provided file out of erroneous entries. Erroneous entry is the second
-  part of a ride segment, that the calculated speed is greater than 100km/hour.
-  The distance is calculated using the Haversine formula.
-- Calculating the fare estimations out of the filtered ride segments, making a new
-  file with all the ride fare estimations.
+- Validating the ride positions of the provided file with the --validation rules, and
+  handling the ones with a duplicate or out of order timestamp with the --timestamp-policy.
+- Filtering the provided file out of erroneous entries with the --filter. By default, an
+  erroneous entry is the second part of a ride segment, that the calculated speed is
+  greater than the tariff's max speed, 100km/hour by default. The distance is calculated
+  using the Haversine formula.
+- Calculating the fare estimations out of the filtered ride segments with the --tariff,
+  making a new file with all the ride fare estimations, along with the extra columns of
+  the features enabled with the flags below. The README describes the rules of each one.
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		start := time.Now()
 
 		filePath, _ := cmd.Flags().GetString("filepath")
 		output, _ := cmd.Flags().GetString("output")
+		tariffPath, _ := cmd.Flags().GetString("tariff")
+		tariffVersionsPath, _ := cmd.Flags().GetString("tariff-versions")
+		timezone, _ := cmd.Flags().GetString("timezone")
+		rounding, _ := cmd.Flags().GetString("rounding")
+		holidaysPath, _ := cmd.Flags().GetString("holidays")
+		zonesPath, _ := cmd.Flags().GetString("zones")
+		fixedRoutesPath, _ := cmd.Flags().GetString("fixed-routes")
+		tollsPath, _ := cmd.Flags().GetString("tolls")
+		surgePath, _ := cmd.Flags().GetString("surge")
+		breakdown, _ := cmd.Flags().GetBool("breakdown")
+		fareStrategy, _ := cmd.Flags().GetString("fare-strategy")
+		gpsAccuracy, _ := cmd.Flags().GetFloat64("gps-accuracy")
+		samples, _ := cmd.Flags().GetInt("samples")
+		seed, _ := cmd.Flags().GetInt64("seed")
+		passengersPath, _ := cmd.Flags().GetString("passengers")
+		splitPolicy, _ := cmd.Flags().GetString("split-policy")
+		payoutsPath, _ := cmd.Flags().GetString("payouts")
+		promotionsPath, _ := cmd.Flags().GetString("promotions")
+		promoRulesPath, _ := cmd.Flags().GetString("promo-rules")
+		recoveryRejections, _ := cmd.Flags().GetInt("recovery-rejections")
+		positionFilters, _ := cmd.Flags().GetString("filter")
+		maxAcceleration, _ := cmd.Flags().GetFloat64("max-acceleration")
+		medianWindow, _ := cmd.Flags().GetInt("median-window")
+		kalmanNoise, _ := cmd.Flags().GetFloat64("kalman-noise")
+		timestampPolicy, _ := cmd.Flags().GetString("timestamp-policy")
+		rejectsPath, _ := cmd.Flags().GetString("rejects")
+		validationRules, _ := cmd.Flags().GetString("validation")
+		rawServiceArea, _ := cmd.Flags().GetString("service-area")
+
+		var overrides speedOverrides
+		if cmd.Flags().Changed("idle-speed") {
+			idleSpeedKMH, _ := cmd.Flags().GetFloat64("idle-speed")
+			overrides.idleSpeedKMH = &idleSpeedKMH
+		}
+		if cmd.Flags().Changed("max-speed") {
+			maxSpeedKMH, _ := cmd.Flags().GetFloat64("max-speed")
+			overrides.maxSpeedKMH = &maxSpeedKMH
+		}
+		if cmd.Flags().Changed("free-waiting") {
+			freeWaiting, _ := cmd.Flags().GetDuration("free-waiting")
+			overrides.freeWaiting = &freeWaiting
+		}
 
 		if filePath == "" {
 			fmt.Println("You need to provide the file path, -h for more information")
@@ -51,49 +101,249 @@ The following steps are executed:
 			os.Exit(1)
 		}
 
-		ridePositionsChan := make(chan []rides.RidePosition)
-		rideSegmentsChan := make(chan []rides.RideSegment)
-		faresChan := make(chan fares.Fare)
+		// The tariffs are loaded and validated before any of the rows is processed.
+		tariff, tariffVersions, tariffZones, err := loadTariffs(
+			tariffPath,
+			tariffVersionsPath,
+			zonesPath,
+			timezone,
+			rounding,
+			holidaysPath,
+		)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		if err := overrideSpeeds(tariff, tariffVersions, tariffZones, overrides); err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
 
-		go func() {
-			err := fileService.Read(filePath, ridePositionsChan)
+		fixedRoutes, err := loadFixedRoutes(fixedRoutesPath)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		tollGates, err := loadTolls(tollsPath)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		surgeSchedule, err := loadSurge(surgePath)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+
+		var serviceArea *rides.BoundingBox
+		if rawServiceArea != "" {
+			serviceArea, err = parseBoundingBox(rawServiceArea)
 			if err != nil {
-				fmt.Printf(err.Error())
+				fmt.Println("You need to provide the service area as minLat,minLng,maxLat,maxLng, -h for more information")
 				os.Exit(1)
 			}
-		}()
+		}
 
-		distanceCalculatorMethod, _ := distances.GetDistanceCalculatorService(distances.HaversineMethod)
-		ridePositionService, err := rides.GetRidePositionService(
-			distanceCalculatorMethod,
-		)
+		var rejectsService files.FileService
+		var rejectionLog *rides.RejectionLog
+		if rejectsPath != "" {
+			rejectsService, err = files.GetFileService(rejectsPath)
+			if err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+			rejectionLog = rides.NewRejectionLog()
+		}
+
+		positionFilterConfig := rides.PositionFilterConfig{
+			TimestampPolicy:        rides.TimestampPolicy(timestampPolicy),
+			MaxKMPerHour:           tariff.MaxSpeedKMH,
+			RecoveryRejections:     recoveryRejections,
+			MaxAcceleration:        maxAcceleration,
+			MedianWindow:           medianWindow,
+			KalmanMeasurementNoise: kalmanNoise,
+			RejectionLog:           rejectionLog,
+			ValidationRules:        validationRules,
+			ServiceArea:            serviceArea,
+		}
+		ridePositionService := getRidePositionService(positionFilters, positionFilterConfig)
 
-		var wg sync.WaitGroup
+		fareCalculatorConfig := fares.FareCalculatorConfig{
+			Tariff:         tariff,
+			TariffVersions: tariffVersions,
+			TariffZones:    tariffZones,
+			FixedRoutes:    fixedRoutes,
+			Tolls:          tollGates,
+			Surge:          surgeSchedule,
+		}
+		fareService, err := fares.GetFareService(fareStrategy, fareCalculatorConfig)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
 
-		for x := 1; x <= 4; x++ {
-			wg.Add(1)
-			go func() {
-				defer wg.Done()
-				ridePositionService.FilterOnSegmentSpeed(ridePositionsChan, rideSegmentsChan)
-			}()
+		var uncertaintyService *uncertainties.UncertaintyService
+		if cmd.Flags().Changed("gps-accuracy") {
+			uncertaintyService, err = uncertainties.GetUncertaintyService(
+				fareStrategy,
+				fareCalculatorConfig,
+				positionFilters,
+				positionFilterConfig,
+				gpsAccuracy,
+				samples,
+				seed,
+			)
+			if err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+		}
+
+		var promotionService *promotions.PromotionService
+		if promotionsPath != "" || promoRulesPath != "" {
+			if promotionsPath == "" || promoRulesPath == "" {
+				fmt.Println("You need to provide both the promotions and the promo rules, -h for more information")
+				os.Exit(1)
+			}
+
+			promotionService, err = promotions.GetPromotionService(promoRulesPath, promotionsPath)
+			if err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+		}
+
+		var payoutService *payouts.PayoutService
+		if payoutsPath != "" {
+			payoutService, err = payouts.GetPayoutService(payoutsPath, tariff.Currency)
+			if err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+		}
+
+		if passengersPath != "" {
+			if uncertaintyService != nil || promotionService != nil || payoutService != nil {
+				fmt.Println(
+					"The --passengers flag cannot be combined with --gps-accuracy, --promotions or --payouts, " +
+						"-h for more information",
+				)
+				os.Exit(1)
+			}
+
+			outputService, err := files.GetFileService(output)
+			if err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+			passengers, err := pools.LoadPassengers(passengersPath)
+			if err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+			poolService, err := pools.GetPoolService(fareStrategy, fareCalculatorConfig, passengers, splitPolicy)
+			if err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
 
+			passengerFares := poolService.Split(filterRides(fileService, filePath, ridePositionService, rejectionLog))
+
+			records := make([][]string, len(passengerFares))
+			for i, passengerFare := range passengerFares {
+				records[i] = passengerFare.ToStrings()
+			}
+
+			if err := outputService.WriteRecords(output, pools.PassengerFareHeader(), records); err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+
+			for _, line := range ridePositionService.Summary().Report() {
+				fmt.Println(line)
+			}
+			if rejectionLog != nil {
+				writeRejects(rejectsService, rejectsPath, rejectionLog)
+			}
+
+			fmt.Println("Fare estimation took:", time.Now().Sub(start).Milliseconds(), "ms")
+			return
+		}
+
+		fareColumns := fares.FareColumns{
+			DayTypes:      holidaysPath != "" || hasDayTypeRates(tariff, tariffVersions, tariffZones),
+			Zones:         zonesPath != "",
+			FixedRoute:    fixedRoutesPath != "",
+			TariffVersion: tariffVersionsPath != "",
+			Surge:         surgePath != "",
+			Uncertainty:   uncertaintyService != nil,
+			Discount:      promotionService != nil,
+			Payout:        payoutService != nil,
+			Breakdown:     breakdown,
 		}
 
-		go func() {
-			wg.Wait()
-			close(rideSegmentsChan)
-		}()
+		faresChan := make(chan fares.Fare)
 
-		fareService := fares.NewFareService()
+		if uncertaintyService != nil {
+			// The fare ranges need the unfiltered positions of each ride, so the filtering on segment
+			// speed happens along with the fare estimation.
+			ridePositionsChan := readRides(fileService, filePath, rejectionLog)
 
-		go fareService.Estimate(rideSegmentsChan, faresChan)
+			var wg sync.WaitGroup
 
-		_, err = fileService.Write(output, faresChan)
+			for x := 1; x <= 4; x++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					uncertaintyService.Estimate(ridePositionsChan, faresChan)
+				}()
+			}
+
+			go func() {
+				wg.Wait()
+				close(faresChan)
+			}()
+		} else {
+			rideSegmentsChan := filterRides(fileService, filePath, ridePositionService, rejectionLog)
+
+			go fareService.Estimate(rideSegmentsChan, faresChan)
+		}
+
+		var outputFaresChan <-chan fares.Fare = faresChan
+		if promotionService != nil {
+			discountedFaresChan := make(chan fares.Fare)
+			go promotionService.Apply(outputFaresChan, discountedFaresChan)
+			outputFaresChan = discountedFaresChan
+		}
+		if payoutService != nil {
+			payoutFaresChan := make(chan fares.Fare)
+			go payoutService.Settle(outputFaresChan, payoutFaresChan)
+			outputFaresChan = payoutFaresChan
+		}
+
+		_, err = fileService.Write(output, outputFaresChan, fareColumns)
 		if err != nil {
 			fmt.Printf(err.Error())
 			os.Exit(1)
 		}
 
+		filterSummary := ridePositionService.Summary()
+		if uncertaintyService != nil {
+			filterSummary = uncertaintyService.Summary()
+		}
+		for _, line := range filterSummary.Report() {
+			fmt.Println(line)
+		}
+		if rejectionLog != nil {
+			writeRejects(rejectsService, rejectsPath, rejectionLog)
+		}
+
+		if payoutService != nil {
+			for _, line := range payoutService.Summary().Report() {
+				fmt.Println(line)
+			}
+		}
+
 		t := time.Now()
 		elapsed := t.Sub(start)
 
@@ -110,4 +360,100 @@ func init() {
 	estimateCmd.Flags().StringP(
 		"output", "o", "", "The output file path that the fare estimations will be persisted",
 	)
+	estimateCmd.Flags().StringP(
+		"tariff", "t", "", "The tariff file path (.yaml, .yml or .json), the built-in tariff is used if omitted",
+	)
+	estimateCmd.Flags().String(
+		"tariff-versions", "", "The tariff versions file path (.yaml, .yml or .json), each version having an effective_from time",
+	)
+	estimateCmd.Flags().String(
+		"timezone", "", "The IANA timezone that day and night time are decided in, overrides the tariff's timezone",
+	)
+	estimateCmd.Flags().String(
+		"rounding", "", "The rounding mode of the fares (half_up, half_even or up_to_0.05), overrides the tariff's rounding",
+	)
+	estimateCmd.Flags().Float64(
+		"idle-speed", rides.MinimumHourKM, "The speed in km/hour that a ride segment is charged as idle at or below, overrides the tariff's idle speed",
+	)
+	estimateCmd.Flags().Float64(
+		"max-speed", rides.MaxKMPerHour, "The speed in km/hour that a ride segment is rejected as erroneous above, overrides the tariff's max speed",
+	)
+	estimateCmd.Flags().Int(
+		"recovery-rejections", rides.RecoveryRejections, "The consecutive agreeing rejected positions that the speed filter is re-anchored on, below 2 disabling it",
+	)
+	estimateCmd.Flags().String(
+		"timestamp-policy", string(rides.RejectTimestamps), "The way that the positions with a duplicate or out of order timestamp are handled (reject, merge or sort)",
+	)
+	estimateCmd.Flags().String(
+		"filter", rides.SpeedPositionFilter, "The comma separated filters that the positions are cleaned with in order (speed, acceleration, median or kalman)",
+	)
+	estimateCmd.Flags().String(
+		"validation",
+		strings.Join(rides.DefaultValidationRules(), ","),
+		"The comma separated rules that the positions are validated with ("+
+			strings.Join(rides.DefaultValidationRules(), ", ")+"), or "+rides.NoValidationRules,
+	)
+	estimateCmd.Flags().String(
+		"service-area", "", "The service area as minLat,minLng,maxLat,maxLng, that the positions outside of are dropped",
+	)
+	estimateCmd.Flags().String(
+		"rejects", "", "The rejects file path that every discarded position is written to, with its line, reason and speed",
+	)
+	estimateCmd.Flags().Float64(
+		"max-acceleration", rides.MaxAcceleration, "The acceleration in meters/second² that a position is rejected as erroneous above by the acceleration filter",
+	)
+	estimateCmd.Flags().Int(
+		"median-window", rides.MedianWindow, "The odd number of the positions that the median filter takes the median of",
+	)
+	estimateCmd.Flags().Float64(
+		"kalman-noise", rides.KalmanMeasurementNoise, "The accuracy in meters of the positions that the kalman filter expects",
+	)
+	estimateCmd.Flags().Duration(
+		"free-waiting", 0, "The idle time per ride that is not charged, e.g. 2m, overrides the tariff's free waiting time",
+	)
+	estimateCmd.Flags().String(
+		"holidays", "", "The public holidays calendar file path (.csv or .ics)",
+	)
+	estimateCmd.Flags().String(
+		"zones", "", "The tariff zones GeoJSON file path, each zone polygon having a name and a tariff property",
+	)
+	estimateCmd.Flags().String(
+		"fixed-routes", "", "The fixed-price routes file path (.yaml, .yml or .json)",
+	)
+	estimateCmd.Flags().String(
+		"tolls", "", "The toll gates file path (.yaml, .yml or .json)",
+	)
+	estimateCmd.Flags().String(
+		"surge", "", "The surge schedule file path (.yaml, .yml or .json), with the multipliers by time window and area",
+	)
+	estimateCmd.Flags().String(
+		"fare-strategy", fares.TariffStrategy, "The registered pricing model that the fares are estimated with",
+	)
+	estimateCmd.Flags().Float64(
+		"gps-accuracy", 0, "The radius in meters of the GPS noise that the fare range of each ride is estimated with",
+	)
+	estimateCmd.Flags().Int(
+		"samples", uncertainties.DefaultSamples, "The number of times that the positions of each ride are perturbed with the GPS noise",
+	)
+	estimateCmd.Flags().Int64(
+		"seed", uncertainties.DefaultSeed, "The seed of the GPS noise, the same seed reproducing the same fare ranges",
+	)
+	estimateCmd.Flags().String(
+		"passengers", "", "The pooled ride passengers .csv file path, each row being ride_id,passenger_id,pickup,dropoff",
+	)
+	estimateCmd.Flags().String(
+		"split-policy", string(pools.EqualSplit), "The way that the shared stretches of a pooled ride are split (equal or distance)",
+	)
+	estimateCmd.Flags().String(
+		"promotions", "", "The promotions .csv file path, each row being ride_id,promo_code",
+	)
+	estimateCmd.Flags().String(
+		"promo-rules", "", "The promo rules file path (.yaml, .yml or .json), with the discount of each promo code",
+	)
+	estimateCmd.Flags().String(
+		"payouts", "", "The payouts file path (.yaml, .yml or .json), with the VAT rate, pricing and commission rate",
+	)
+	estimateCmd.Flags().Bool(
+		"breakdown", false, "Write the fare components of each ride as extra columns",
+	)
 }
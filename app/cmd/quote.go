@@ -0,0 +1,212 @@
+/*
+Package cmd
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/iliaskaras/fare-estimation/app/fares"
+	"github.com/iliaskaras/fare-estimation/app/files"
+	"github.com/iliaskaras/fare-estimation/app/quotes"
+	"github.com/iliaskaras/fare-estimation/app/rides"
+	"github.com/iliaskaras/fare-estimation/app/zones"
+	"github.com/spf13/cobra"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var quoteCmd = &cobra.Command{
+	Use:   "quote",
+	Short: "Quote the fare range of a ride before it starts.",
+	Long: `Quoting the fare range of a ride from an origin to a destination, departing at a time.
+
+The following steps are executed:
+
+- Learning how the rides of the --filepath history file travelled, that is their
+  distance over the straight line distance, their moving speed and their idle time,
+  after filtering it out of erroneous entries as the estimate command does.
+- Pricing the ride from the --origin to the --destination departing at the --departure
+  time, e.g. --origin 37.9838,23.7275 --departure 2014-07-17T10:00:00+03:00, as the
+  rides of the history travelled at the same hour of the day, with the same tariff logic
+  and flags as the estimate command. The low, expected and high fares are printed.
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		filePath, _ := cmd.Flags().GetString("filepath")
+		rawOrigin, _ := cmd.Flags().GetString("origin")
+		rawDestination, _ := cmd.Flags().GetString("destination")
+		rawDeparture, _ := cmd.Flags().GetString("departure")
+		tariffPath, _ := cmd.Flags().GetString("tariff")
+		tariffVersionsPath, _ := cmd.Flags().GetString("tariff-versions")
+		timezone, _ := cmd.Flags().GetString("timezone")
+		rounding, _ := cmd.Flags().GetString("rounding")
+		holidaysPath, _ := cmd.Flags().GetString("holidays")
+		zonesPath, _ := cmd.Flags().GetString("zones")
+		fixedRoutesPath, _ := cmd.Flags().GetString("fixed-routes")
+		tollsPath, _ := cmd.Flags().GetString("tolls")
+		surgePath, _ := cmd.Flags().GetString("surge")
+		fareStrategy, _ := cmd.Flags().GetString("fare-strategy")
+
+		if filePath == "" {
+			fmt.Println("You need to provide the history file path, -h for more information")
+			os.Exit(1)
+		}
+
+		origin, err := parsePoint(rawOrigin)
+		if err != nil {
+			fmt.Println("You need to provide the origin as lat,lng, -h for more information")
+			os.Exit(1)
+		}
+		destination, err := parsePoint(rawDestination)
+		if err != nil {
+			fmt.Println("You need to provide the destination as lat,lng, -h for more information")
+			os.Exit(1)
+		}
+
+		departure := time.Now()
+		if rawDeparture != "" {
+			if departure, err = time.Parse(time.RFC3339, rawDeparture); err != nil {
+				fmt.Println("You need to provide the departure as an RFC 3339 time, -h for more information")
+				os.Exit(1)
+			}
+		}
+
+		fileService, err := files.GetFileService(filePath)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+
+		tariff, tariffVersions, tariffZones, err := loadTariffs(
+			tariffPath,
+			tariffVersionsPath,
+			zonesPath,
+			timezone,
+			rounding,
+			holidaysPath,
+		)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		fixedRoutes, err := loadFixedRoutes(fixedRoutesPath)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		tollGates, err := loadTolls(tollsPath)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		surgeSchedule, err := loadSurge(surgePath)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+
+		quoteService, err := quotes.GetQuoteService(
+			fareStrategy,
+			fares.FareCalculatorConfig{
+				Tariff:         tariff,
+				TariffVersions: tariffVersions,
+				TariffZones:    tariffZones,
+				FixedRoutes:    fixedRoutes,
+				Tolls:          tollGates,
+				Surge:          surgeSchedule,
+			},
+		)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+
+		travelModel := quoteService.Learn(
+			filterRides(
+				fileService,
+				filePath,
+				getRidePositionService(
+					rides.SpeedPositionFilter,
+					rides.PositionFilterConfig{MaxKMPerHour: tariff.MaxSpeedKMH, RecoveryRejections: rides.RecoveryRejections},
+				),
+				nil,
+			),
+		)
+
+		quote, err := quoteService.Quote(travelModel, *origin, *destination, departure)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+
+		for _, line := range quote.Report() {
+			fmt.Println(line)
+		}
+	},
+}
+
+// parsePoint returns the zones.Point of a lat,lng pair, e.g. 37.9838,23.7275.
+func parsePoint(rawPoint string) (*zones.Point, error) {
+	coordinates := strings.Split(rawPoint, ",")
+	if len(coordinates) != 2 {
+		return nil, errors.New("invalid point: " + rawPoint)
+	}
+
+	lat, errLat := strconv.ParseFloat(strings.TrimSpace(coordinates[0]), 64)
+	lng, errLng := strconv.ParseFloat(strings.TrimSpace(coordinates[1]), 64)
+	if errLat != nil || errLng != nil {
+		return nil, errors.New("invalid point: " + rawPoint)
+	}
+
+	return zones.NewPoint(lat, lng), nil
+}
+
+func init() {
+	rootCmd.AddCommand(quoteCmd)
+
+	quoteCmd.Flags().StringP(
+		"filepath", "f", "", "The file path contains the historical rides that the quote is learned from",
+	)
+	quoteCmd.Flags().String(
+		"origin", "", "The origin of the ride as lat,lng",
+	)
+	quoteCmd.Flags().String(
+		"destination", "", "The destination of the ride as lat,lng",
+	)
+	quoteCmd.Flags().String(
+		"departure", "", "The departure time of the ride in RFC 3339 format, now if omitted",
+	)
+	quoteCmd.Flags().StringP(
+		"tariff", "t", "", "The tariff file path (.yaml, .yml or .json), the built-in tariff is used if omitted",
+	)
+	quoteCmd.Flags().String(
+		"tariff-versions", "", "The tariff versions file path (.yaml, .yml or .json), each version having an effective_from time",
+	)
+	quoteCmd.Flags().String(
+		"timezone", "", "The IANA timezone that day and night time are decided in, overrides the tariff's timezone",
+	)
+	quoteCmd.Flags().String(
+		"rounding", "", "The rounding mode of the fares (half_up, half_even or up_to_0.05), overrides the tariff's rounding",
+	)
+	quoteCmd.Flags().String(
+		"holidays", "", "The public holidays calendar file path (.csv or .ics)",
+	)
+	quoteCmd.Flags().String(
+		"zones", "", "The tariff zones GeoJSON file path, each zone polygon having a name and a tariff property",
+	)
+	quoteCmd.Flags().String(
+		"fixed-routes", "", "The fixed-price routes file path (.yaml, .yml or .json)",
+	)
+	quoteCmd.Flags().String(
+		"tolls", "", "The toll gates file path (.yaml, .yml or .json)",
+	)
+	quoteCmd.Flags().String(
+		"surge", "", "The surge schedule file path (.yaml, .yml or .json), with the multipliers by time window and area",
+	)
+	quoteCmd.Flags().String(
+		"fare-strategy", fares.TariffStrategy, "The registered pricing model that the fares are estimated with",
+	)
+}
@@ -0,0 +1,126 @@
+/*
+Package cmd
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/iliaskaras/fare-estimation/app/distances"
+	"github.com/iliaskaras/fare-estimation/app/files"
+	"github.com/iliaskaras/fare-estimation/app/rides"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// readRides reads the RidePositions of filePath with the fileService, returning the channel that the
+// RidePositions of each RideID are pushed to. The channel is closed once the whole file has been read.
+// The rows that cannot be parsed are added to the rejectionLog, if any.
+func readRides(
+	fileService files.FileService,
+	filePath string,
+	rejectionLog *rides.RejectionLog,
+) <-chan []rides.RidePosition {
+	ridePositionsChan := make(chan []rides.RidePosition)
+
+	go func() {
+		err := fileService.Read(filePath, ridePositionsChan, rejectionLog)
+		if err != nil {
+			fmt.Printf(err.Error())
+			os.Exit(1)
+		}
+	}()
+
+	return ridePositionsChan
+}
+
+// getRidePositionService returns the RidePositionService that cleans the RidePositions with the comma
+// separated positionFilters, the speed gate with the max speed of the positionFilterConfig by default,
+// exiting when the positionFilters or the positionFilterConfig are invalid.
+func getRidePositionService(
+	positionFilters string,
+	positionFilterConfig rides.PositionFilterConfig,
+) *rides.RidePositionService {
+	distanceCalculatorMethod, _ := distances.GetDistanceCalculatorService(distances.HaversineMethod)
+	ridePositionService, err := rides.GetRidePositionService(
+		distanceCalculatorMethod,
+		positionFilters,
+		positionFilterConfig,
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	return ridePositionService
+}
+
+// filterRides reads the RidePositions of filePath with the fileService, and filters them with the
+// ridePositionService, returning the channel that the filtered RideSegments of each RideID are pushed
+// to. The channel is closed once every RideID has been filtered, and the ridePositionService's
+// FilterSummary and the rejectionLog, that the rows that cannot be parsed are added to, can be read then.
+func filterRides(
+	fileService files.FileService,
+	filePath string,
+	ridePositionService *rides.RidePositionService,
+	rejectionLog *rides.RejectionLog,
+) <-chan []rides.RideSegment {
+	ridePositionsChan := readRides(fileService, filePath, rejectionLog)
+	rideSegmentsChan := make(chan []rides.RideSegment)
+
+	var wg sync.WaitGroup
+
+	for x := 1; x <= 4; x++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ridePositionService.FilterOnSegmentSpeed(ridePositionsChan, rideSegmentsChan)
+		}()
+
+	}
+
+	go func() {
+		wg.Wait()
+		close(rideSegmentsChan)
+	}()
+
+	return rideSegmentsChan
+}
+
+// writeRejects writes the RejectedPositions of the rejectionLog, sorted by line, to the rejectsPath file
+// with the rejectsService, exiting when it cannot be written.
+func writeRejects(rejectsService files.FileService, rejectsPath string, rejectionLog *rides.RejectionLog) {
+	rejectedPositions := rejectionLog.RejectedPositions()
+	records := make([][]string, len(rejectedPositions))
+	for i, rejectedPosition := range rejectedPositions {
+		records[i] = rejectedPosition.ToStrings()
+	}
+
+	if err := rejectsService.WriteRecords(rejectsPath, rides.RejectedPositionHeader(), records); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+}
+
+// parseBoundingBox returns the rides.BoundingBox of a minLat,minLng,maxLat,maxLng quadruple, e.g.
+// 37.8,23.5,38.2,24.1.
+func parseBoundingBox(rawBoundingBox string) (*rides.BoundingBox, error) {
+	rawCoordinates := strings.Split(rawBoundingBox, ",")
+	if len(rawCoordinates) != 4 {
+		return nil, errors.New("invalid bounding box: " + rawBoundingBox)
+	}
+
+	var coordinates [4]float64
+	for i, rawCoordinate := range rawCoordinates {
+		coordinate, err := strconv.ParseFloat(strings.TrimSpace(rawCoordinate), 64)
+		if err != nil {
+			return nil, errors.New("invalid bounding box: " + rawBoundingBox)
+		}
+		coordinates[i] = coordinate
+	}
+
+	return rides.NewBoundingBox(coordinates[0], coordinates[1], coordinates[2], coordinates[3]), nil
+}
@@ -0,0 +1,53 @@
+/*
+Package cmd
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package cmd
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/routes"
+	"github.com/iliaskaras/fare-estimation/app/surges"
+	"github.com/iliaskaras/fare-estimation/app/tolls"
+)
+
+// loadFixedRoutes loads and validates the fixed-price routes of fixedRoutesPath, none when the path is empty.
+func loadFixedRoutes(fixedRoutesPath string) ([]routes.FixedRoute, error) {
+	if fixedRoutesPath == "" {
+		return nil, nil
+	}
+
+	fixedRouteService, err := routes.GetFixedRouteService(fixedRoutesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return fixedRouteService.Load(fixedRoutesPath)
+}
+
+// loadTolls loads and validates the toll gates of tollsPath, none when the path is empty.
+func loadTolls(tollsPath string) ([]tolls.Toll, error) {
+	if tollsPath == "" {
+		return nil, nil
+	}
+
+	tollService, err := tolls.GetTollService(tollsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return tollService.Load(tollsPath)
+}
+
+// loadSurge loads and validates the surge schedule of surgePath, none when the path is empty.
+func loadSurge(surgePath string) (*surges.SurgeSchedule, error) {
+	if surgePath == "" {
+		return nil, nil
+	}
+
+	surgeService, err := surges.GetSurgeService(surgePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return surgeService.Load(surgePath)
+}
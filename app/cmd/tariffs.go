@@ -0,0 +1,190 @@
+/*
+Package cmd
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package cmd
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/calendars"
+	"github.com/iliaskaras/fare-estimation/app/money"
+	"github.com/iliaskaras/fare-estimation/app/tariffs"
+	"time"
+)
+
+// loadTariff loads and validates the tariff of tariffPath, or returns the built-in tariff when the path is empty.
+func loadTariff(tariffPath string) (*tariffs.Tariff, error) {
+	if tariffPath == "" {
+		return tariffs.DefaultTariff(), nil
+	}
+
+	tariffService, err := tariffs.GetTariffService(tariffPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return tariffService.Load(tariffPath)
+}
+
+// loadTariffs loads and validates the tariff of tariffPath, or the built-in tariff when the path is
+// empty, along with the tariff versions of versionsPath and the tariff zones of zonesPath when
+// provided. The timezone, the rounding mode and the holidays calendar of holidaysPath, when provided,
+// are applied on the tariff and on every version and zone tariff. The version tariffs must be in the
+// currency of the tariff, like the zone tariffs, which must be in the billing mode of the tariff too,
+// while the zones cannot be combined with the versions, as the zone tariffs are not versioned.
+func loadTariffs(
+	tariffPath string,
+	versionsPath string,
+	zonesPath string,
+	timezone string,
+	rounding string,
+	holidaysPath string,
+) (*tariffs.Tariff, []tariffs.TariffVersion, []tariffs.TariffZone, error) {
+	if versionsPath != "" && zonesPath != "" {
+		return nil, nil, nil, tariffs.NewTariffError(
+			tariffs.UnversionedTariffZones,
+			"the --zones cannot be combined with the --tariff-versions, the zone tariffs not being versioned",
+		)
+	}
+
+	tariff, err := loadTariff(tariffPath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var tariffVersions []tariffs.TariffVersion
+	if versionsPath != "" {
+		if tariffVersions, err = tariffs.LoadTariffVersions(versionsPath); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	var tariffZones []tariffs.TariffZone
+	if zonesPath != "" {
+		if tariffZones, err = tariffs.LoadTariffZones(zonesPath, tariff.Currency, tariff.BillingMode); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	for _, tariffVersion := range tariffVersions {
+		if tariffVersion.Tariff.Currency != tariff.Currency {
+			return nil, nil, nil, tariffs.NewTariffError(
+				tariffs.MismatchedCurrency,
+				"tariff version: "+tariffVersion.ID+" currency: "+tariffVersion.Tariff.Currency.Code+
+					" must be the tariff currency: "+tariff.Currency.Code,
+			)
+		}
+	}
+
+	allTariffs := collectTariffs(tariff, tariffVersions, tariffZones)
+
+	if timezone != "" {
+		location, err := tariffs.LoadLocation(timezone)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		for _, t := range allTariffs {
+			t.Location = location
+		}
+	}
+
+	if rounding != "" {
+		roundingMode, err := money.ParseRoundingMode(rounding)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		for _, t := range allTariffs {
+			t.Rounding = roundingMode
+		}
+	}
+
+	if holidaysPath != "" {
+		calendarService, err := calendars.GetCalendarService(holidaysPath)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		holidays, err := calendarService.Load(holidaysPath)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		for _, t := range allTariffs {
+			t.Holidays = holidays
+		}
+	}
+
+	return tariff, tariffVersions, tariffZones, nil
+}
+
+// collectTariffs returns the tariff along with every version and zone tariff.
+func collectTariffs(
+	tariff *tariffs.Tariff,
+	tariffVersions []tariffs.TariffVersion,
+	tariffZones []tariffs.TariffZone,
+) []*tariffs.Tariff {
+	allTariffs := []*tariffs.Tariff{tariff}
+	for _, tariffVersion := range tariffVersions {
+		allTariffs = append(allTariffs, tariffVersion.Tariff)
+	}
+	for _, tariffZone := range tariffZones {
+		allTariffs = append(allTariffs, tariffZone.Tariff)
+	}
+
+	return allTariffs
+}
+
+// speedOverrides are the speeds and the free waiting time set on the command line, nil when not set.
+type speedOverrides struct {
+	idleSpeedKMH *float64
+	maxSpeedKMH  *float64
+	freeWaiting  *time.Duration
+}
+
+// overrideSpeeds applies the speedOverrides on the tariff and on every version and zone tariff, validating them.
+func overrideSpeeds(
+	tariff *tariffs.Tariff,
+	tariffVersions []tariffs.TariffVersion,
+	tariffZones []tariffs.TariffZone,
+	overrides speedOverrides,
+) error {
+	for _, t := range collectTariffs(tariff, tariffVersions, tariffZones) {
+		if overrides.idleSpeedKMH != nil {
+			t.IdleSpeedKMH = *overrides.idleSpeedKMH
+		}
+		if overrides.maxSpeedKMH != nil {
+			t.MaxSpeedKMH = *overrides.maxSpeedKMH
+		}
+		if overrides.freeWaiting != nil {
+			t.FreeWaitingSecs = overrides.freeWaiting.Seconds()
+		}
+
+		if t.IdleSpeedKMH < 0 || t.FreeWaitingSecs < 0 {
+			return tariffs.NewTariffError(
+				tariffs.NegativeTariffValue,
+				"the idle speed and the free waiting time must not be negative",
+			)
+		}
+		if err := tariffs.ValidateSpeeds(t.IdleSpeedKMH, t.MaxSpeedKMH); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// hasDayTypeRates returns whether any of the tariffs has rates of its own for a day type.
+func hasDayTypeRates(
+	tariff *tariffs.Tariff,
+	tariffVersions []tariffs.TariffVersion,
+	tariffZones []tariffs.TariffZone,
+) bool {
+	for _, t := range collectTariffs(tariff, tariffVersions, tariffZones) {
+		if len(t.DayTypeRates) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
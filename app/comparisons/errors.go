@@ -0,0 +1,24 @@
+/*
+Package comparisons
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package comparisons
+
+import (
+	"errors"
+	baseAppErrors "github.com/iliaskaras/fare-estimation/app/infrastructure/errors"
+)
+
+type ComparisonError struct {
+	baseAppErrors.BaseAppError
+}
+
+func NewComparisonError(err error, additionalInfo string) ComparisonError {
+	return ComparisonError{
+		BaseAppError: baseAppErrors.NewBaseAppError(err, additionalInfo),
+	}
+}
+
+var (
+	MismatchedCurrency = errors.New("mismatched currency")
+)
@@ -0,0 +1,39 @@
+/*
+Package comparisons
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package comparisons
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/fares"
+)
+
+// GetComparisonService is responsible for initializing and injecting all the dependencies of the
+// ComparisonService, pricing the rides with the fare strategy under the FareCalculatorConfig A and B,
+// whose tariffs must be in the same currency for their fares to be compared.
+func GetComparisonService(
+	fareStrategy string,
+	fareCalculatorConfigA fares.FareCalculatorConfig,
+	fareCalculatorConfigB fares.FareCalculatorConfig,
+) (*ComparisonService, error) {
+	tariffA := fareCalculatorConfigA.Tariff
+	tariffB := fareCalculatorConfigB.Tariff
+	if tariffA.Currency != tariffB.Currency {
+		return nil, NewComparisonError(
+			MismatchedCurrency,
+			"tariff B currency: "+tariffB.Currency.Code+" must be the tariff A currency: "+tariffA.Currency.Code,
+		)
+	}
+
+	fareCalculatorA, err := fares.GetFareCalculator(fareStrategy, fareCalculatorConfigA)
+	if err != nil {
+		return nil, err
+	}
+
+	fareCalculatorB, err := fares.GetFareCalculator(fareStrategy, fareCalculatorConfigB)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewComparisonService(fareCalculatorA, fareCalculatorB, tariffA.Currency), nil
+}
@@ -0,0 +1,63 @@
+/*
+Package comparisons
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package comparisons
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/fares"
+	"github.com/iliaskaras/fare-estimation/app/money"
+	"github.com/iliaskaras/fare-estimation/app/tariffs"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+// Tests the GetComparisonService initializes and returns the ComparisonService.
+func TestGetComparisonService(t *testing.T) {
+	comparisonService, err := GetComparisonService(
+		fares.TariffStrategy,
+		fares.FareCalculatorConfig{Tariff: tariffs.DefaultTariff()},
+		fares.FareCalculatorConfig{Tariff: tariffs.DefaultTariff()},
+	)
+	assert.NoError(t, err)
+
+	returnedServiceType := reflect.TypeOf(comparisonService).String()
+	expectedServiceType := "*comparisons.ComparisonService"
+
+	assert.Equal(t, expectedServiceType, returnedServiceType)
+	assert.Equal(t, money.EUR, comparisonService.currency)
+}
+
+// Tests the GetComparisonService return a ComparisonError when the tariffs are in different currencies.
+func TestGetComparisonServiceReturnErrorWhenCurrenciesMismatch(t *testing.T) {
+	tariffB := tariffs.DefaultTariff()
+	tariffB.Currency = *money.NewCurrency("USD", 2)
+
+	comparisonService, err := GetComparisonService(
+		fares.TariffStrategy,
+		fares.FareCalculatorConfig{Tariff: tariffs.DefaultTariff()},
+		fares.FareCalculatorConfig{Tariff: tariffB},
+	)
+	assert.Error(t, err)
+
+	assert.Nil(t, comparisonService)
+	assert.Equal(
+		t,
+		NewComparisonError(MismatchedCurrency, "tariff B currency: USD must be the tariff A currency: EUR"),
+		err,
+	)
+}
+
+// Tests the GetComparisonService return an error when the fare strategy is not registered.
+func TestGetComparisonServiceReturnErrorWhenFareStrategyIsInvalid(t *testing.T) {
+	comparisonService, err := GetComparisonService(
+		"random",
+		fares.FareCalculatorConfig{Tariff: tariffs.DefaultTariff()},
+		fares.FareCalculatorConfig{Tariff: tariffs.DefaultTariff()},
+	)
+	assert.Error(t, err)
+
+	assert.Nil(t, comparisonService)
+	assert.IsType(t, fares.FareStrategyError{}, err)
+}
@@ -0,0 +1,110 @@
+/*
+Package comparisons
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package comparisons
+
+import (
+	"fmt"
+	"github.com/iliaskaras/fare-estimation/app/money"
+	"strconv"
+)
+
+// SummaryPercentiles are the percentiles of the fare changes reported in a ComparisonSummary.
+var SummaryPercentiles = []float64{10, 25, 50, 75, 90}
+
+// RideComparison is the fare of a single RideID under the tariffs A and B.
+// - FareA, FareB: The fare estimation of the ride under the tariff A and B.
+// - Difference: The FareB minus the FareA.
+// - ChangePercent: The Difference as a percentage of the FareA, zero when the FareA is zero.
+type RideComparison struct {
+	RideID        int
+	FareA         money.Money
+	FareB         money.Money
+	Difference    money.Money
+	ChangePercent float64
+}
+
+func NewRideComparison(rideID int, fareA money.Money, fareB money.Money) *RideComparison {
+	difference := fareB.Sub(fareA)
+
+	changePercent := 0.0
+	if !fareA.IsZero() {
+		changePercent = difference.Float64() / fareA.Float64() * 100
+	}
+
+	return &RideComparison{
+		RideID:        rideID,
+		FareA:         fareA,
+		FareB:         fareB,
+		Difference:    difference,
+		ChangePercent: changePercent,
+	}
+}
+
+// RideComparisonHeader returns the names of the columns that the RideComparison ToStrings returns.
+func RideComparisonHeader() []string {
+	return []string{"ride_id", "fare_a", "fare_b", "difference", "change_percent"}
+}
+
+func (rc RideComparison) ToStrings() []string {
+	return []string{
+		strconv.Itoa(rc.RideID),
+		rc.FareA.String(),
+		rc.FareB.String(),
+		rc.Difference.String(),
+		strconv.FormatFloat(rc.ChangePercent, 'f', 2, 64),
+	}
+}
+
+// PercentileChange is a percentile of the fare changes of the compared rides.
+// - Percentile: The percentile, e.g. 50 for the median.
+// - Difference: The percentile of the differences of the fares.
+// - ChangePercent: The percentile of the changes of the fares, as a percentage.
+type PercentileChange struct {
+	Percentile    float64
+	Difference    money.Money
+	ChangePercent float64
+}
+
+// ComparisonSummary sums up the RideComparisons of the compared rides.
+// - Rides: The number of the compared rides.
+// - TotalA, TotalB, TotalDifference: The sum of the fares under the tariff A and B, and their difference.
+// - TotalChangePercent: The TotalDifference as a percentage of the TotalA.
+// - MeanA, MeanB, MeanDifference: The mean fare under the tariff A and B, and the mean difference.
+// - Percentiles: The PercentileChange of each of the SummaryPercentiles.
+type ComparisonSummary struct {
+	Rides              int
+	TotalA             money.Money
+	TotalB             money.Money
+	TotalDifference    money.Money
+	TotalChangePercent float64
+	MeanA              money.Money
+	MeanB              money.Money
+	MeanDifference     money.Money
+	Percentiles        []PercentileChange
+}
+
+// Report returns the ComparisonSummary as human readable lines.
+func (cs ComparisonSummary) Report() []string {
+	report := []string{
+		fmt.Sprintf("Rides compared: %d", cs.Rides),
+		fmt.Sprintf(
+			"Total: A %s, B %s, difference %s (%.2f%%)",
+			cs.TotalA, cs.TotalB, cs.TotalDifference, cs.TotalChangePercent,
+		),
+		fmt.Sprintf("Mean: A %s, B %s, difference %s", cs.MeanA, cs.MeanB, cs.MeanDifference),
+	}
+
+	for _, percentile := range cs.Percentiles {
+		report = append(
+			report,
+			fmt.Sprintf(
+				"P%v change: difference %s, %.2f%%",
+				percentile.Percentile, percentile.Difference, percentile.ChangePercent,
+			),
+		)
+	}
+
+	return report
+}
@@ -0,0 +1,59 @@
+/*
+Package comparisons
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package comparisons
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/money"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// euros returns the money.Money of the amount in EUR, the currency of the built-in tariff.
+func euros(amount float64) money.Money {
+	return money.FromFloat(amount, money.EUR)
+}
+
+// Tests the NewRideComparison computes the difference and the change of the fares.
+func TestNewRideComparison(t *testing.T) {
+	rideComparison := NewRideComparison(1, euros(10), euros(11.5))
+
+	assert.Equal(t, euros(1.5), rideComparison.Difference)
+	assert.InDelta(t, 15, rideComparison.ChangePercent, 1e-9)
+	assert.Equal(t, []string{"1", "10.00", "11.50", "1.50", "15.00"}, rideComparison.ToStrings())
+	assert.Len(t, RideComparisonHeader(), len(rideComparison.ToStrings()))
+
+	assert.Equal(t, 0.0, NewRideComparison(2, euros(0), euros(3.47)).ChangePercent)
+	assert.Equal(
+		t,
+		[]string{"3", "3.60", "3.47", "-0.13", "-3.61"},
+		NewRideComparison(3, euros(3.60), euros(3.47)).ToStrings(),
+	)
+}
+
+// Tests the ComparisonSummary Report returns the totals, means and percentiles as lines.
+func TestComparisonSummaryReport(t *testing.T) {
+	summary := ComparisonSummary{
+		Rides:              2,
+		TotalA:             euros(20),
+		TotalB:             euros(22),
+		TotalDifference:    euros(2),
+		TotalChangePercent: 10,
+		MeanA:              euros(10),
+		MeanB:              euros(11),
+		MeanDifference:     euros(1),
+		Percentiles:        []PercentileChange{{Percentile: 50, Difference: euros(0.5), ChangePercent: 5}},
+	}
+
+	assert.Equal(
+		t,
+		[]string{
+			"Rides compared: 2",
+			"Total: A 20.00, B 22.00, difference 2.00 (10.00%)",
+			"Mean: A 10.00, B 11.00, difference 1.00",
+			"P50 change: difference 0.50, 5.00%",
+		},
+		summary.Report(),
+	)
+}
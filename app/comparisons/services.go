@@ -0,0 +1,116 @@
+/*
+Package comparisons
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package comparisons
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/fares"
+	"github.com/iliaskaras/fare-estimation/app/money"
+	"github.com/iliaskaras/fare-estimation/app/rides"
+	"github.com/iliaskaras/fare-estimation/app/stats"
+	"sort"
+)
+
+type ComparisonService struct {
+	fareCalculatorA fares.FareCalculator
+	fareCalculatorB fares.FareCalculator
+	currency        money.Currency
+}
+
+func NewComparisonService(
+	fareCalculatorA fares.FareCalculator,
+	fareCalculatorB fares.FareCalculator,
+	currency money.Currency,
+) *ComparisonService {
+	return &ComparisonService{
+		fareCalculatorA: fareCalculatorA,
+		fareCalculatorB: fareCalculatorB,
+		currency:        currency,
+	}
+}
+
+// Compare prices the filtered RideSegments of each RideID under both the tariffs A and B, so that the
+// RideSegments are filtered only once, and returns the RideComparison of every ride sorted by RideID.
+// - Receiver of the channel rideSegmentsChan.
+func (cs *ComparisonService) Compare(rideSegmentsChan <-chan []rides.RideSegment) []RideComparison {
+	var rideComparisons []RideComparison
+
+	for rideSegments := range rideSegmentsChan {
+		// Case where the RideID had only one RidePosition in the input file.
+		if rideSegments == nil {
+			continue
+		}
+
+		fareA := cs.fareCalculatorA.Calculate(rideSegments)
+		fareB := cs.fareCalculatorB.Calculate(rideSegments)
+
+		rideComparisons = append(
+			rideComparisons,
+			*NewRideComparison(fareA.RideID, fareA.Estimation(), fareB.Estimation()),
+		)
+	}
+
+	sort.Slice(rideComparisons, func(i, j int) bool {
+		return rideComparisons[i].RideID < rideComparisons[j].RideID
+	})
+
+	return rideComparisons
+}
+
+// Summarize returns the ComparisonSummary of the rideComparisons, with their totals, means and the
+// SummaryPercentiles of their differences and changes. The percentiles are taken with the nearest rank
+// method, on the differences and on the changes separately.
+func (cs *ComparisonService) Summarize(rideComparisons []RideComparison) ComparisonSummary {
+	summary := ComparisonSummary{
+		Rides:           len(rideComparisons),
+		TotalA:          money.FromFloat(0, cs.currency),
+		TotalB:          money.FromFloat(0, cs.currency),
+		TotalDifference: money.FromFloat(0, cs.currency),
+		MeanA:           money.FromFloat(0, cs.currency),
+		MeanB:           money.FromFloat(0, cs.currency),
+		MeanDifference:  money.FromFloat(0, cs.currency),
+	}
+	if len(rideComparisons) == 0 {
+		return summary
+	}
+
+	differences := make([]money.Money, len(rideComparisons))
+	changePercents := make([]float64, len(rideComparisons))
+
+	for i, rideComparison := range rideComparisons {
+		summary.TotalA = summary.TotalA.Add(rideComparison.FareA)
+		summary.TotalB = summary.TotalB.Add(rideComparison.FareB)
+		differences[i] = rideComparison.Difference
+		changePercents[i] = rideComparison.ChangePercent
+	}
+
+	summary.TotalDifference = summary.TotalB.Sub(summary.TotalA)
+	if !summary.TotalA.IsZero() {
+		summary.TotalChangePercent = summary.TotalDifference.Float64() / summary.TotalA.Float64() * 100
+	}
+
+	rideCount := float64(len(rideComparisons))
+	summary.MeanA = summary.TotalA.Mul(1 / rideCount)
+	summary.MeanB = summary.TotalB.Mul(1 / rideCount)
+	summary.MeanDifference = summary.TotalDifference.Mul(1 / rideCount)
+
+	sort.Slice(differences, func(i, j int) bool {
+		return differences[i].Cmp(differences[j]) < 0
+	})
+	sort.Float64s(changePercents)
+
+	for _, percentile := range SummaryPercentiles {
+		rank := stats.NearestRank(percentile, len(rideComparisons))
+		summary.Percentiles = append(
+			summary.Percentiles,
+			PercentileChange{
+				Percentile:    percentile,
+				Difference:    differences[rank],
+				ChangePercent: changePercents[rank],
+			},
+		)
+	}
+
+	return summary
+}
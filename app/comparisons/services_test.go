@@ -0,0 +1,172 @@
+/*
+Package comparisons
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package comparisons
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/fares"
+	"github.com/iliaskaras/fare-estimation/app/money"
+	"github.com/iliaskaras/fare-estimation/app/rides"
+	"github.com/iliaskaras/fare-estimation/app/tariffs"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// perKMFareCalculator is a fares.FareCalculator charging a fixed amount per km covered.
+type perKMFareCalculator struct {
+	amountPerKM float64
+}
+
+func (fc *perKMFareCalculator) Calculate(rideSegments []rides.RideSegment) fares.Fare {
+	distanceCovered := 0.0
+	for _, rideSegment := range rideSegments {
+		distanceCovered += rideSegment.DistanceCovered
+	}
+
+	return *fares.NewFare(rideSegments[0].RideID, euros(distanceCovered*fc.amountPerKM))
+}
+
+// Tests the ComparisonService.Compare prices each ride under both the tariffs, sorted by RideID.
+func TestCompareSuccessfulExecution(t *testing.T) {
+	rideSegmentsChan := make(chan []rides.RideSegment)
+	comparisonService := NewComparisonService(
+		&perKMFareCalculator{amountPerKM: 1},
+		&perKMFareCalculator{amountPerKM: 1.1},
+		money.EUR,
+	)
+
+	go func() {
+		rideSegmentsChan <- []rides.RideSegment{{RideID: 2, DistanceCovered: 5}}
+		rideSegmentsChan <- nil
+		rideSegmentsChan <- []rides.RideSegment{{RideID: 1, DistanceCovered: 2}, {RideID: 1, DistanceCovered: 8}}
+		close(rideSegmentsChan)
+	}()
+
+	rideComparisons := comparisonService.Compare(rideSegmentsChan)
+
+	assert.Equal(
+		t,
+		[]RideComparison{
+			*NewRideComparison(1, euros(10), euros(11)),
+			*NewRideComparison(2, euros(5), euros(5.5)),
+		},
+		rideComparisons,
+	)
+}
+
+// Tests the ComparisonService.Compare prices the rides with the fare calculators of the GetComparisonService tariffs.
+func TestCompareWithTariffs(t *testing.T) {
+	rideSegmentsChan := make(chan []rides.RideSegment)
+	tariffB := tariffs.DefaultTariff()
+	tariffB.MovingDay = 1.00
+	comparisonService, _ := GetComparisonService(
+		fares.TariffStrategy,
+		fares.FareCalculatorConfig{Tariff: tariffs.DefaultTariff()},
+		fares.FareCalculatorConfig{Tariff: tariffB},
+	)
+
+	go func() {
+		rideSegmentsChan <- []rides.RideSegment{
+			{
+				RideID: 1,
+				RidePositions: [2]rides.RidePosition{
+					{Id: 1, Lat: 37.966660, Lng: 23.728308, Timestamp: 1405594957},
+					{Id: 1, Lat: 37.966660, Lng: 23.728308, Timestamp: 1405595257},
+				},
+				Speed:           60,
+				DistanceCovered: 5,
+			},
+		}
+		close(rideSegmentsChan)
+	}()
+
+	// 1.30 standard fare + 5km * 0.74, against 1.30 standard fare + 5km * 1.00.
+	assert.Equal(
+		t,
+		[]RideComparison{*NewRideComparison(1, euros(5.00), euros(6.30))},
+		comparisonService.Compare(rideSegmentsChan),
+	)
+}
+
+// Tests the ComparisonService.Compare prices the rides with the TariffVersions of each of the tariffs, the
+// versions in force taking the place of the tariffs A and B.
+func TestCompareWithTariffVersions(t *testing.T) {
+	rideSegmentsChan := make(chan []rides.RideSegment)
+	effectiveFrom := time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC)
+	versionTariffB := tariffs.DefaultTariff()
+	versionTariffB.MovingDay = 1.00
+	comparisonService, _ := GetComparisonService(
+		fares.TariffStrategy,
+		fares.FareCalculatorConfig{
+			Tariff:         tariffs.DefaultTariff(),
+			TariffVersions: []tariffs.TariffVersion{*tariffs.NewTariffVersion("a", effectiveFrom, tariffs.DefaultTariff())},
+		},
+		fares.FareCalculatorConfig{
+			Tariff:         tariffs.DefaultTariff(),
+			TariffVersions: []tariffs.TariffVersion{*tariffs.NewTariffVersion("b", effectiveFrom, versionTariffB)},
+		},
+	)
+
+	go func() {
+		rideSegmentsChan <- []rides.RideSegment{
+			{
+				RideID: 1,
+				RidePositions: [2]rides.RidePosition{
+					{Id: 1, Lat: 37.966660, Lng: 23.728308, Timestamp: 1405594957},
+					{Id: 1, Lat: 37.966660, Lng: 23.728308, Timestamp: 1405595257},
+				},
+				Speed:           60,
+				DistanceCovered: 5,
+			},
+		}
+		close(rideSegmentsChan)
+	}()
+
+	// 1.30 standard fare + 5km * 0.74 of the version a, against 1.30 standard fare + 5km * 1.00 of the version b.
+	assert.Equal(
+		t,
+		[]RideComparison{*NewRideComparison(1, euros(5.00), euros(6.30))},
+		comparisonService.Compare(rideSegmentsChan),
+	)
+}
+
+// Tests the ComparisonService.Summarize returns the totals, the means and the nearest rank percentiles.
+func TestSummarize(t *testing.T) {
+	comparisonService := NewComparisonService(nil, nil, money.EUR)
+	var rideComparisons []RideComparison
+	for i, fareB := range []float64{10, 9, 12, 10.5, 13, 11, 10, 14, 10.2, 10} {
+		rideComparisons = append(rideComparisons, *NewRideComparison(i+1, euros(10), euros(fareB)))
+	}
+
+	summary := comparisonService.Summarize(rideComparisons)
+
+	assert.Equal(t, 10, summary.Rides)
+	assert.Equal(t, euros(100), summary.TotalA)
+	assert.Equal(t, euros(109.7), summary.TotalB)
+	assert.Equal(t, euros(9.7), summary.TotalDifference)
+	assert.InDelta(t, 9.7, summary.TotalChangePercent, 1e-9)
+	assert.Equal(t, euros(10), summary.MeanA)
+	assert.Equal(t, euros(10.97), summary.MeanB)
+	assert.Equal(t, euros(0.97), summary.MeanDifference)
+
+	// The sorted differences are: -1, 0, 0, 0, 0.2, 0.5, 1, 2, 3, 4.
+	expectedDifferences := []float64{-1, 0, 0.2, 2, 3}
+	assert.Len(t, summary.Percentiles, len(SummaryPercentiles))
+	for i, percentile := range summary.Percentiles {
+		assert.Equal(t, SummaryPercentiles[i], percentile.Percentile)
+		assert.Equal(t, euros(expectedDifferences[i]), percentile.Difference)
+		assert.InDelta(t, expectedDifferences[i]*10, percentile.ChangePercent, 1e-9)
+	}
+}
+
+// Tests the ComparisonService.Summarize returns zero totals when there are no rides.
+func TestSummarizeWithoutRides(t *testing.T) {
+	summary := NewComparisonService(nil, nil, money.EUR).Summarize(nil)
+
+	assert.Equal(t, 0, summary.Rides)
+	assert.Equal(t, euros(0), summary.TotalA)
+	assert.Equal(t, euros(0), summary.MeanDifference)
+	assert.Nil(t, summary.Percentiles)
+}
@@ -0,0 +1,286 @@
+/*
+Package fares
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package fares
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/money"
+	"github.com/iliaskaras/fare-estimation/app/rides"
+	"github.com/iliaskaras/fare-estimation/app/routes"
+	"github.com/iliaskaras/fare-estimation/app/surges"
+	"github.com/iliaskaras/fare-estimation/app/tariffs"
+	"github.com/iliaskaras/fare-estimation/app/tolls"
+	"math"
+)
+
+const (
+	// DefaultZoneName is the name of the area outside every TariffZone.
+	DefaultZoneName = "default"
+	// DefaultTariffVersionID is the version of the rides that are priced with the default Tariff, being
+	// before the first TariffVersion.
+	DefaultTariffVersionID = "default"
+)
+
+// TariffFareCalculator is the default FareCalculator implementor, that meters the rides with the Tariffs.
+// The rides that follow a FixedRoute are charged with its price instead of the metered fare, and the
+// amounts of the tolls crossed are added on top of either. The metered rides are surged with the
+// multipliers of the SurgeSchedule, if any. With the metered mode, the metered amount of each RideSegment
+// is surged with the multiplier at its start, before the minimum fare, while with the ride mode the fare
+// is surged with the multiplier at the ride's first position, after the minimum fare. Neither the fixed
+// routes nor the tolls are surged.
+type TariffFareCalculator struct {
+	tariff         *tariffs.Tariff
+	tariffVersions []tariffs.TariffVersion
+	tariffZones    []tariffs.TariffZone
+	fixedRoutes    []routes.FixedRoute
+	tolls          []tolls.Toll
+	surge          *surges.SurgeSchedule
+}
+
+func NewTariffFareCalculator(config FareCalculatorConfig) FareCalculator {
+	return &TariffFareCalculator{
+		tariff:         config.Tariff,
+		tariffVersions: config.TariffVersions,
+		tariffZones:    config.TariffZones,
+		fixedRoutes:    config.FixedRoutes,
+		tolls:          config.Tolls,
+		surge:          config.Surge,
+	}
+}
+
+// Calculate estimates the Fare of a single RideID out of its filtered RideSegments, itemizing the amounts
+// charged in the Fare's FareBreakdown. The amounts are accumulated exactly in the ride's money.Currency,
+// and only the fare is rounded.
+func (tc *TariffFareCalculator) Calculate(rideSegments []rides.RideSegment) Fare {
+	startPosition := rideSegments[0].RidePositions[0]
+	defaultTariff, tariffVersionID := tc.tariffInForce(startPosition.Timestamp)
+	rideTariff, _ := tc.tariffAt(defaultTariff, startPosition.Lat, startPosition.Lng)
+	currency := rideTariff.Currency
+
+	breakdown := FareBreakdown{
+		FlagFall:             money.FromFloat(rideTariff.StandardFare, currency),
+		MovingDayAmount:      money.FromFloat(0, currency),
+		MovingNightAmount:    money.FromFloat(0, currency),
+		IdleAmount:           money.FromFloat(0, currency),
+		MeterDropsAdjustment: money.FromFloat(0, currency),
+		MinimumFareTopUp:     money.FromFloat(0, currency),
+		SurgeAmount:          money.FromFloat(0, currency),
+		FixedRouteAdjustment: money.FromFloat(0, currency),
+		TollsAmount:          money.FromFloat(0, currency),
+	}
+	surgeBase := money.FromFloat(0, currency)
+	var dayTypes []tariffs.DayType
+	var zoneNames []string
+	remainingFreeWaitingSecs := rideTariff.FreeWaitingSecs
+
+	for _, rideSegment := range rideSegments {
+		tariff, zoneName := tc.tariffAt(
+			defaultTariff,
+			rideSegment.RidePositions[0].Lat,
+			rideSegment.RidePositions[0].Lng,
+		)
+		zoneNames = appendZoneName(zoneNames, zoneName)
+
+		// Each share of the RideSegment is priced at the rates of its own window and DayType, as moving
+		// above the idle speed of the Tariff, and as idle otherwise.
+		shares := splitOnTariffWindows(
+			rideSegment.RidePositions[0].Timestamp,
+			rideSegment.RidePositions[1].Timestamp,
+			tariff,
+		)
+
+		elapsedTimeSecs := float64(
+			rideSegment.RidePositions[1].Timestamp - rideSegment.RidePositions[0].Timestamp,
+		)
+		moving := rideSegment.Speed > tariff.IdleSpeedKMH
+		segmentAmount := money.FromFloat(0, currency)
+
+		for _, share := range shares {
+			rates := tariff.Rates(share.dayType)
+			distanceCovered := rideSegment.DistanceCovered * share.fraction
+			shareSecs := elapsedTimeSecs * share.fraction
+
+			movingRate := rates.MovingDay
+			if share.window == nightWindow {
+				movingRate = rates.MovingNight
+			}
+
+			// A meter charges whichever of the distance and the time amount is the higher, that is
+			// the distance above the crossover speed of the rates, and the time below it.
+			if tariff.BillingMode == tariffs.MeterBilling {
+				moving = distanceCovered*movingRate >= (shareSecs/rides.HourInSeconds)*rates.Idle
+			}
+
+			if moving {
+				amount := money.FromFloat(distanceCovered*movingRate, currency)
+				segmentAmount = segmentAmount.Add(amount)
+
+				if share.window == nightWindow {
+					breakdown.MovingNightKM += distanceCovered
+					breakdown.MovingNightAmount = breakdown.MovingNightAmount.Add(amount)
+				} else {
+					breakdown.MovingDayKM += distanceCovered
+					breakdown.MovingDayAmount = breakdown.MovingDayAmount.Add(amount)
+				}
+			} else {
+				// The free waiting time is used up by the ride's earliest idle time, before the
+				// rest of it is charged at the Idle rate.
+				freeSecs := math.Min(shareSecs, remainingFreeWaitingSecs)
+				remainingFreeWaitingSecs -= freeSecs
+
+				amount := money.FromFloat(((shareSecs-freeSecs)/rides.HourInSeconds)*rates.Idle, currency)
+				segmentAmount = segmentAmount.Add(amount)
+
+				breakdown.IdleSecs += shareSecs
+				breakdown.FreeWaitingSecs += freeSecs
+				breakdown.IdleAmount = breakdown.IdleAmount.Add(amount)
+			}
+
+			dayTypes = appendDayType(dayTypes, share.dayType)
+		}
+
+		if tc.surge != nil && tc.surge.Mode == surges.MeteredSurge {
+			multiplier := tc.surge.MultiplierAt(
+				rideSegment.RidePositions[0].Timestamp,
+				rideSegment.RidePositions[0].Lat,
+				rideSegment.RidePositions[0].Lng,
+			)
+			surgeBase = surgeBase.Add(segmentAmount)
+			breakdown.SurgeAmount = breakdown.SurgeAmount.Add(segmentAmount.Mul(multiplier - 1))
+		}
+	}
+	if tc.surge != nil && tc.surge.Mode == surges.MeteredSurge {
+		breakdown.SurgeAmount = tc.capSurge(breakdown.SurgeAmount)
+	}
+
+	// A meter charges the metered amount in whole fare drops, so what is short of the next drop
+	// is taken off.
+	if rideTariff.BillingMode == tariffs.MeterBilling {
+		meteredAmount := breakdown.MovingDayAmount.Add(breakdown.MovingNightAmount).Add(breakdown.IdleAmount)
+		breakdown.MeterDropsAdjustment = meteredAmount.
+			FloorTo(money.FromFloat(rideTariff.MeterDropAmount, currency)).
+			Sub(meteredAmount)
+	}
+
+	fareAmount := breakdown.FlagFall.
+		Add(breakdown.MovingDayAmount).
+		Add(breakdown.MovingNightAmount).
+		Add(breakdown.IdleAmount).
+		Add(breakdown.MeterDropsAdjustment).
+		Add(breakdown.SurgeAmount)
+
+	minimumFare := money.FromFloat(rideTariff.MinimumFare, currency)
+	if fareAmount.Cmp(minimumFare) <= 0 {
+		breakdown.MinimumFareTopUp = minimumFare.Sub(fareAmount)
+		fareAmount = minimumFare
+	}
+
+	if tc.surge != nil && tc.surge.Mode == surges.RideSurge {
+		multiplier := tc.surge.MultiplierAt(startPosition.Timestamp, startPosition.Lat, startPosition.Lng)
+		surgeBase = fareAmount
+		breakdown.SurgeAmount = tc.capSurge(fareAmount.Mul(multiplier - 1))
+		fareAmount = fareAmount.Add(breakdown.SurgeAmount)
+	}
+
+	// The metered components are kept in the FareBreakdown of a FixedRoute for reference, along with the
+	// adjustment to its fixed price.
+	fixedRoute := routes.Match(tc.fixedRoutes, rideSegments)
+	if fixedRoute != nil {
+		price := money.FromFloat(fixedRoute.Price, currency)
+		breakdown.FixedRouteAdjustment = price.Sub(fareAmount)
+		fareAmount = price
+	}
+
+	// The tolls are passed through to the rider, so they are added after the minimum fare, the surge and
+	// the fixed price.
+	breakdown.Tolls = tolls.Detect(tc.tolls, rideSegments)
+	for _, toll := range breakdown.Tolls {
+		breakdown.TollsAmount = breakdown.TollsAmount.Add(money.FromFloat(toll.Amount, currency))
+	}
+	fareAmount = fareAmount.Add(breakdown.TollsAmount)
+
+	fare := NewFare(
+		rideSegments[0].RideID,
+		fareAmount.Round(rideTariff.Rounding),
+	)
+	fare.DayTypes = dayTypes
+	fare.Zones = zoneNames
+	fare.TariffVersion = tariffVersionID
+	fare.MinimumFare = minimumFare
+	fare.Rounding = rideTariff.Rounding
+	if fixedRoute != nil {
+		fare.FixedRoute = fixedRoute.Name
+	} else if !surgeBase.IsZero() {
+		fare.SurgeMultiplier = 1 + breakdown.SurgeAmount.Float64()/surgeBase.Float64()
+	}
+	fare.Breakdown = breakdown
+
+	return *fare
+}
+
+// capSurge returns the surgeAmount capped to the MaxSurgeAmount of the TariffFareCalculator's SurgeSchedule.
+func (tc *TariffFareCalculator) capSurge(surgeAmount money.Money) money.Money {
+	if tc.surge.MaxSurgeAmount <= 0 {
+		return surgeAmount
+	}
+
+	maxSurgeAmount := money.FromFloat(tc.surge.MaxSurgeAmount, surgeAmount.Currency)
+	if surgeAmount.Cmp(maxSurgeAmount) > 0 {
+		return maxSurgeAmount
+	}
+
+	return surgeAmount
+}
+
+// tariffInForce returns the default Tariff in force at the provided unix timestamp, along with the ID
+// of its TariffVersion. It is the TariffFareCalculator's Tariff, with the DefaultTariffVersionID, when
+// the timestamp is before the first TariffVersion, and with an empty ID when there are no TariffVersions.
+func (tc *TariffFareCalculator) tariffInForce(timestamp int64) (*tariffs.Tariff, string) {
+	if len(tc.tariffVersions) == 0 {
+		return tc.tariff, ""
+	}
+
+	tariffVersion := tariffs.VersionAt(tc.tariffVersions, timestamp)
+	if tariffVersion == nil {
+		return tc.tariff, DefaultTariffVersionID
+	}
+
+	return tariffVersion.Tariff, tariffVersion.ID
+}
+
+// tariffAt returns the Tariff of the first TariffZone that the position falls in, along with the
+// zone's name, or the defaultTariff and the DefaultZoneName if it falls in none of them. Each RideSegment
+// is priced with the Tariff at its start, while the Tariff at the ride's start decides the currency, the
+// flag fall, the minimum fare, the free waiting time and the meter drops of the whole ride.
+func (tc *TariffFareCalculator) tariffAt(defaultTariff *tariffs.Tariff, lat, lng float64) (*tariffs.Tariff, string) {
+	for _, tariffZone := range tc.tariffZones {
+		if tariffZone.Zone.Contains(lat, lng) {
+			return tariffZone.Tariff, tariffZone.Zone.Name
+		}
+	}
+
+	return defaultTariff, DefaultZoneName
+}
+
+// appendDayType appends the DayType to the dayTypes, unless it is already there.
+func appendDayType(dayTypes []tariffs.DayType, dayType tariffs.DayType) []tariffs.DayType {
+	for _, seenDayType := range dayTypes {
+		if seenDayType == dayType {
+			return dayTypes
+		}
+	}
+
+	return append(dayTypes, dayType)
+}
+
+// appendZoneName appends the zone name to the zoneNames, unless it is already there.
+func appendZoneName(zoneNames []string, zoneName string) []string {
+	for _, seenZoneName := range zoneNames {
+		if seenZoneName == zoneName {
+			return zoneNames
+		}
+	}
+
+	return append(zoneNames, zoneName)
+}
@@ -0,0 +1,26 @@
+/*
+Package fares
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package fares
+
+import (
+	"errors"
+	baseAppErrors "github.com/iliaskaras/fare-estimation/app/infrastructure/errors"
+)
+
+type FareStrategyError struct {
+	baseAppErrors.BaseAppError
+}
+
+func NewFareStrategyError(err error, additionalInfo string) FareStrategyError {
+	return FareStrategyError{
+		BaseAppError: baseAppErrors.NewBaseAppError(err, additionalInfo),
+	}
+}
+
+var (
+	UnsupportedFareStrategy = errors.New("unsupported fare strategy")
+	DuplicateFareStrategy   = errors.New("duplicate fare strategy")
+	InvalidFareStrategy     = errors.New("invalid fare strategy")
+)
@@ -0,0 +1,100 @@
+/*
+Package fares
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package fares
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/tariffs"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	TariffStrategy      = "tariff"
+	defaultFareStrategy = TariffStrategy
+)
+
+// FareCalculatorFactory initializes a FareCalculator out of the FareCalculatorConfig.
+type FareCalculatorFactory func(config FareCalculatorConfig) FareCalculator
+
+var (
+	fareCalculatorFactoriesMutex sync.RWMutex
+	fareCalculatorFactories      = map[string]FareCalculatorFactory{
+		TariffStrategy: NewTariffFareCalculator,
+	}
+)
+
+// RegisterFareCalculator registers the FareCalculatorFactory of a pricing model under the fare strategy name,
+// so that it can be picked with GetFareCalculator. It is meant to be called from the init function of the
+// package that implements the FareCalculator.
+func RegisterFareCalculator(fareStrategy string, factory FareCalculatorFactory) error {
+	if fareStrategy == "" || factory == nil {
+		return NewFareStrategyError(InvalidFareStrategy, "a fare strategy needs a name and a factory")
+	}
+
+	fareCalculatorFactoriesMutex.Lock()
+	defer fareCalculatorFactoriesMutex.Unlock()
+
+	if _, ok := fareCalculatorFactories[fareStrategy]; ok {
+		return NewFareStrategyError(
+			DuplicateFareStrategy,
+			"provided fare strategy: "+fareStrategy+" is already registered",
+		)
+	}
+	fareCalculatorFactories[fareStrategy] = factory
+
+	return nil
+}
+
+// SupportedFareStrategies returns the names of the registered fare strategies, sorted.
+func SupportedFareStrategies() []string {
+	fareCalculatorFactoriesMutex.RLock()
+	defer fareCalculatorFactoriesMutex.RUnlock()
+
+	fareStrategies := make([]string, 0, len(fareCalculatorFactories))
+	for fareStrategy := range fareCalculatorFactories {
+		fareStrategies = append(fareStrategies, fareStrategy)
+	}
+	sort.Strings(fareStrategies)
+
+	return fareStrategies
+}
+
+// GetFareCalculator is responsible for returning the FareCalculator implementor of the fare strategy provided,
+// the TariffStrategy being the default one. The built-in tariffs.DefaultTariff is used when the config has
+// no Tariff, while the rest of the config is optional.
+func GetFareCalculator(fareStrategy string, config FareCalculatorConfig) (FareCalculator, error) {
+	if fareStrategy == "" {
+		fareStrategy = defaultFareStrategy
+	}
+	if config.Tariff == nil {
+		config.Tariff = tariffs.DefaultTariff()
+	}
+
+	fareCalculatorFactoriesMutex.RLock()
+	factory, ok := fareCalculatorFactories[fareStrategy]
+	fareCalculatorFactoriesMutex.RUnlock()
+
+	if !ok {
+		return nil, NewFareStrategyError(
+			UnsupportedFareStrategy,
+			"provided fare strategy: "+fareStrategy+", "+
+				"must be one of the: "+strings.Join(SupportedFareStrategies(), ",")+" \n",
+		)
+	}
+
+	return factory(config), nil
+}
+
+// GetFareService is responsible for initializing and injecting all the dependencies
+// of the FareService, with the FareCalculator of the fare strategy provided.
+func GetFareService(fareStrategy string, config FareCalculatorConfig) (*FareService, error) {
+	fareCalculator, err := GetFareCalculator(fareStrategy, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewFareService(fareCalculator), nil
+}
@@ -0,0 +1,99 @@
+/*
+Package fares
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package fares
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/rides"
+	"github.com/iliaskaras/fare-estimation/app/tariffs"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+// flatFareCalculator is a FareCalculator charging the same amount for every ride.
+type flatFareCalculator struct {
+	tariff *tariffs.Tariff
+}
+
+func (fc *flatFareCalculator) Calculate(rideSegments []rides.RideSegment) Fare {
+	return *NewFare(rideSegments[0].RideID, euros(fc.tariff.MinimumFare))
+}
+
+// Tests the GetFareService initializes and returns the FareService with the TariffFareCalculator of the provided Tariff.
+func TestGetFareService(t *testing.T) {
+	tariff := tariffs.NewTariff(1, 2, 3, 4, 5)
+	fareService, err := GetFareService(TariffStrategy, FareCalculatorConfig{Tariff: tariff})
+	assert.NoError(t, err)
+
+	returnedServiceType := reflect.TypeOf(fareService).String()
+	expectedServiceType := "*fares.FareService"
+
+	assert.Equal(t, expectedServiceType, returnedServiceType)
+	assert.Equal(t, tariff, fareService.fareCalculator.(*TariffFareCalculator).tariff)
+}
+
+// Tests the GetFareCalculator returns the TariffFareCalculator with the built-in Tariff, when no fare strategy
+// and no Tariff are provided.
+func TestGetFareCalculatorDefaults(t *testing.T) {
+	fareCalculator, err := GetFareCalculator("", FareCalculatorConfig{})
+	assert.NoError(t, err)
+
+	returnedCalculatorType := reflect.TypeOf(fareCalculator).String()
+	expectedCalculatorType := "*fares.TariffFareCalculator"
+
+	assert.Equal(t, expectedCalculatorType, returnedCalculatorType)
+	assert.Equal(t, tariffs.DefaultTariff(), fareCalculator.(*TariffFareCalculator).tariff)
+}
+
+// Tests the GetFareCalculator return a FareStrategyError when the fare strategy is not registered.
+func TestGetFareCalculatorReturnErrorWhenFareStrategyIsInvalid(t *testing.T) {
+	fareCalculator, err := GetFareCalculator("invalidFareStrategy", FareCalculatorConfig{})
+	assert.Error(t, err)
+
+	assert.Nil(t, fareCalculator)
+	assert.Equal(
+		t,
+		NewFareStrategyError(
+			UnsupportedFareStrategy,
+			"provided fare strategy: invalidFareStrategy, must be one of the: tariff \n",
+		),
+		err,
+	)
+}
+
+// Tests the RegisterFareCalculator registers a pricing model that the GetFareCalculator returns.
+func TestRegisterFareCalculator(t *testing.T) {
+	defer func() {
+		fareCalculatorFactoriesMutex.Lock()
+		delete(fareCalculatorFactories, "flat")
+		fareCalculatorFactoriesMutex.Unlock()
+	}()
+
+	err := RegisterFareCalculator("flat", func(config FareCalculatorConfig) FareCalculator {
+		return &flatFareCalculator{tariff: config.Tariff}
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"flat", "tariff"}, SupportedFareStrategies())
+
+	fareCalculator, err := GetFareCalculator("flat", FareCalculatorConfig{})
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		*NewFare(7, euros(tariffs.MinimumFare)),
+		fareCalculator.Calculate([]rides.RideSegment{{RideID: 7}}),
+	)
+
+	err = RegisterFareCalculator("flat", func(config FareCalculatorConfig) FareCalculator {
+		return &flatFareCalculator{tariff: config.Tariff}
+	})
+	assert.Equal(
+		t,
+		NewFareStrategyError(DuplicateFareStrategy, "provided fare strategy: flat is already registered"),
+		err,
+	)
+
+	err = RegisterFareCalculator("", nil)
+	assert.Equal(t, NewFareStrategyError(InvalidFareStrategy, "a fare strategy needs a name and a factory"), err)
+}
@@ -5,30 +5,292 @@ Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
 package fares
 
 import (
-	"fmt"
+	"github.com/iliaskaras/fare-estimation/app/money"
+	"github.com/iliaskaras/fare-estimation/app/tariffs"
+	"github.com/iliaskaras/fare-estimation/app/tolls"
 	"strconv"
+	"strings"
 )
 
-const (
-	StandardFare float64 = 1.30
-	MinimumFare  float64 = 3.47
-	Idle         float64 = 11.90
-	MovingDay    float64 = 0.74
-	MovingNight  float64 = 1.30
-)
+// FareBreakdown itemizes the amounts that a Fare's estimation is made of, before it is rounded.
+// - FlagFall: The Tariff's StandardFare.
+// - MovingDayKM, MovingDayAmount: The distance covered while moving at day time, and its amount.
+// - MovingNightKM, MovingNightAmount: The distance covered while moving at night time, and its amount.
+// - IdleSecs, IdleAmount: The time spent idle, and its amount.
+// - FreeWaitingSecs: The part of the IdleSecs that is not charged, being the free waiting time.
+// - MeterDropsAdjustment: The metered amount short of the next fare drop, taken off on the meter billing mode.
+// - MinimumFareTopUp: The amount added for the Fare to reach the Tariff's MinimumFare.
+// - SurgeAmount: The amount added by the surge multipliers, after it is capped.
+// - FixedRouteAdjustment: The amount added, or subtracted, for the Fare to reach the price of its fixed route.
+// - Tolls, TollsAmount: The tolls crossed by the ride, in the order they were crossed, and their total amount.
+type FareBreakdown struct {
+	FlagFall             money.Money
+	MovingDayKM          float64
+	MovingDayAmount      money.Money
+	MovingNightKM        float64
+	MovingNightAmount    money.Money
+	IdleSecs             float64
+	IdleAmount           money.Money
+	FreeWaitingSecs      float64
+	MeterDropsAdjustment money.Money
+	MinimumFareTopUp     money.Money
+	SurgeAmount          money.Money
+	FixedRouteAdjustment money.Money
+	Tolls                []tolls.Toll
+	TollsAmount          money.Money
+}
+
+// FareUncertainty is the range of a Fare's estimation under GPS noise, out of the fares of the ride's
+// perturbed RidePositions.
+// - P5, P50, P95: The 5th, 50th and 95th percentile of the perturbed fares.
+// - Samples: The number of the perturbed fares.
+type FareUncertainty struct {
+	P5      money.Money
+	P50     money.Money
+	P95     money.Money
+	Samples int
+}
+
+// FarePayout is the split of a Fare's estimation between the tax, the platform and the driver.
+// - Net: The estimation without the VAT.
+// - VAT: The VAT charged on the Net.
+// - Gross: The Net along with the VAT, that the passenger pays.
+// - Commission: The platform commission, charged on the Net.
+// - DriverPayout: The Net without the Commission, that the driver is paid.
+type FarePayout struct {
+	Net          money.Money
+	VAT          money.Money
+	Gross        money.Money
+	Commission   money.Money
+	DriverPayout money.Money
+}
 
+// FareDiscount is the discount of a promotion applied on a Fare's estimation.
+// - PromoCode: The code of the promotion applied.
+// - Amount: The amount taken off the estimation.
+type FareDiscount struct {
+	PromoCode string
+	Amount    money.Money
+}
+
+// Fare is the fare estimation of a single RideID, rounded to its currency's minor units.
+// - DayTypes: The DayTypes whose rates were applied on the ride, in chronological order.
+// - Zones: The names of the tariff zones that the ride touched, in chronological order.
+// - FixedRoute: The name of the fixed route that the ride is charged with, empty when it is metered.
+// - TariffVersion: The ID of the tariff version that the ride is priced with, empty when there are none.
+// - SurgeMultiplier: The multiplier that the surged part of the fare is charged with, 1 when it is not surged.
+// - MinimumFare: The minimum fare of the ride, which no discount takes the estimation below.
+// - Rounding: The RoundingMode of the tariff that the estimation is rounded with.
+// - Breakdown: The amounts that the estimation is made of.
+// - Discount: The discount of the promotion applied on the estimation, nil when there is none.
+// - Uncertainty: The range of the estimation under GPS noise, nil when it is not estimated.
+// - Payout: The tax, commission and driver payout of the estimation, nil when it is not computed.
 type Fare struct {
-	RideID     int
-	estimation float64
+	RideID          int
+	estimation      money.Money
+	DayTypes        []tariffs.DayType
+	Zones           []string
+	FixedRoute      string
+	TariffVersion   string
+	SurgeMultiplier float64
+	MinimumFare     money.Money
+	Rounding        money.RoundingMode
+	Breakdown       FareBreakdown
+	Discount        *FareDiscount
+	Uncertainty     *FareUncertainty
+	Payout          *FarePayout
 }
 
-func NewFare(rideID int, estimation float64) *Fare {
+func NewFare(rideID int, estimation money.Money) *Fare {
 	return &Fare{
-		rideID,
-		estimation,
+		RideID:          rideID,
+		estimation:      estimation,
+		SurgeMultiplier: 1,
+	}
+}
+
+// Estimation returns the fare estimation of the RideID.
+func (f Fare) Estimation() money.Money {
+	return f.estimation
+}
+
+// Payable returns the amount that the passenger pays, that is the estimation without its Discount.
+func (f Fare) Payable() money.Money {
+	if f.Discount == nil {
+		return f.estimation
 	}
+
+	return f.estimation.Sub(f.Discount.Amount)
 }
 
-func (f Fare) ToStrings() []string {
-	return []string{strconv.Itoa(f.RideID), fmt.Sprintf("%v", f.estimation)}
+// FareColumns selects the optional columns that are written next to the RideID and the estimation of each Fare.
+// - DayTypes: The DayTypes applied on the ride, separated by "|".
+// - Zones: The tariff zones that the ride touched, separated by "|".
+// - FixedRoute: The fixed route that the ride is charged with, if any.
+// - TariffVersion: The tariff version that the ride is priced with.
+// - Surge: The surge multiplier that the ride is charged with, and the amount added by the surge.
+// - Uncertainty: The 5th, 50th and 95th percentile of the fare under GPS noise, empty when not estimated.
+// - Discount: The promo code applied, the gross fare, the discount and the net fare, the discount being zero
+// when no promotion is applied.
+// - Payout: The net, VAT, gross, commission and driver payout of the fare, empty when not computed.
+// - Breakdown: The FareBreakdown components, with the amounts rounded to minor units and the distances to meters,
+// and the tolls crossed as name:amount separated by "|".
+type FareColumns struct {
+	DayTypes      bool
+	Zones         bool
+	FixedRoute    bool
+	TariffVersion bool
+	Surge         bool
+	Uncertainty   bool
+	Discount      bool
+	Payout        bool
+	Breakdown     bool
+}
+
+// Any returns whether any of the optional columns is selected.
+func (fc FareColumns) Any() bool {
+	return fc.DayTypes || fc.Zones || fc.FixedRoute || fc.TariffVersion || fc.Surge || fc.Uncertainty || fc.Discount || fc.Payout || fc.Breakdown
+}
+
+// Header returns the names of the columns that ToStrings returns for the same FareColumns.
+func (fc FareColumns) Header() []string {
+	header := []string{"ride_id", "fare"}
+
+	if fc.DayTypes {
+		header = append(header, "day_types")
+	}
+	if fc.Zones {
+		header = append(header, "zones")
+	}
+	if fc.FixedRoute {
+		header = append(header, "fixed_route")
+	}
+	if fc.TariffVersion {
+		header = append(header, "tariff_version")
+	}
+	if fc.Surge {
+		header = append(header, "surge_multiplier", "surge_amount")
+	}
+	if fc.Uncertainty {
+		header = append(header, "fare_p5", "fare_p50", "fare_p95")
+	}
+	if fc.Discount {
+		header = append(header, "promo_code", "gross_fare", "discount", "net_fare")
+	}
+	if fc.Payout {
+		header = append(header, "net", "vat", "gross", "commission", "driver_payout")
+	}
+	if fc.Breakdown {
+		header = append(
+			header,
+			"flag_fall",
+			"moving_day_km",
+			"moving_day_amount",
+			"moving_night_km",
+			"moving_night_amount",
+			"idle_secs",
+			"idle_amount",
+			"free_waiting_secs",
+			"meter_drops_adjustment",
+			"minimum_fare_top_up",
+			"fixed_route_adjustment",
+			"tolls_amount",
+			"tolls",
+		)
+	}
+
+	return header
+}
+
+func (f Fare) ToStrings(columns FareColumns) []string {
+	record := []string{strconv.Itoa(f.RideID), f.estimation.String()}
+
+	if columns.DayTypes {
+		dayTypes := make([]string, len(f.DayTypes))
+		for i, dayType := range f.DayTypes {
+			dayTypes[i] = string(dayType)
+		}
+		record = append(record, strings.Join(dayTypes, "|"))
+	}
+	if columns.Zones {
+		record = append(record, strings.Join(f.Zones, "|"))
+	}
+	if columns.FixedRoute {
+		record = append(record, f.FixedRoute)
+	}
+	if columns.TariffVersion {
+		record = append(record, f.TariffVersion)
+	}
+	if columns.Surge {
+		record = append(
+			record,
+			strconv.FormatFloat(f.SurgeMultiplier, 'f', 2, 64),
+			money.FromFloat(0, f.estimation.Currency).Add(f.Breakdown.SurgeAmount).String(),
+		)
+	}
+	if columns.Uncertainty {
+		if f.Uncertainty != nil {
+			record = append(record, f.Uncertainty.P5.String(), f.Uncertainty.P50.String(), f.Uncertainty.P95.String())
+		} else {
+			record = append(record, "", "", "")
+		}
+	}
+	if columns.Discount {
+		if f.Discount != nil {
+			record = append(
+				record,
+				f.Discount.PromoCode,
+				f.estimation.String(),
+				f.Discount.Amount.String(),
+				f.Payable().String(),
+			)
+		} else {
+			record = append(
+				record,
+				"",
+				f.estimation.String(),
+				money.FromFloat(0, f.estimation.Currency).String(),
+				f.estimation.String(),
+			)
+		}
+	}
+	if columns.Payout {
+		if f.Payout != nil {
+			record = append(
+				record,
+				f.Payout.Net.String(),
+				f.Payout.VAT.String(),
+				f.Payout.Gross.String(),
+				f.Payout.Commission.String(),
+				f.Payout.DriverPayout.String(),
+			)
+		} else {
+			record = append(record, "", "", "", "", "")
+		}
+	}
+	if columns.Breakdown {
+		record = append(
+			record,
+			f.Breakdown.FlagFall.String(),
+			strconv.FormatFloat(f.Breakdown.MovingDayKM, 'f', 3, 64),
+			f.Breakdown.MovingDayAmount.String(),
+			strconv.FormatFloat(f.Breakdown.MovingNightKM, 'f', 3, 64),
+			f.Breakdown.MovingNightAmount.String(),
+			strconv.FormatFloat(f.Breakdown.IdleSecs, 'f', 0, 64),
+			f.Breakdown.IdleAmount.String(),
+			strconv.FormatFloat(f.Breakdown.FreeWaitingSecs, 'f', 0, 64),
+			f.Breakdown.MeterDropsAdjustment.String(),
+			f.Breakdown.MinimumFareTopUp.String(),
+			f.Breakdown.FixedRouteAdjustment.String(),
+			f.Breakdown.TollsAmount.String(),
+		)
+
+		crossedTolls := make([]string, len(f.Breakdown.Tolls))
+		for i, toll := range f.Breakdown.Tolls {
+			crossedTolls[i] = toll.Name + ":" + money.FromFloat(toll.Amount, f.estimation.Currency).String()
+		}
+		record = append(record, strings.Join(crossedTolls, "|"))
+	}
+
+	return record
 }
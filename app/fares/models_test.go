@@ -0,0 +1,200 @@
+/*
+Package fares
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package fares
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/money"
+	"github.com/iliaskaras/fare-estimation/app/tariffs"
+	"github.com/iliaskaras/fare-estimation/app/tolls"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// Tests the Fare ToStrings method returns only the RideID and the estimation when no optional column is selected.
+func TestFareToStringsWithoutColumns(t *testing.T) {
+	fare := NewFare(1, euros(3.47))
+	fare.DayTypes = []tariffs.DayType{tariffs.Weekday}
+
+	assert.Equal(t, false, FareColumns{}.Any())
+	assert.Equal(t, []string{"ride_id", "fare"}, FareColumns{}.Header())
+	assert.Equal(t, []string{"1", "3.47"}, fare.ToStrings(FareColumns{}))
+	assert.Equal(t, euros(3.47), fare.Estimation())
+}
+
+// Tests the Fare ToStrings method formats the estimation to exactly the currency's minor units.
+func TestFareToStringsFormatsMinorUnits(t *testing.T) {
+	assert.Equal(t, []string{"1", "13.10"}, NewFare(1, euros(13.1)).ToStrings(FareColumns{}))
+	assert.Equal(t, []string{"1", "3.47"}, NewFare(1, euros(3.4699999)).ToStrings(FareColumns{}))
+	assert.Equal(
+		t,
+		[]string{"1", "1500"},
+		NewFare(1, money.FromFloat(1500, *money.NewCurrency("JPY", 0))).ToStrings(FareColumns{}),
+	)
+}
+
+// Tests the Fare ToStrings method returns the DayTypes column when selected.
+func TestFareToStringsWithDayTypes(t *testing.T) {
+	fare := NewFare(1, euros(3.47))
+	fare.DayTypes = []tariffs.DayType{tariffs.Weekday, tariffs.Sunday}
+	columns := FareColumns{DayTypes: true}
+
+	assert.Equal(t, true, columns.Any())
+	assert.Equal(t, []string{"ride_id", "fare", "day_types"}, columns.Header())
+	assert.Equal(t, []string{"1", "3.47", "weekday|sunday"}, fare.ToStrings(columns))
+}
+
+// Tests the Fare ToStrings method returns the FareBreakdown components when selected.
+func TestFareToStringsWithBreakdown(t *testing.T) {
+	fare := NewFare(1, euros(4.16))
+	fare.Breakdown = FareBreakdown{
+		FlagFall:             euros(1.30),
+		MovingDayKM:          1.8985400968846038,
+		MovingDayAmount:      euros(1.404919671694607),
+		MovingNightKM:        0.5,
+		MovingNightAmount:    euros(0.65),
+		IdleSecs:             27,
+		IdleAmount:           euros(0.08925),
+		MeterDropsAdjustment: euros(0),
+		MinimumFareTopUp:     euros(0),
+		FixedRouteAdjustment: euros(0),
+		TollsAmount:          euros(0),
+	}
+	columns := FareColumns{Breakdown: true}
+
+	assert.Equal(t, true, columns.Any())
+	assert.Equal(
+		t,
+		[]string{
+			"ride_id",
+			"fare",
+			"flag_fall",
+			"moving_day_km",
+			"moving_day_amount",
+			"moving_night_km",
+			"moving_night_amount",
+			"idle_secs",
+			"idle_amount",
+			"free_waiting_secs",
+			"meter_drops_adjustment",
+			"minimum_fare_top_up",
+			"fixed_route_adjustment",
+			"tolls_amount",
+			"tolls",
+		},
+		columns.Header(),
+	)
+	assert.Equal(
+		t,
+		[]string{"1", "4.16", "1.30", "1.899", "1.40", "0.500", "0.65", "27", "0.09", "0", "0.00", "0.00", "0.00", "0.00", ""},
+		fare.ToStrings(columns),
+	)
+}
+
+// Tests the Fare ToStrings method returns the Zones column when selected.
+func TestFareToStringsWithZones(t *testing.T) {
+	fare := NewFare(1, euros(3.47))
+	fare.Zones = []string{"centre", DefaultZoneName}
+	columns := FareColumns{Zones: true}
+
+	assert.Equal(t, true, columns.Any())
+	assert.Equal(t, []string{"ride_id", "fare", "zones"}, columns.Header())
+	assert.Equal(t, []string{"1", "3.47", "centre|default"}, fare.ToStrings(columns))
+}
+
+// Tests the Fare ToStrings method returns the FixedRoute column when selected.
+func TestFareToStringsWithFixedRoute(t *testing.T) {
+	fare := NewFare(1, euros(40))
+	fare.FixedRoute = "airport-centre"
+	columns := FareColumns{FixedRoute: true}
+
+	assert.Equal(t, true, columns.Any())
+	assert.Equal(t, []string{"ride_id", "fare", "fixed_route"}, columns.Header())
+	assert.Equal(t, []string{"1", "40.00", "airport-centre"}, fare.ToStrings(columns))
+}
+
+// Tests the Fare ToStrings method returns the TariffVersion column when selected.
+func TestFareToStringsWithTariffVersion(t *testing.T) {
+	fare := NewFare(1, euros(3.47))
+	fare.TariffVersion = "2022-03"
+	columns := FareColumns{TariffVersion: true}
+
+	assert.Equal(t, true, columns.Any())
+	assert.Equal(t, []string{"ride_id", "fare", "tariff_version"}, columns.Header())
+	assert.Equal(t, []string{"1", "3.47", "2022-03"}, fare.ToStrings(columns))
+}
+
+// Tests the Fare ToStrings method returns the Surge columns when selected.
+func TestFareToStringsWithSurge(t *testing.T) {
+	fare := NewFare(1, euros(5.74))
+	fare.SurgeMultiplier = 1.2
+	fare.Breakdown.SurgeAmount = euros(0.74)
+	columns := FareColumns{Surge: true}
+
+	assert.Equal(t, true, columns.Any())
+	assert.Equal(t, []string{"ride_id", "fare", "surge_multiplier", "surge_amount"}, columns.Header())
+	assert.Equal(t, []string{"1", "5.74", "1.20", "0.74"}, fare.ToStrings(columns))
+	assert.Equal(t, []string{"2", "3.47", "1.00", "0.00"}, NewFare(2, euros(3.47)).ToStrings(columns))
+}
+
+// Tests the Fare ToStrings method returns the Uncertainty columns when selected, empty when not estimated.
+func TestFareToStringsWithUncertainty(t *testing.T) {
+	fare := NewFare(1, euros(11.34))
+	fare.Uncertainty = &FareUncertainty{P5: euros(11.1), P50: euros(11.34), P95: euros(11.9), Samples: 100}
+	columns := FareColumns{Uncertainty: true}
+
+	assert.Equal(t, true, columns.Any())
+	assert.Equal(t, []string{"ride_id", "fare", "fare_p5", "fare_p50", "fare_p95"}, columns.Header())
+	assert.Equal(t, []string{"1", "11.34", "11.10", "11.34", "11.90"}, fare.ToStrings(columns))
+	assert.Equal(t, []string{"2", "3.47", "", "", ""}, NewFare(2, euros(3.47)).ToStrings(columns))
+}
+
+// Tests the Fare ToStrings method returns the Payout columns when selected, empty when not computed.
+func TestFareToStringsWithPayout(t *testing.T) {
+	fare := NewFare(1, euros(12.4))
+	fare.Payout = &FarePayout{
+		Net:          euros(10),
+		VAT:          euros(2.4),
+		Gross:        euros(12.4),
+		Commission:   euros(1.5),
+		DriverPayout: euros(8.5),
+	}
+	columns := FareColumns{Payout: true}
+
+	assert.Equal(t, true, columns.Any())
+	assert.Equal(t, []string{"ride_id", "fare", "net", "vat", "gross", "commission", "driver_payout"}, columns.Header())
+	assert.Equal(t, []string{"1", "12.40", "10.00", "2.40", "12.40", "1.50", "8.50"}, fare.ToStrings(columns))
+	assert.Equal(t, []string{"2", "3.47", "", "", "", "", ""}, NewFare(2, euros(3.47)).ToStrings(columns))
+}
+
+// Tests the Fare ToStrings method lists the tolls crossed in the breakdown columns.
+func TestFareToStringsWithTolls(t *testing.T) {
+	fare := NewFare(1, euros(9.1))
+	fare.Breakdown = FareBreakdown{
+		FlagFall: euros(3.5),
+		Tolls: []tolls.Toll{
+			*tolls.NewToll("elefsina", nil, nil, 2.8, tolls.DefaultDebounceSecs),
+			*tolls.NewToll("metamorfosi", nil, nil, 2.8, tolls.DefaultDebounceSecs),
+		},
+		TollsAmount: euros(5.6),
+	}
+	columns := FareColumns{Breakdown: true}
+
+	record := fare.ToStrings(columns)
+	assert.Len(t, record, len(columns.Header()))
+	assert.Equal(t, []string{"5.60", "elefsina:2.80|metamorfosi:2.80"}, record[len(record)-2:])
+}
+
+// Tests the Fare ToStrings method returns the Discount columns when selected, with no discount when none applies.
+func TestFareToStringsWithDiscount(t *testing.T) {
+	fare := NewFare(1, euros(11.34))
+	fare.Discount = &FareDiscount{PromoCode: "SUMMER20", Amount: euros(2.27)}
+	columns := FareColumns{Discount: true}
+
+	assert.Equal(t, true, columns.Any())
+	assert.Equal(t, []string{"ride_id", "fare", "promo_code", "gross_fare", "discount", "net_fare"}, columns.Header())
+	assert.Equal(t, euros(9.07), fare.Payable())
+	assert.Equal(t, []string{"1", "11.34", "SUMMER20", "11.34", "2.27", "9.07"}, fare.ToStrings(columns))
+	assert.Equal(t, []string{"2", "3.47", "", "3.47", "0.00", "3.47"}, NewFare(2, euros(3.47)).ToStrings(columns))
+}
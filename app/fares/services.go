@@ -6,17 +6,44 @@ package fares
 
 import (
 	"github.com/iliaskaras/fare-estimation/app/rides"
-	"math"
-	"time"
+	"github.com/iliaskaras/fare-estimation/app/routes"
+	"github.com/iliaskaras/fare-estimation/app/surges"
+	"github.com/iliaskaras/fare-estimation/app/tariffs"
+	"github.com/iliaskaras/fare-estimation/app/tolls"
 )
 
-type FareService struct{}
+// FareCalculator is the pricing model that estimates the Fare of a single RideID out of its filtered RideSegments.
+type FareCalculator interface {
+	Calculate(rideSegments []rides.RideSegment) Fare
+}
+
+// FareCalculatorConfig holds what a FareCalculator may price the rides with.
+// - Tariff: The default Tariff, never nil.
+// - TariffVersions: The TariffVersion of the default Tariff, sorted by their EffectiveFrom time, if any.
+// - TariffZones: The TariffZone whose Tariff the RideSegments starting in them are priced with.
+// - FixedRoutes: The FixedRoute charged with a fixed price.
+// - Tolls: The tolls charged on top of the fare.
+// - Surge: The SurgeSchedule whose multipliers are applied on the fare, if any.
+type FareCalculatorConfig struct {
+	Tariff         *tariffs.Tariff
+	TariffVersions []tariffs.TariffVersion
+	TariffZones    []tariffs.TariffZone
+	FixedRoutes    []routes.FixedRoute
+	Tolls          []tolls.Toll
+	Surge          *surges.SurgeSchedule
+}
+
+type FareService struct {
+	fareCalculator FareCalculator
+}
 
-func NewFareService() *FareService {
-	return &FareService{}
+func NewFareService(fareCalculator FareCalculator) *FareService {
+	return &FareService{
+		fareCalculator: fareCalculator,
+	}
 }
 
-// Estimate estimates the fare for each RideID.
+// Estimate estimates the fare for each RideID with the FareService's FareCalculator.
 // - Receiver of the channel rideSegmentsChan,
 // - Pusher to the channel faresChan, where all the estimated Fare are pushed.
 func (ss *FareService) Estimate(
@@ -26,44 +53,12 @@ func (ss *FareService) Estimate(
 
 	// Receives the rideSegmentsChan.
 	for rideSegments := range rideSegmentsChan {
-		fareAmount := StandardFare
-
 		// Case where the RideID had only one RidePosition in the input file.
 		if rideSegments == nil {
 			continue
 		}
 
-		rideID := rideSegments[0].RideID
-
-		for _, rideSegment := range rideSegments {
-			if rideSegment.Speed > rides.MinimumHourKM {
-				startHour := time.Unix(rideSegment.RidePositions[0].Timestamp, 0).UTC().Hour()
-
-				if startHour >= 0 && startHour < 5 {
-					// Night, time after 0 and before 5 the morning.
-					fareAmount += (rideSegment.DistanceCovered) * MovingNight
-				} else {
-					// Day time after 5 the morning and before 24.
-					fareAmount += (rideSegment.DistanceCovered) * MovingDay
-				}
-
-			} else {
-				elapsedTimeSecs := float64(
-					rideSegment.RidePositions[1].Timestamp - rideSegment.RidePositions[0].Timestamp,
-				)
-				fareAmount += (elapsedTimeSecs / rides.HourInSeconds) * Idle
-			}
-		}
-
-		if fareAmount <= MinimumFare {
-			fareAmount = MinimumFare
-		}
-
-		faresChan <- *NewFare(
-			rideID,
-			math.Round(fareAmount*100)/100,
-		)
-
+		faresChan <- ss.fareCalculator.Calculate(rideSegments)
 	}
 
 	close(faresChan)
@@ -5,31 +5,100 @@ Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
 package fares
 
 import (
+	"github.com/iliaskaras/fare-estimation/app/calendars"
+	"github.com/iliaskaras/fare-estimation/app/money"
 	"github.com/iliaskaras/fare-estimation/app/rides"
+	"github.com/iliaskaras/fare-estimation/app/routes"
+	"github.com/iliaskaras/fare-estimation/app/surges"
+	"github.com/iliaskaras/fare-estimation/app/tariffs"
+	"github.com/iliaskaras/fare-estimation/app/tolls"
+	"github.com/iliaskaras/fare-estimation/app/zones"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
+// euros returns the money.Money of the amount in EUR, the currency of the built-in tariff.
+func euros(amount float64) money.Money {
+	return money.FromFloat(amount, money.EUR)
+}
+
+// assertFareEqual asserts that the two Fare are equal, allowing a tiny difference on the
+// FareBreakdown components, due to the floating point arithmetic of the expected amounts.
+func assertFareEqual(t *testing.T, expected Fare, actual Fare) {
+	assert.Equal(t, expected.RideID, actual.RideID)
+	assert.Equal(t, expected.estimation, actual.estimation)
+	assert.Equal(t, expected.DayTypes, actual.DayTypes)
+	assert.Equal(t, expected.Zones, actual.Zones)
+	assert.Equal(t, expected.FixedRoute, actual.FixedRoute)
+	assert.Equal(t, expected.TariffVersion, actual.TariffVersion)
+
+	assert.InDelta(t, expected.Breakdown.FlagFall.Float64(), actual.Breakdown.FlagFall.Float64(), 1e-5)
+	assert.InDelta(t, expected.Breakdown.MovingDayKM, actual.Breakdown.MovingDayKM, 1e-9)
+	assert.InDelta(t, expected.Breakdown.MovingDayAmount.Float64(), actual.Breakdown.MovingDayAmount.Float64(), 1e-5)
+	assert.InDelta(t, expected.Breakdown.MovingNightKM, actual.Breakdown.MovingNightKM, 1e-9)
+	assert.InDelta(t, expected.Breakdown.MovingNightAmount.Float64(), actual.Breakdown.MovingNightAmount.Float64(), 1e-5)
+	assert.InDelta(t, expected.Breakdown.IdleSecs, actual.Breakdown.IdleSecs, 1e-9)
+	assert.InDelta(t, expected.Breakdown.IdleAmount.Float64(), actual.Breakdown.IdleAmount.Float64(), 1e-5)
+	assert.InDelta(t, expected.Breakdown.FreeWaitingSecs, actual.Breakdown.FreeWaitingSecs, 1e-9)
+	assert.InDelta(t, expected.Breakdown.MeterDropsAdjustment.Float64(), actual.Breakdown.MeterDropsAdjustment.Float64(), 1e-5)
+	assert.InDelta(t, expected.Breakdown.MinimumFareTopUp.Float64(), actual.Breakdown.MinimumFareTopUp.Float64(), 1e-5)
+	assert.InDelta(t, expected.Breakdown.SurgeAmount.Float64(), actual.Breakdown.SurgeAmount.Float64(), 1e-5)
+	assert.InDelta(t, expected.Breakdown.FixedRouteAdjustment.Float64(), actual.Breakdown.FixedRouteAdjustment.Float64(), 1e-5)
+	assert.Equal(t, expected.Breakdown.Tolls, actual.Breakdown.Tolls)
+	assert.InDelta(t, expected.Breakdown.TollsAmount.Float64(), actual.Breakdown.TollsAmount.Float64(), 1e-5)
+}
+
 // Tests the FareService.Estimate fare estimation on the received rides.RideSegment.
 // This test case, tests a successful estimation on multiple rides.RideSegment scenarios.
 func TestEstimateSuccessfulExecution(t *testing.T) {
 	rideSegmentsChan := make(chan []rides.RideSegment)
 	faresChan := make(chan Fare)
 
-	fareService := NewFareService()
+	fareService := NewFareService(
+		NewTariffFareCalculator(FareCalculatorConfig{
+			Tariff: tariffs.DefaultTariff(),
+		}),
+	)
 	var expectedFareResults = []Fare{
-		*NewFare(
-			1,
-			3.47,
-		),
-		*NewFare(
-			2,
-			3.47,
-		),
-		*NewFare(
-			3,
-			3.47,
-		),
+		{
+			RideID:     1,
+			estimation: euros(3.47),
+			DayTypes:   []tariffs.DayType{tariffs.Weekday},
+			Zones:      []string{DefaultZoneName},
+			Breakdown: FareBreakdown{
+				FlagFall:         euros(1.30),
+				MovingDayKM:      1.8985400968846038,
+				MovingDayAmount:  euros(1.8985400968846038 * 0.74),
+				IdleSecs:         27,
+				IdleAmount:       euros(27.0 / 3600 * 11.90),
+				MinimumFareTopUp: euros(3.47 - 1.30 - 1.8985400968846038*0.74 - 27.0/3600*11.90),
+			},
+		},
+		{
+			RideID:     2,
+			estimation: euros(3.47),
+			DayTypes:   []tariffs.DayType{tariffs.Weekday},
+			Zones:      []string{DefaultZoneName},
+			Breakdown: FareBreakdown{
+				FlagFall:         euros(1.30),
+				IdleSecs:         19,
+				IdleAmount:       euros(19.0 / 3600 * 11.90),
+				MinimumFareTopUp: euros(3.47 - 1.30 - 19.0/3600*11.90),
+			},
+		},
+		{
+			RideID:     3,
+			estimation: euros(3.47),
+			DayTypes:   []tariffs.DayType{tariffs.Weekday},
+			Zones:      []string{DefaultZoneName},
+			Breakdown: FareBreakdown{
+				FlagFall:         euros(1.30),
+				MovingDayKM:      0.08341822345838025,
+				MovingDayAmount:  euros(0.08341822345838025 * 0.74),
+				MinimumFareTopUp: euros(3.47 - 1.30 - 0.08341822345838025*0.74),
+			},
+		},
 	}
 	go func() {
 		rideSegmentsChan <- []rides.RideSegment{
@@ -186,7 +255,7 @@ func TestEstimateSuccessfulExecution(t *testing.T) {
 
 	i := 0
 	for faresResult := range faresChan {
-		assert.Equal(t, expectedFareResults[i], faresResult)
+		assertFareEqual(t, expectedFareResults[i], faresResult)
 		i += 1
 	}
 
@@ -198,12 +267,24 @@ func TestEstimateWhenTheFareEstimateIsTheMinimum(t *testing.T) {
 	rideSegmentsChan := make(chan []rides.RideSegment)
 	faresChan := make(chan Fare)
 
-	fareService := NewFareService()
+	fareService := NewFareService(
+		NewTariffFareCalculator(FareCalculatorConfig{
+			Tariff: tariffs.DefaultTariff(),
+		}),
+	)
 	var expectedFareResults = []Fare{
-		*NewFare(
-			1,
-			3.47,
-		),
+		{
+			RideID:     1,
+			estimation: euros(3.47),
+			DayTypes:   []tariffs.DayType{tariffs.Weekday},
+			Zones:      []string{DefaultZoneName},
+			Breakdown: FareBreakdown{
+				FlagFall:         euros(1.30),
+				IdleSecs:         9,
+				IdleAmount:       euros(9.0 / 3600 * 11.90),
+				MinimumFareTopUp: euros(3.47 - 1.30 - 9.0/3600*11.90),
+			},
+		},
 	}
 	go func() {
 		rideSegmentsChan <- []rides.RideSegment{
@@ -240,7 +321,7 @@ func TestEstimateWhenTheFareEstimateIsTheMinimum(t *testing.T) {
 
 	i := 0
 	for faresResult := range faresChan {
-		assert.Equal(t, expectedFareResults[i], faresResult)
+		assertFareEqual(t, expectedFareResults[i], faresResult)
 		i += 1
 	}
 
@@ -252,12 +333,23 @@ func TestEstimateWhenFareIsAboveMinimumAndIdle(t *testing.T) {
 	rideSegmentsChan := make(chan []rides.RideSegment)
 	faresChan := make(chan Fare)
 
-	fareService := NewFareService()
+	fareService := NewFareService(
+		NewTariffFareCalculator(FareCalculatorConfig{
+			Tariff: tariffs.DefaultTariff(),
+		}),
+	)
 	var expectedFareResults = []Fare{
-		*NewFare(
-			1,
-			4.16,
-		),
+		{
+			RideID:     1,
+			estimation: euros(4.16),
+			DayTypes:   []tariffs.DayType{tariffs.Weekday},
+			Zones:      []string{DefaultZoneName},
+			Breakdown: FareBreakdown{
+				FlagFall:   euros(1.30),
+				IdleSecs:   866,
+				IdleAmount: euros(866.0 / 3600 * 11.90),
+			},
+		},
 	}
 	go func() {
 		rideSegmentsChan <- []rides.RideSegment{
@@ -294,7 +386,7 @@ func TestEstimateWhenFareIsAboveMinimumAndIdle(t *testing.T) {
 
 	i := 0
 	for faresResult := range faresChan {
-		assert.Equal(t, expectedFareResults[i], faresResult)
+		assertFareEqual(t, expectedFareResults[i], faresResult)
 		i += 1
 	}
 
@@ -306,10 +398,21 @@ func TestEstimateWhenFareIsAboveMinimumAndMovingDay(t *testing.T) {
 	rideSegmentsChan := make(chan []rides.RideSegment)
 	faresChan := make(chan Fare)
 
-	fareService := NewFareService()
+	fareService := NewFareService(
+		NewTariffFareCalculator(FareCalculatorConfig{
+			Tariff: tariffs.DefaultTariff(),
+		}),
+	)
 	var expectedFareResult = Fare{
-		1,
-		5,
+		RideID:     1,
+		estimation: euros(5),
+		DayTypes:   []tariffs.DayType{tariffs.Weekday},
+		Zones:      []string{DefaultZoneName},
+		Breakdown: FareBreakdown{
+			FlagFall:        euros(1.30),
+			MovingDayKM:     5,
+			MovingDayAmount: euros(5 * 0.74),
+		},
 	}
 
 	go func() {
@@ -330,10 +433,8 @@ func TestEstimateWhenFareIsAboveMinimumAndMovingDay(t *testing.T) {
 						Timestamp: 1405594966,
 					},
 				},
-				Speed: 11,
-				// Not realistic for a single RideSegment to have that big distance covered,
-				// but is used for emulate the test scenario.
-				DistanceCovered: 5000,
+				Speed:           11,
+				DistanceCovered: 5,
 			},
 		}
 
@@ -350,7 +451,7 @@ func TestEstimateWhenFareIsAboveMinimumAndMovingDay(t *testing.T) {
 
 	i := 0
 	for faresResult := range faresChan {
-		assert.Equal(t, expectedFareResult, faresResult)
+		assertFareEqual(t, expectedFareResult, faresResult)
 		i += 1
 	}
 
@@ -362,10 +463,21 @@ func TestEstimateWhenFareIsAboveMinimumAndMovingNight(t *testing.T) {
 	rideSegmentsChan := make(chan []rides.RideSegment)
 	faresChan := make(chan Fare)
 
-	fareService := NewFareService()
+	fareService := NewFareService(
+		NewTariffFareCalculator(FareCalculatorConfig{
+			Tariff: tariffs.DefaultTariff(),
+		}),
+	)
 	var expectedFareResult = Fare{
-		1,
-		7.8,
+		RideID:     1,
+		estimation: euros(7.8),
+		DayTypes:   []tariffs.DayType{tariffs.Weekday},
+		Zones:      []string{DefaultZoneName},
+		Breakdown: FareBreakdown{
+			FlagFall:          euros(1.30),
+			MovingNightKM:     5,
+			MovingNightAmount: euros(5 * 1.30),
+		},
 	}
 
 	go func() {
@@ -384,16 +496,843 @@ func TestEstimateWhenFareIsAboveMinimumAndMovingNight(t *testing.T) {
 						Id:        1,
 						Lat:       37.966627,
 						Lng:       23.728263,
-						Timestamp: 1644943444,
+						Timestamp: 1644887400,
+					},
+				},
+				Speed:           11,
+				DistanceCovered: 5,
+			},
+		}
+
+		close(rideSegmentsChan)
+	}()
+
+	go func() {
+		fareService.Estimate(
+			rideSegmentsChan,
+			faresChan,
+		)
+	}()
+
+	i := 0
+	for faresResult := range faresChan {
+		assertFareEqual(t, expectedFareResult, faresResult)
+		i += 1
+	}
+
+}
+
+// Tests the FareService.Estimate decides day and night time in the Tariff's timezone,
+// including the daylight saving time switch days of Europe/Athens.
+func TestEstimateDayAndNightInTariffTimezone(t *testing.T) {
+	athens, _ := time.LoadLocation("Europe/Athens")
+
+	testCases := []struct {
+		timestamp       int64
+		expectedFare    float64
+		expectedDayType tariffs.DayType
+	}{
+		// 2022-02-15 01:00 UTC, 03:00 in Athens (EET, UTC+2).
+		{timestamp: 1644886800, expectedFare: 7.8, expectedDayType: tariffs.Weekday},
+		// 2022-02-15 03:30 UTC, 05:30 in Athens (EET, UTC+2), night in UTC but day in Athens.
+		{timestamp: 1644895800, expectedFare: 5, expectedDayType: tariffs.Weekday},
+		// 2022-02-14 22:30 UTC, 00:30 in Athens (EET, UTC+2), day in UTC but night in Athens.
+		{timestamp: 1644877800, expectedFare: 7.8, expectedDayType: tariffs.Weekday},
+		// 2022-03-27 01:30 UTC, 04:30 in Athens, right after the switch to summer time (EEST, UTC+3).
+		{timestamp: 1648344600, expectedFare: 7.8, expectedDayType: tariffs.Sunday},
+		// 2022-03-27 02:00 UTC, 05:00 in Athens (EEST, UTC+3), would be 04:00 without the switch.
+		{timestamp: 1648346400, expectedFare: 5, expectedDayType: tariffs.Sunday},
+		// 2022-10-30 01:30 UTC, 03:30 in Athens, right after the switch back to winter time (EET, UTC+2).
+		{timestamp: 1667093400, expectedFare: 7.8, expectedDayType: tariffs.Sunday},
+		// 2022-10-30 02:30 UTC, 04:30 in Athens (EET, UTC+2), would be 05:30 without the switch.
+		{timestamp: 1667097000, expectedFare: 7.8, expectedDayType: tariffs.Sunday},
+	}
+
+	tariff := tariffs.DefaultTariff()
+	tariff.Location = athens
+	fareService := NewFareService(NewTariffFareCalculator(FareCalculatorConfig{Tariff: tariff}))
+
+	for _, testCase := range testCases {
+		rideSegmentsChan := make(chan []rides.RideSegment)
+		faresChan := make(chan Fare)
+
+		go func(timestamp int64) {
+			rideSegmentsChan <- []rides.RideSegment{
+				{
+					RideID: 1,
+					RidePositions: [2]rides.RidePosition{
+						{
+							Id:        1,
+							Lat:       37.966660,
+							Lng:       23.728308,
+							Timestamp: timestamp,
+						},
+						{
+							Id:        1,
+							Lat:       37.966627,
+							Lng:       23.728263,
+							Timestamp: timestamp + 600,
+						},
+					},
+					Speed:           30,
+					DistanceCovered: 5,
+				},
+			}
+			close(rideSegmentsChan)
+		}(testCase.timestamp)
+
+		go fareService.Estimate(rideSegmentsChan, faresChan)
+
+		for faresResult := range faresChan {
+			assert.Equal(t, euros(testCase.expectedFare), faresResult.estimation, "timestamp: %d", testCase.timestamp)
+			assert.Equal(t, []tariffs.DayType{testCase.expectedDayType}, faresResult.DayTypes, "timestamp: %d", testCase.timestamp)
+		}
+	}
+
+}
+
+// Tests the FareService.Estimate prices a RideSegment that crosses the night to day boundary,
+// by splitting its distance by time and pricing each share at its own rate.
+func TestEstimateWhenSegmentCrossesNightToDay(t *testing.T) {
+	rideSegmentsChan := make(chan []rides.RideSegment)
+	faresChan := make(chan Fare)
+
+	fareService := NewFareService(
+		NewTariffFareCalculator(FareCalculatorConfig{
+			Tariff: tariffs.DefaultTariff(),
+		}),
+	)
+	// 1.30 standard fare + 1km * 1.30 at night + 10km * 0.74 at day.
+	var expectedFareResult = Fare{
+		RideID:     1,
+		estimation: euros(10),
+		DayTypes:   []tariffs.DayType{tariffs.Weekday},
+		Zones:      []string{DefaultZoneName},
+		Breakdown: FareBreakdown{
+			FlagFall:          euros(1.30),
+			MovingDayKM:       10,
+			MovingDayAmount:   euros(10 * 0.74),
+			MovingNightKM:     1,
+			MovingNightAmount: euros(1 * 1.30),
+		},
+	}
+
+	go func() {
+		rideSegmentsChan <- []rides.RideSegment{
+			{
+				RideID: 1,
+				RidePositions: [2]rides.RidePosition{
+					{
+						Id:  1,
+						Lat: 37.966660,
+						Lng: 23.728308,
+						// StartTime = 04:59 AM
+						Timestamp: 1644901140,
+					},
+					{
+						Id:  1,
+						Lat: 37.966627,
+						Lng: 23.728263,
+						// EndTime = 05:10 AM
+						Timestamp: 1644901800,
+					},
+				},
+				Speed:           60,
+				DistanceCovered: 11,
+			},
+		}
+
+		close(rideSegmentsChan)
+	}()
+
+	go func() {
+		fareService.Estimate(
+			rideSegmentsChan,
+			faresChan,
+		)
+	}()
+
+	for faresResult := range faresChan {
+		assertFareEqual(t, expectedFareResult, faresResult)
+	}
+
+}
+
+// Tests the FareService.Estimate prices a ride with the rates of the DayType it happens in,
+// when the Tariff has rates for it, and keeps the DayTypes applied on the Fare.
+func TestEstimateWithDayTypeRates(t *testing.T) {
+	rideSegmentsChan := make(chan []rides.RideSegment)
+	faresChan := make(chan Fare)
+
+	holidays := calendars.NewCalendar()
+	holidays.Add(time.Date(2022, 2, 15, 0, 0, 0, 0, time.UTC), "")
+
+	tariff := tariffs.DefaultTariff()
+	tariff.Holidays = holidays
+	tariff.DayTypeRates[tariffs.Holiday] = *tariffs.NewRateTable(20, 1, 2)
+
+	fareService := NewFareService(NewTariffFareCalculator(FareCalculatorConfig{Tariff: tariff}))
+	// 1.30 standard fare + 5km * 2 holiday night rate + 0.5 hours * 20 holiday idle rate.
+	var expectedFareResult = Fare{
+		RideID:     1,
+		estimation: euros(21.3),
+		DayTypes:   []tariffs.DayType{tariffs.Holiday},
+		Zones:      []string{DefaultZoneName},
+		Breakdown: FareBreakdown{
+			FlagFall:          euros(1.30),
+			MovingNightKM:     5,
+			MovingNightAmount: euros(5 * 2),
+			IdleSecs:          1800,
+			IdleAmount:        euros(0.5 * 20),
+		},
+	}
+
+	go func() {
+		rideSegmentsChan <- []rides.RideSegment{
+			{
+				RideID: 1,
+				RidePositions: [2]rides.RidePosition{
+					{
+						Id:  1,
+						Lat: 37.966660,
+						Lng: 23.728308,
+						// 2022-02-15 01:00 AM
+						Timestamp: 1644886800,
+					},
+					{
+						Id:        1,
+						Lat:       37.966627,
+						Lng:       23.728263,
+						Timestamp: 1644887400,
+					},
+				},
+				Speed:           30,
+				DistanceCovered: 5,
+			},
+			{
+				RideID: 1,
+				RidePositions: [2]rides.RidePosition{
+					{
+						Id:        1,
+						Lat:       37.966627,
+						Lng:       23.728263,
+						Timestamp: 1644887400,
+					},
+					{
+						Id:        1,
+						Lat:       37.966627,
+						Lng:       23.728263,
+						Timestamp: 1644889200,
+					},
+				},
+				Speed:           0,
+				DistanceCovered: 0,
+			},
+		}
+
+		close(rideSegmentsChan)
+	}()
+
+	go func() {
+		fareService.Estimate(
+			rideSegmentsChan,
+			faresChan,
+		)
+	}()
+
+	for faresResult := range faresChan {
+		assertFareEqual(t, expectedFareResult, faresResult)
+	}
+
+}
+
+// Tests the FareService.Estimate prices each RideSegment with the Tariff of the TariffZone that its start
+// position falls in, falling back to the FareService's Tariff outside every zone, and keeps the zones touched.
+func TestEstimateWithTariffZones(t *testing.T) {
+	rideSegmentsChan := make(chan []rides.RideSegment)
+	faresChan := make(chan Fare)
+
+	centreTariff := tariffs.NewTariff(2, 3, 12, 1, 1.5)
+	tariffZones := []tariffs.TariffZone{
+		*tariffs.NewTariffZone(
+			*zones.NewZone(
+				"centre",
+				[]zones.Polygon{
+					{
+						{
+							{Lat: 37.95, Lng: 23.70},
+							{Lat: 37.95, Lng: 23.76},
+							{Lat: 38.00, Lng: 23.76},
+							{Lat: 38.00, Lng: 23.70},
+							{Lat: 37.95, Lng: 23.70},
+						},
+					},
+				},
+				nil,
+			),
+			centreTariff,
+		),
+	}
+
+	fareService := NewFareService(
+		NewTariffFareCalculator(FareCalculatorConfig{
+			Tariff:      tariffs.DefaultTariff(),
+			TariffZones: tariffZones,
+		}),
+	)
+	// 2 centre standard fare + 2km * 1 centre day rate + 3km * 0.74 default day rate.
+	var expectedFareResult = Fare{
+		RideID:     1,
+		estimation: euros(6.22),
+		DayTypes:   []tariffs.DayType{tariffs.Weekday},
+		Zones:      []string{"centre", DefaultZoneName},
+		Breakdown: FareBreakdown{
+			FlagFall:        euros(2),
+			MovingDayKM:     5,
+			MovingDayAmount: euros(2*1 + 3*0.74),
+		},
+	}
+
+	go func() {
+		rideSegmentsChan <- []rides.RideSegment{
+			{
+				RideID: 1,
+				RidePositions: [2]rides.RidePosition{
+					{
+						Id:        1,
+						Lat:       37.966660,
+						Lng:       23.728308,
+						Timestamp: 1405594957,
+					},
+					{
+						Id:        1,
+						Lat:       37.940000,
+						Lng:       23.728263,
+						Timestamp: 1405595257,
+					},
+				},
+				Speed:           24,
+				DistanceCovered: 2,
+			},
+			{
+				RideID: 1,
+				RidePositions: [2]rides.RidePosition{
+					{
+						Id:        1,
+						Lat:       37.940000,
+						Lng:       23.728263,
+						Timestamp: 1405595257,
+					},
+					{
+						Id:        1,
+						Lat:       37.920000,
+						Lng:       23.728263,
+						Timestamp: 1405595557,
+					},
+				},
+				Speed:           36,
+				DistanceCovered: 3,
+			},
+		}
+
+		close(rideSegmentsChan)
+	}()
+
+	go func() {
+		fareService.Estimate(
+			rideSegmentsChan,
+			faresChan,
+		)
+	}()
+
+	for faresResult := range faresChan {
+		assertFareEqual(t, expectedFareResult, faresResult)
+		// The minimum fare is the one of the zone that the ride starts in.
+		assert.Equal(t, euros(3), faresResult.MinimumFare)
+	}
+
+}
+
+// Tests the FareService.Estimate charges the rides that follow a FixedRoute with its price, in both
+// directions, while the rest of the rides are metered.
+func TestEstimateWithFixedRoutes(t *testing.T) {
+	rideSegmentsChan := make(chan []rides.RideSegment)
+	faresChan := make(chan Fare)
+
+	airport := *zones.NewZone(
+		"airport",
+		[]zones.Polygon{{{{Lat: 37.92, Lng: 23.93}, {Lat: 37.92, Lng: 23.96}, {Lat: 37.95, Lng: 23.96}, {Lat: 37.92, Lng: 23.93}}}},
+		nil,
+	)
+	centre := *zones.NewZone(
+		"centre",
+		[]zones.Polygon{{{{Lat: 37.95, Lng: 23.70}, {Lat: 37.95, Lng: 23.76}, {Lat: 38.00, Lng: 23.76}, {Lat: 37.95, Lng: 23.70}}}},
+		nil,
+	)
+	fixedRoutes := []routes.FixedRoute{
+		*routes.NewFixedRoute("airport-centre", airport, centre, 40, true),
+	}
+
+	fareService := NewFareService(
+		NewTariffFareCalculator(FareCalculatorConfig{
+			Tariff:      tariffs.DefaultTariff(),
+			FixedRoutes: fixedRoutes,
+		}),
+	)
+	var expectedFareResults = []Fare{
+		{
+			// 1.30 standard fare + 30km * 0.74, charged 40 instead.
+			RideID:     1,
+			estimation: euros(40),
+			DayTypes:   []tariffs.DayType{tariffs.Weekday},
+			Zones:      []string{DefaultZoneName},
+			FixedRoute: "airport-centre",
+			Breakdown: FareBreakdown{
+				FlagFall:             euros(1.30),
+				MovingDayKM:          30,
+				MovingDayAmount:      euros(30 * 0.74),
+				FixedRouteAdjustment: euros(40 - 1.30 - 30*0.74),
+			},
+		},
+		{
+			// The other direction.
+			RideID:     2,
+			estimation: euros(40),
+			DayTypes:   []tariffs.DayType{tariffs.Weekday},
+			Zones:      []string{DefaultZoneName},
+			FixedRoute: "airport-centre",
+			Breakdown: FareBreakdown{
+				FlagFall:             euros(1.30),
+				MovingDayKM:          30,
+				MovingDayAmount:      euros(30 * 0.74),
+				FixedRouteAdjustment: euros(40 - 1.30 - 30*0.74),
+			},
+		},
+		{
+			// Starts at the airport, but does not end in the centre.
+			RideID:     3,
+			estimation: euros(23.5),
+			DayTypes:   []tariffs.DayType{tariffs.Weekday},
+			Zones:      []string{DefaultZoneName},
+			Breakdown: FareBreakdown{
+				FlagFall:        euros(1.30),
+				MovingDayKM:     30,
+				MovingDayAmount: euros(30 * 0.74),
+			},
+		},
+	}
+
+	airportPosition := rides.RidePosition{Lat: 37.936, Lng: 23.947, Timestamp: 1405594957}
+	centrePosition := rides.RidePosition{Lat: 37.975, Lng: 23.735, Timestamp: 1405596757}
+	elsewherePosition := rides.RidePosition{Lat: 38.050, Lng: 23.800, Timestamp: 1405596757}
+
+	go func() {
+		for rideID, ends := range [][2]rides.RidePosition{
+			{airportPosition, centrePosition},
+			{centrePosition, airportPosition},
+			{airportPosition, elsewherePosition},
+		} {
+			start := ends[0]
+			start.Id = rideID + 1
+			start.Timestamp = 1405594957
+			end := ends[1]
+			end.Id = rideID + 1
+			end.Timestamp = 1405596757
+
+			rideSegmentsChan <- []rides.RideSegment{
+				{
+					RideID:          rideID + 1,
+					RidePositions:   [2]rides.RidePosition{start, end},
+					Speed:           60,
+					DistanceCovered: 30,
+				},
+			}
+		}
+
+		close(rideSegmentsChan)
+	}()
+
+	go func() {
+		fareService.Estimate(
+			rideSegmentsChan,
+			faresChan,
+		)
+	}()
+
+	i := 0
+	for faresResult := range faresChan {
+		assertFareEqual(t, expectedFareResults[i], faresResult)
+		i += 1
+	}
+
+}
+
+// Tests the FareService.Estimate adds the amounts of the tolls crossed on top of the metered fare,
+// after the minimum fare is applied.
+func TestEstimateWithTolls(t *testing.T) {
+	rideSegmentsChan := make(chan []rides.RideSegment)
+	faresChan := make(chan Fare)
+
+	gate := tolls.NewToll(
+		"elefsina",
+		nil,
+		[]zones.Point{{Lat: 38.00, Lng: 23.60}, {Lat: 38.10, Lng: 23.60}},
+		2.80,
+		0,
+	)
+	fareService := NewFareService(
+		NewTariffFareCalculator(FareCalculatorConfig{
+			Tariff: tariffs.DefaultTariff(),
+			Tolls:  []tolls.Toll{*gate},
+		}),
+	)
+
+	var expectedFareResults = []Fare{
+		{
+			// Crosses the gate there and back: 1.30 standard fare + 2km * 0.74, topped up
+			// to the 3.47 minimum fare, plus 2 * 2.80 tolls.
+			RideID:     1,
+			estimation: euros(9.07),
+			DayTypes:   []tariffs.DayType{tariffs.Weekday},
+			Zones:      []string{DefaultZoneName},
+			Breakdown: FareBreakdown{
+				FlagFall:         euros(1.30),
+				MovingDayKM:      2,
+				MovingDayAmount:  euros(2 * 0.74),
+				MinimumFareTopUp: euros(3.47 - 1.30 - 2*0.74),
+				Tolls:            []tolls.Toll{*gate, *gate},
+				TollsAmount:      euros(5.60),
+			},
+		},
+		{
+			// Stays on the one side of the gate.
+			RideID:     2,
+			estimation: euros(3.47),
+			DayTypes:   []tariffs.DayType{tariffs.Weekday},
+			Zones:      []string{DefaultZoneName},
+			Breakdown: FareBreakdown{
+				FlagFall:         euros(1.30),
+				MovingDayKM:      2,
+				MovingDayAmount:  euros(2 * 0.74),
+				MinimumFareTopUp: euros(3.47 - 1.30 - 2*0.74),
+			},
+		},
+	}
+
+	west := rides.RidePosition{Lat: 38.05, Lng: 23.59, Timestamp: 1405594957}
+	east := rides.RidePosition{Lat: 38.05, Lng: 23.61, Timestamp: 1405595017}
+	farEast := rides.RidePosition{Lat: 38.05, Lng: 23.62, Timestamp: 1405595077}
+
+	go func() {
+		for rideID, positions := range [][3]rides.RidePosition{
+			{west, east, west},
+			{east, farEast, east},
+		} {
+			var rideSegments []rides.RideSegment
+			for i := 1; i < len(positions); i++ {
+				from := positions[i-1]
+				from.Id = rideID + 1
+				from.Timestamp = 1405594957 + int64(i-1)*60
+				to := positions[i]
+				to.Id = rideID + 1
+				to.Timestamp = 1405594957 + int64(i)*60
+
+				rideSegments = append(
+					rideSegments,
+					rides.RideSegment{
+						RideID:          rideID + 1,
+						RidePositions:   [2]rides.RidePosition{from, to},
+						Speed:           60,
+						DistanceCovered: 1,
+					},
+				)
+			}
+
+			rideSegmentsChan <- rideSegments
+		}
+
+		close(rideSegmentsChan)
+	}()
+
+	go func() {
+		fareService.Estimate(
+			rideSegmentsChan,
+			faresChan,
+		)
+	}()
+
+	i := 0
+	for faresResult := range faresChan {
+		assertFareEqual(t, expectedFareResults[i], faresResult)
+		i += 1
+	}
+
+}
+
+// Tests the FareService.Estimate rounds the fare with the Tariff's money.RoundingMode, formatted to its currency.
+func TestEstimateRoundsWithTariffRoundingMode(t *testing.T) {
+	testCases := []struct {
+		rounding     money.RoundingMode
+		expectedFare string
+	}{
+		// 1.30 standard fare + 5km * 0.745 = 5.025
+		{rounding: money.HalfUp, expectedFare: "5.03"},
+		{rounding: money.HalfEven, expectedFare: "5.02"},
+		{rounding: money.UpToNickel, expectedFare: "5.05"},
+	}
+
+	for _, testCase := range testCases {
+		tariff := tariffs.NewTariff(1.30, 3.47, 11.90, 0.745, 1.30)
+		tariff.Currency = *money.NewCurrency("CHF", 2)
+		tariff.Rounding = testCase.rounding
+		fareService := NewFareService(NewTariffFareCalculator(FareCalculatorConfig{Tariff: tariff}))
+
+		rideSegmentsChan := make(chan []rides.RideSegment)
+		faresChan := make(chan Fare)
+
+		go func() {
+			rideSegmentsChan <- []rides.RideSegment{
+				{
+					RideID: 1,
+					RidePositions: [2]rides.RidePosition{
+						{Id: 1, Lat: 37.966660, Lng: 23.728308, Timestamp: 1405594957},
+						{Id: 1, Lat: 37.966627, Lng: 23.728263, Timestamp: 1405595557},
 					},
+					Speed:           30,
+					DistanceCovered: 5,
 				},
-				Speed: 11,
-				// Not realistic for a single RideSegment to have that big distance covered,
-				// but is used for emulate the test scenario.
-				DistanceCovered: 5000,
+			}
+			close(rideSegmentsChan)
+		}()
+
+		go fareService.Estimate(rideSegmentsChan, faresChan)
+
+		for faresResult := range faresChan {
+			assert.Equal(t, "CHF", faresResult.estimation.Currency.Code)
+			assert.Equal(t, []string{"1", testCase.expectedFare}, faresResult.ToStrings(FareColumns{}), testCase.rounding)
+		}
+	}
+}
+
+// Tests the FareService.Estimate charges the idle time after the Tariff's free waiting time is used up,
+// deciding on the idle RideSegments with the Tariff's idle speed.
+func TestEstimateWithIdleSpeedAndFreeWaiting(t *testing.T) {
+	rideSegmentsChan := make(chan []rides.RideSegment)
+	faresChan := make(chan Fare)
+
+	tariff := tariffs.DefaultTariff()
+	tariff.IdleSpeedKMH = 20
+	tariff.FreeWaitingSecs = 120
+	fareService := NewFareService(NewTariffFareCalculator(FareCalculatorConfig{Tariff: tariff}))
+
+	var expectedFareResults = []Fare{
+		{
+			// 1.30 standard fare + 5km * 0.74 + (60 + 180 - 120 free secs) * 11.90 / 3600 idle, while
+			// the segment at 15km/h is charged as idle with the idle speed of 20km/h.
+			RideID:     1,
+			estimation: euros(5.40),
+			DayTypes:   []tariffs.DayType{tariffs.Weekday},
+			Zones:      []string{DefaultZoneName},
+			Breakdown: FareBreakdown{
+				FlagFall:        euros(1.30),
+				MovingDayKM:     5,
+				MovingDayAmount: euros(5 * 0.74),
+				IdleSecs:        240,
+				IdleAmount:      euros(120.0 / 3600 * 11.90),
+				FreeWaitingSecs: 120,
+			},
+		},
+	}
+
+	go func() {
+		position := func(timestamp int64) rides.RidePosition {
+			return rides.RidePosition{Id: 1, Lat: 37.966660, Lng: 23.728308, Timestamp: timestamp}
+		}
+
+		rideSegmentsChan <- []rides.RideSegment{
+			{
+				RideID:          1,
+				RidePositions:   [2]rides.RidePosition{position(1405594957), position(1405595017)},
+				Speed:           15,
+				DistanceCovered: 0.25,
+			},
+			{
+				RideID:          1,
+				RidePositions:   [2]rides.RidePosition{position(1405595017), position(1405595317)},
+				Speed:           60,
+				DistanceCovered: 5,
+			},
+			{
+				RideID:          1,
+				RidePositions:   [2]rides.RidePosition{position(1405595317), position(1405595497)},
+				Speed:           0,
+				DistanceCovered: 0,
+			},
+		}
+		close(rideSegmentsChan)
+	}()
+
+	go func() {
+		fareService.Estimate(
+			rideSegmentsChan,
+			faresChan,
+		)
+	}()
+
+	i := 0
+	for faresResult := range faresChan {
+		assertFareEqual(t, expectedFareResults[i], faresResult)
+		i += 1
+	}
+
+}
+
+// Tests the FareService.Estimate on the meter BillingMode charges the higher of the time and the distance
+// amount of each RideSegment, in whole fare drops.
+func TestEstimateWithMeterBillingMode(t *testing.T) {
+	rideSegmentsChan := make(chan []rides.RideSegment)
+	faresChan := make(chan Fare)
+
+	tariff := tariffs.DefaultTariff()
+	tariff.BillingMode = tariffs.MeterBilling
+	tariff.MeterDropAmount = 0.10
+	fareService := NewFareService(NewTariffFareCalculator(FareCalculatorConfig{Tariff: tariff}))
+
+	var expectedFareResults = []Fare{
+		{
+			// At 12km/h, below the 11.90 / 0.74 = 16.08km/h crossover speed, the 600 secs are charged
+			// over the 2km, and at 30km/h the 5km are charged over the 600 secs. The metered
+			// 600 * 11.90 / 3600 + 5 * 0.74 = 5.6833 is charged in 0.10 drops, plus the 1.30 standard fare.
+			RideID:     1,
+			estimation: euros(6.90),
+			DayTypes:   []tariffs.DayType{tariffs.Weekday},
+			Zones:      []string{DefaultZoneName},
+			Breakdown: FareBreakdown{
+				FlagFall:             euros(1.30),
+				MovingDayKM:          5,
+				MovingDayAmount:      euros(5 * 0.74),
+				IdleSecs:             600,
+				IdleAmount:           euros(600.0 / 3600 * 11.90),
+				MeterDropsAdjustment: euros(5.60 - 5*0.74 - 600.0/3600*11.90),
+			},
+		},
+	}
+
+	go func() {
+		position := func(timestamp int64) rides.RidePosition {
+			return rides.RidePosition{Id: 1, Lat: 37.966660, Lng: 23.728308, Timestamp: timestamp}
+		}
+
+		rideSegmentsChan <- []rides.RideSegment{
+			{
+				RideID:          1,
+				RidePositions:   [2]rides.RidePosition{position(1405594957), position(1405595557)},
+				Speed:           12,
+				DistanceCovered: 2,
+			},
+			{
+				RideID:          1,
+				RidePositions:   [2]rides.RidePosition{position(1405595557), position(1405596157)},
+				Speed:           30,
+				DistanceCovered: 5,
 			},
 		}
+		close(rideSegmentsChan)
+	}()
+
+	go func() {
+		fareService.Estimate(
+			rideSegmentsChan,
+			faresChan,
+		)
+	}()
+
+	i := 0
+	for faresResult := range faresChan {
+		assertFareEqual(t, expectedFareResults[i], faresResult)
+		i += 1
+	}
+
+}
+
+// Tests the FareService.Estimate prices each ride with the tariffs.TariffVersion in force at its first
+// position, and with the default Tariff before the first of them.
+func TestEstimateWithTariffVersions(t *testing.T) {
+	rideSegmentsChan := make(chan []rides.RideSegment)
+	faresChan := make(chan Fare)
+
+	july := tariffs.DefaultTariff()
+	july.MovingDay = 1.00
+	august := tariffs.DefaultTariff()
+	august.MovingDay = 2.00
+
+	fareService := NewFareService(
+		NewTariffFareCalculator(FareCalculatorConfig{
+			Tariff: tariffs.DefaultTariff(),
+			TariffVersions: []tariffs.TariffVersion{
+				*tariffs.NewTariffVersion("2014-07", time.Date(2014, 7, 15, 0, 0, 0, 0, time.UTC), july),
+				*tariffs.NewTariffVersion("2014-08", time.Date(2014, 8, 1, 0, 0, 0, 0, time.UTC), august),
+			},
+		}),
+	)
+
+	var expectedFareResults = []Fare{
+		{
+			// 2014-07-10, before the first version: 1.30 standard fare + 5km * 0.74.
+			RideID:        1,
+			estimation:    euros(5.00),
+			DayTypes:      []tariffs.DayType{tariffs.Weekday},
+			Zones:         []string{DefaultZoneName},
+			TariffVersion: DefaultTariffVersionID,
+			Breakdown: FareBreakdown{
+				FlagFall:        euros(1.30),
+				MovingDayKM:     5,
+				MovingDayAmount: euros(5 * 0.74),
+			},
+		},
+		{
+			// 2014-07-17: 1.30 standard fare + 5km * 1.00.
+			RideID:        2,
+			estimation:    euros(6.30),
+			DayTypes:      []tariffs.DayType{tariffs.Weekday},
+			Zones:         []string{DefaultZoneName},
+			TariffVersion: "2014-07",
+			Breakdown: FareBreakdown{
+				FlagFall:        euros(1.30),
+				MovingDayKM:     5,
+				MovingDayAmount: euros(5 * 1.00),
+			},
+		},
+		{
+			// 2014-08-02: 1.30 standard fare + 5km * 2.00.
+			RideID:        3,
+			estimation:    euros(11.30),
+			DayTypes:      []tariffs.DayType{tariffs.Weekday},
+			Zones:         []string{DefaultZoneName},
+			TariffVersion: "2014-08",
+			Breakdown: FareBreakdown{
+				FlagFall:        euros(1.30),
+				MovingDayKM:     5,
+				MovingDayAmount: euros(5 * 2.00),
+			},
+		},
+	}
 
+	go func() {
+		for i, timestamp := range []int64{1405000000, 1405594957, 1407000000} {
+			rideID := i + 1
+			rideSegmentsChan <- []rides.RideSegment{
+				{
+					RideID: rideID,
+					RidePositions: [2]rides.RidePosition{
+						{Id: rideID, Lat: 37.966660, Lng: 23.728308, Timestamp: timestamp},
+						{Id: rideID, Lat: 37.966660, Lng: 23.728308, Timestamp: timestamp + 300},
+					},
+					Speed:           60,
+					DistanceCovered: 5,
+				},
+			}
+		}
 		close(rideSegmentsChan)
 	}()
 
@@ -406,8 +1345,87 @@ func TestEstimateWhenFareIsAboveMinimumAndMovingNight(t *testing.T) {
 
 	i := 0
 	for faresResult := range faresChan {
-		assert.Equal(t, expectedFareResult, faresResult)
+		assertFareEqual(t, expectedFareResults[i], faresResult)
 		i += 1
 	}
 
 }
+
+// Tests the FareService.Estimate surges the metered amount of each RideSegment with the multiplier at its start,
+// or the whole fare with the multiplier at the ride's first position, capped to the max surge amount.
+func TestEstimateWithSurge(t *testing.T) {
+	centre := zones.NewZone(
+		"centre",
+		[]zones.Polygon{
+			{
+				{
+					{Lat: 37.95, Lng: 23.70},
+					{Lat: 37.95, Lng: 23.76},
+					{Lat: 38.00, Lng: 23.76},
+					{Lat: 38.00, Lng: 23.70},
+					{Lat: 37.95, Lng: 23.70},
+				},
+			},
+		},
+		nil,
+	)
+	// The centre surges at midday, the ride starting there at 11:02 UTC.
+	surgeRules := []surges.SurgeRule{
+		*surges.NewSurgeRule("midday-centre", *surges.NewSurgeWindow(nil, 11*60, 14*60), centre, nil, 1.5),
+	}
+	// The ride covers 2km in the centre and then 3km outside of it, at the 0.74 default day rate.
+	rideSegments := []rides.RideSegment{
+		{
+			RideID: 1,
+			RidePositions: [2]rides.RidePosition{
+				{Id: 1, Lat: 37.966660, Lng: 23.728308, Timestamp: 1405594957},
+				{Id: 1, Lat: 37.940000, Lng: 23.728263, Timestamp: 1405595257},
+			},
+			Speed:           24,
+			DistanceCovered: 2,
+		},
+		{
+			RideID: 1,
+			RidePositions: [2]rides.RidePosition{
+				{Id: 1, Lat: 37.940000, Lng: 23.728263, Timestamp: 1405595257},
+				{Id: 1, Lat: 37.920000, Lng: 23.728263, Timestamp: 1405595557},
+			},
+			Speed:           36,
+			DistanceCovered: 3,
+		},
+	}
+
+	testCases := []struct {
+		surgeSchedule      *surges.SurgeSchedule
+		expectedEstimation money.Money
+		expectedSurge      money.Money
+		expectedMultiplier float64
+	}{
+		// 1.30 standard fare + 2km * 0.74 * 1.5 + 3km * 0.74.
+		{surges.NewSurgeSchedule(surges.MeteredSurge, time.UTC, 0, 0, 0, surgeRules), euros(5.74), euros(0.74), 1.2},
+		// (1.30 standard fare + 5km * 0.74) * 1.5.
+		{surges.NewSurgeSchedule(surges.RideSurge, time.UTC, 0, 0, 0, surgeRules), euros(7.5), euros(2.5), 1.5},
+		// (1.30 standard fare + 5km * 0.74) * 1.5, capped to 1.00 surge.
+		{surges.NewSurgeSchedule(surges.RideSurge, time.UTC, 0, 0, 1, surgeRules), euros(6), euros(1), 1.2},
+		// The multiplier of 1.5 capped to 1.1.
+		{surges.NewSurgeSchedule(surges.RideSurge, time.UTC, 0, 1.1, 0, surgeRules), euros(5.5), euros(0.5), 1.1},
+	}
+
+	for _, testCase := range testCases {
+		fareCalculator := NewTariffFareCalculator(FareCalculatorConfig{
+			Tariff: tariffs.DefaultTariff(),
+			Surge:  testCase.surgeSchedule,
+		})
+
+		fare := fareCalculator.Calculate(rideSegments)
+
+		assert.Equal(t, testCase.expectedEstimation, fare.Estimation())
+		assert.InDelta(t, testCase.expectedSurge.Float64(), fare.Breakdown.SurgeAmount.Float64(), 1e-5)
+		assert.InDelta(t, testCase.expectedMultiplier, fare.SurgeMultiplier, 1e-9)
+	}
+
+	// Without a SurgeSchedule, the fare is not surged.
+	fare := NewTariffFareCalculator(FareCalculatorConfig{Tariff: tariffs.DefaultTariff()}).Calculate(rideSegments)
+	assert.Equal(t, euros(5), fare.Estimation())
+	assert.Equal(t, 1.0, fare.SurgeMultiplier)
+}
@@ -0,0 +1,96 @@
+/*
+Package fares
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package fares
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/calendars"
+	"github.com/iliaskaras/fare-estimation/app/tariffs"
+	"time"
+)
+
+type tariffWindow string
+
+const (
+	// nightWindow is the time after 0 and before 5 the morning.
+	nightWindow tariffWindow = "night"
+	// dayWindow is the time after 5 the morning and before 24.
+	dayWindow tariffWindow = "day"
+
+	nightWindowEndHour = 5
+)
+
+// tariffWindowShare is the part of a RideSegment's elapsed time that falls in a single tariffWindow
+// of a single calendar day. The fraction is the share of the RideSegment's elapsed time, in the range (0, 1].
+type tariffWindowShare struct {
+	window   tariffWindow
+	dayType  tariffs.DayType
+	fraction float64
+}
+
+// splitOnTariffWindows splits the time between the start and end timestamps on the tariff window
+// boundaries of the Tariff's location, returning the share of the time spent in each window in
+// chronological order. Since a window never crosses midnight, each share falls in a single calendar
+// day, whose DayType is found using the Tariff's holidays. The boundaries are found in local time,
+// thus the daylight saving time switch days have windows shorter or longer than usual. When no time
+// elapses between the two timestamps, the whole share belongs to the window of the start timestamp.
+func splitOnTariffWindows(startTimestamp, endTimestamp int64, tariff *tariffs.Tariff) []tariffWindowShare {
+	start := time.Unix(startTimestamp, 0).In(tariff.Location)
+	end := time.Unix(endTimestamp, 0).In(tariff.Location)
+
+	if !end.After(start) {
+		window, _ := tariffWindowAt(start)
+		return []tariffWindowShare{{window: window, dayType: dayTypeAt(start, tariff.Holidays), fraction: 1}}
+	}
+
+	elapsedSecs := end.Sub(start).Seconds()
+	var shares []tariffWindowShare
+
+	for cursor := start; cursor.Before(end); {
+		window, windowEnd := tariffWindowAt(cursor)
+		if windowEnd.After(end) {
+			windowEnd = end
+		}
+
+		shares = append(
+			shares,
+			tariffWindowShare{
+				window:   window,
+				dayType:  dayTypeAt(cursor, tariff.Holidays),
+				fraction: windowEnd.Sub(cursor).Seconds() / elapsedSecs,
+			},
+		)
+		cursor = windowEnd
+	}
+
+	return shares
+}
+
+// tariffWindowAt returns the tariffWindow that the local time t falls in, along with the time that the window ends.
+func tariffWindowAt(t time.Time) (tariffWindow, time.Time) {
+	year, month, day := t.Date()
+
+	nightEnd := time.Date(year, month, day, nightWindowEndHour, 0, 0, 0, t.Location())
+	if t.Before(nightEnd) {
+		return nightWindow, nightEnd
+	}
+
+	return dayWindow, time.Date(year, month, day+1, 0, 0, 0, 0, t.Location())
+}
+
+// dayTypeAt returns the DayType of the calendar day that the local time t falls in.
+// A holiday takes precedence over a Sunday.
+func dayTypeAt(t time.Time, holidays *calendars.Calendar) tariffs.DayType {
+	if holidays != nil {
+		if _, ok := holidays.Holiday(t); ok {
+			return tariffs.Holiday
+		}
+	}
+
+	if t.Weekday() == time.Sunday {
+		return tariffs.Sunday
+	}
+
+	return tariffs.Weekday
+}
@@ -0,0 +1,211 @@
+/*
+Package fares
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package fares
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/calendars"
+	"github.com/iliaskaras/fare-estimation/app/tariffs"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type splitOnTariffWindowsTestCase struct {
+	name           string
+	startTimestamp int64
+	endTimestamp   int64
+	location       string
+	expectedShares []tariffWindowShare
+}
+
+// Tests the splitOnTariffWindows splits the elapsed time on the day and night boundaries.
+func TestSplitOnTariffWindows(t *testing.T) {
+	testCases := []splitOnTariffWindowsTestCase{
+		{
+			name: "within the night window",
+			// 2022-02-15 01:00 UTC - 01:10 UTC.
+			startTimestamp: 1644886800,
+			endTimestamp:   1644887400,
+			location:       "UTC",
+			expectedShares: []tariffWindowShare{
+				{window: nightWindow, fraction: 1},
+			},
+		},
+		{
+			name: "within the day window",
+			// 2022-02-15 10:00 UTC - 10:30 UTC.
+			startTimestamp: 1644919200,
+			endTimestamp:   1644921000,
+			location:       "UTC",
+			expectedShares: []tariffWindowShare{
+				{window: dayWindow, fraction: 1},
+			},
+		},
+		{
+			name: "crossing from night to day",
+			// 2022-02-15 04:59 UTC - 05:10 UTC.
+			startTimestamp: 1644901140,
+			endTimestamp:   1644901800,
+			location:       "UTC",
+			expectedShares: []tariffWindowShare{
+				{window: nightWindow, fraction: 60.0 / 660.0},
+				{window: dayWindow, fraction: 600.0 / 660.0},
+			},
+		},
+		{
+			name: "crossing from day to night at midnight",
+			// 2022-02-14 23:45 UTC - 2022-02-15 00:15 UTC.
+			startTimestamp: 1644882300,
+			endTimestamp:   1644884100,
+			location:       "UTC",
+			expectedShares: []tariffWindowShare{
+				{window: dayWindow, fraction: 0.5},
+				{window: nightWindow, fraction: 0.5},
+			},
+		},
+		{
+			name: "crossing a whole night",
+			// 2022-02-14 23:00 UTC - 2022-02-15 06:00 UTC.
+			startTimestamp: 1644879600,
+			endTimestamp:   1644904800,
+			location:       "UTC",
+			expectedShares: []tariffWindowShare{
+				{window: dayWindow, fraction: 1.0 / 7.0},
+				{window: nightWindow, fraction: 5.0 / 7.0},
+				{window: dayWindow, fraction: 1.0 / 7.0},
+			},
+		},
+		{
+			name: "ending exactly on the boundary",
+			// 2022-02-15 04:50 UTC - 05:00 UTC.
+			startTimestamp: 1644900600,
+			endTimestamp:   1644901200,
+			location:       "UTC",
+			expectedShares: []tariffWindowShare{
+				{window: nightWindow, fraction: 1},
+			},
+		},
+		{
+			name: "no elapsed time",
+			// 2022-02-15 05:00 UTC.
+			startTimestamp: 1644901200,
+			endTimestamp:   1644901200,
+			location:       "UTC",
+			expectedShares: []tariffWindowShare{
+				{window: dayWindow, fraction: 1},
+			},
+		},
+		{
+			name: "crossing the boundary in local time",
+			// 2022-02-15 02:50 UTC - 03:10 UTC, 04:50 - 05:10 in Athens (EET, UTC+2).
+			startTimestamp: 1644893400,
+			endTimestamp:   1644894600,
+			location:       "Europe/Athens",
+			expectedShares: []tariffWindowShare{
+				{window: nightWindow, fraction: 0.5},
+				{window: dayWindow, fraction: 0.5},
+			},
+		},
+		{
+			name: "crossing the switch to summer time",
+			// 2022-03-27 00:30 UTC - 02:30 UTC, 02:30 EET - 05:30 EEST in Athens, of which the
+			// 1.5 hours until 05:00 are night, since the clock jumps from 03:00 to 04:00.
+			startTimestamp: 1648341000,
+			endTimestamp:   1648348200,
+			location:       "Europe/Athens",
+			expectedShares: []tariffWindowShare{
+				{window: nightWindow, fraction: 0.75},
+				{window: dayWindow, fraction: 0.25},
+			},
+		},
+		{
+			name: "crossing the switch back to winter time",
+			// 2022-10-29 23:30 UTC - 2022-10-30 03:30 UTC, 02:30 EEST - 05:30 EET in Athens, of which the
+			// 3.5 hours until 05:00 are night, since the clock goes back from 04:00 to 03:00.
+			startTimestamp: 1667086200,
+			endTimestamp:   1667100600,
+			location:       "Europe/Athens",
+			expectedShares: []tariffWindowShare{
+				{window: nightWindow, fraction: 3.5 / 4.0},
+				{window: dayWindow, fraction: 0.5 / 4.0},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		location, err := time.LoadLocation(testCase.location)
+		assert.NoError(t, err)
+		tariff := tariffs.DefaultTariff()
+		tariff.Location = location
+
+		shares := splitOnTariffWindows(testCase.startTimestamp, testCase.endTimestamp, tariff)
+
+		assert.Equal(t, len(testCase.expectedShares), len(shares), testCase.name)
+		for i := range shares {
+			assert.Equal(t, testCase.expectedShares[i].window, shares[i].window, testCase.name)
+			assert.InDelta(t, testCase.expectedShares[i].fraction, shares[i].fraction, 1e-9, testCase.name)
+		}
+	}
+
+}
+
+// Tests the splitOnTariffWindows finds the DayType of each share, using the Tariff's holidays and location.
+func TestSplitOnTariffWindowsDayTypes(t *testing.T) {
+	athens, _ := time.LoadLocation("Europe/Athens")
+	holidays := calendars.NewCalendar()
+	// Easter Monday, 2022-04-25, in Greece.
+	holidays.Add(time.Date(2022, 4, 25, 0, 0, 0, 0, time.UTC), "Easter Monday")
+
+	tariff := tariffs.DefaultTariff()
+	tariff.Location = athens
+	tariff.Holidays = holidays
+
+	testCases := []struct {
+		name             string
+		startTimestamp   int64
+		endTimestamp     int64
+		expectedDayTypes []tariffs.DayType
+	}{
+		{
+			name: "weekday",
+			// 2022-04-22 10:00 - 10:30 in Athens, Friday.
+			startTimestamp:   1650610800,
+			endTimestamp:     1650612600,
+			expectedDayTypes: []tariffs.DayType{tariffs.Weekday},
+		},
+		{
+			name: "saturday to sunday at midnight",
+			// 2022-04-23 23:45 - 2022-04-24 00:15 in Athens.
+			startTimestamp:   1650746700,
+			endTimestamp:     1650748500,
+			expectedDayTypes: []tariffs.DayType{tariffs.Weekday, tariffs.Sunday},
+		},
+		{
+			name: "sunday to a holiday at midnight",
+			// 2022-04-24 23:45 - 2022-04-25 00:15 in Athens.
+			startTimestamp:   1650833100,
+			endTimestamp:     1650834900,
+			expectedDayTypes: []tariffs.DayType{tariffs.Sunday, tariffs.Holiday},
+		},
+		{
+			name: "holiday in local time only",
+			// 2022-04-24 21:30 UTC, 2022-04-25 00:30 in Athens, with no elapsed time.
+			startTimestamp:   1650835800,
+			endTimestamp:     1650835800,
+			expectedDayTypes: []tariffs.DayType{tariffs.Holiday},
+		},
+	}
+
+	for _, testCase := range testCases {
+		shares := splitOnTariffWindows(testCase.startTimestamp, testCase.endTimestamp, tariff)
+
+		dayTypes := make([]tariffs.DayType, len(shares))
+		for i, share := range shares {
+			dayTypes[i] = share.dayType
+		}
+		assert.Equal(t, testCase.expectedDayTypes, dayTypes, testCase.name)
+	}
+
+}
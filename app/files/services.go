@@ -15,8 +15,9 @@ import (
 )
 
 type FileService interface {
-	Read(filePath string, ridePositionsChan chan<- []rides.RidePosition) error
-	Write(output string, faresChan <-chan fares.Fare) (bool, error)
+	Read(filePath string, ridePositionsChan chan<- []rides.RidePosition, rejectionLog *rides.RejectionLog) error
+	Write(output string, faresChan <-chan fares.Fare, columns fares.FareColumns) (bool, error)
+	WriteRecords(output string, header []string, records [][]string) error
 }
 
 // csvFileService is the FileService implementor responsible for operating on .csv type of files.
@@ -29,11 +30,13 @@ func newCSVFileService() FileService {
 // Read parses a file that contain rows of ride positions, unmarshal the entries and
 // pushes the ride positions to the ridePositionsChan channel for further processing by
 // its receivers. The file is already sorted by RideID, and it makes a single push
-// to the channel for each RideID encountered through the file parsing.
+// to the channel for each RideID encountered through the file parsing. Each RidePosition keeps
+// its line in the file, and the rows that cannot be unmarshalled are added to the rejectionLog, if any.
 // - Pusher to the channel ridePositionsChan, where all the encountered RidePosition are pushed.
 func (fs *csvFileService) Read(
 	filePath string,
 	ridePositionsChan chan<- []rides.RidePosition,
+	rejectionLog *rides.RejectionLog,
 ) error {
 	// Since Read is the sender function of the ridePositionsChan channel, we close it here.
 	defer close(ridePositionsChan)
@@ -73,11 +76,14 @@ func (fs *csvFileService) Read(
 			return NewFileError(err, "failure on reading file records")
 		}
 
+		line, _ := reader.FieldPos(0)
 		ridePos, unmarshalErr := rides.Unmarshal(fileRecord)
-		// Skip entry in case there is an error during unmarshal.
+		// Skip entry in case there is an error during unmarshal, keeping it in the rejectionLog.
 		if unmarshalErr != nil {
+			rejectionLog.Add(*rides.NewParseErrorRejectedPosition(line, fileRecord))
 			continue
 		}
+		ridePos.Line = line
 
 		// Initialize the current RideID in the first iteration.
 		if currentRideID != ridePos.Id {
@@ -107,13 +113,15 @@ func (fs *csvFileService) Read(
 }
 
 // Write writes line by line, to the output file the fare estimates, each line represents
-// the Fare Estimation of a single RideID.
+// the Fare Estimation of a single RideID. The optional columns selected are written next to
+// the estimation, in which case the file starts with a header line naming the columns.
 // - Pusher to the channel fileWriteFinishChan, a flag channel indicating when the writing is finish.
 //	 Used to block the main goroutine and force it wait Write to finish.
 // - Receiver to the channel faresChan, where all the estimated Fares are pushed.
 func (fs *csvFileService) Write(
 	output string,
 	faresChan <-chan fares.Fare,
+	columns fares.FareColumns,
 ) (bool, error) {
 	file, err := os.Create(output)
 	if err != nil {
@@ -122,8 +130,15 @@ func (fs *csvFileService) Write(
 
 	writer := csv.NewWriter(file)
 
+	if columns.Any() {
+		if err := writer.Write(columns.Header()); err != nil {
+			file.Close()
+			return false, NewFileError(err, "unable to write the header")
+		}
+	}
+
 	for fare := range faresChan {
-		err := writer.Write(fare.ToStrings())
+		err := writer.Write(fare.ToStrings(columns))
 		if err != nil {
 			log.Println("failure while writing fare estimation with rideID: ", fare.RideID)
 		}
@@ -135,3 +150,33 @@ func (fs *csvFileService) Write(
 
 	return true, nil
 }
+
+// WriteRecords writes the header line, followed by the records line by line, to the output file.
+func (fs *csvFileService) WriteRecords(output string, header []string, records [][]string) error {
+	file, err := os.Create(output)
+	if err != nil {
+		return NewFileError(err, "unable to create the file")
+	}
+	defer file.Close()
+
+	return writeRecords(file, header, records)
+}
+
+// PrintRecords writes the header line, followed by the records line by line, to the standard output in .csv format.
+func PrintRecords(header []string, records [][]string) error {
+	return writeRecords(os.Stdout, header, records)
+}
+
+// writeRecords writes the header line, followed by the records line by line, to the writer in .csv format.
+func writeRecords(w io.Writer, header []string, records [][]string) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(header); err != nil {
+		return NewFileError(err, "unable to write the header")
+	}
+	if err := writer.WriteAll(records); err != nil {
+		return NewFileError(err, "unable to write the records")
+	}
+
+	return nil
+}
@@ -9,7 +9,9 @@ import (
 	"github.com/Flaque/filet"
 	"github.com/iliaskaras/fare-estimation/app/fares"
 	baseAppErrors "github.com/iliaskaras/fare-estimation/app/infrastructure/errors"
+	"github.com/iliaskaras/fare-estimation/app/money"
 	"github.com/iliaskaras/fare-estimation/app/rides"
+	"github.com/iliaskaras/fare-estimation/app/tariffs"
 	"github.com/stretchr/testify/assert"
 	"io/fs"
 	"os"
@@ -38,12 +40,14 @@ func TestCSVFileServiceReadSuccessfulExecution(t *testing.T) {
 				Lat:       37.955217,
 				Lng:       23.714548,
 				Timestamp: 1405595237,
+				Line:      1,
 			},
 			rides.RidePosition{
 				Id:        1,
 				Lat:       37.954302,
 				Lng:       23.71337,
 				Timestamp: 1405595284,
+				Line:      2,
 			},
 		},
 		{
@@ -52,18 +56,21 @@ func TestCSVFileServiceReadSuccessfulExecution(t *testing.T) {
 				Lat:       37.946545,
 				Lng:       23.754918,
 				Timestamp: 1405591065,
+				Line:      3,
 			},
 			rides.RidePosition{
 				Id:        2,
 				Lat:       37.946545,
 				Lng:       23.754918,
 				Timestamp: 1405591073,
+				Line:      4,
 			},
 			rides.RidePosition{
 				Id:        2,
 				Lat:       37.946545,
 				Lng:       23.754918,
 				Timestamp: 1405591084,
+				Line:      5,
 			},
 		},
 		{
@@ -72,6 +79,7 @@ func TestCSVFileServiceReadSuccessfulExecution(t *testing.T) {
 				Lat:       37.946545,
 				Lng:       23.754918,
 				Timestamp: 1405591084,
+				Line:      6,
 			},
 		},
 	}
@@ -81,6 +89,7 @@ func TestCSVFileServiceReadSuccessfulExecution(t *testing.T) {
 	go newCSVFileService().Read(
 		testInputFile.Name(),
 		testRidePositionsChan,
+		nil,
 	)
 
 	i := 0
@@ -93,6 +102,45 @@ func TestCSVFileServiceReadSuccessfulExecution(t *testing.T) {
 
 }
 
+// Tests the FileService.Read skips the rows that cannot be unmarshalled, adding them to the rejectionLog with
+// their line in the file.
+func TestCSVFileServiceReadAddsParseErrorsToRejectionLog(t *testing.T) {
+	defer filet.CleanUp(t)
+	testInputFile := filet.TmpFile(
+		t,
+		"",
+		"1,37.955217,23.714548,1405595237\n"+
+			"1,invalidFloat,23.713370,1405595284\n"+
+			"1,37.954302,23.713370,1405595294\n"+
+			"x,37.946545,23.754918,1405591065\n",
+	)
+	rejectionLog := rides.NewRejectionLog()
+	testRidePositionsChan := make(chan []rides.RidePosition)
+
+	go newCSVFileService().Read(
+		testInputFile.Name(),
+		testRidePositionsChan,
+		rejectionLog,
+	)
+
+	var lines []int
+	for ridePositionsResult := range testRidePositionsChan {
+		for _, ridePosition := range ridePositionsResult {
+			lines = append(lines, ridePosition.Line)
+		}
+	}
+
+	assert.Equal(t, []int{1, 3}, lines)
+	assert.Equal(
+		t,
+		[]rides.RejectedPosition{
+			{Line: 2, Fields: []string{"1", "invalidFloat", "23.713370", "1405595284"}, Reason: rides.ParseError},
+			{Line: 4, Fields: []string{"x", "37.946545", "23.754918", "1405591065"}, Reason: rides.ParseError},
+		},
+		rejectionLog.RejectedPositions(),
+	)
+}
+
 // Tests the csvFileService.Read() return an error when the FilePath is not provided.
 func TestCSVFileServiceReadReturnErrorWhenFilePathNotProvided(t *testing.T) {
 	testRidePositionsChan := make(chan []rides.RidePosition)
@@ -100,6 +148,7 @@ func TestCSVFileServiceReadReturnErrorWhenFilePathNotProvided(t *testing.T) {
 	err := newCSVFileService().Read(
 		"",
 		testRidePositionsChan,
+		nil,
 	)
 	assert.Error(t, err)
 
@@ -118,13 +167,14 @@ func TestCSVFileServiceReadReturnErrorWhenFileDoesNotExist(t *testing.T) {
 	err := newCSVFileService().Read(
 		"filethatnotexist.csv",
 		testRidePositionsChan,
+		nil,
 	)
 	assert.Error(t, err)
 
 	expectedFileError := &fs.PathError{
-		"open",
-		"filethatnotexist.csv",
-		syscall.ENOENT,
+		Op:   "open",
+		Path: "filethatnotexist.csv",
+		Err:  syscall.ENOENT,
 	}
 	expectedError := FileError{
 		BaseAppError: baseAppErrors.NewBaseAppError(expectedFileError, "unable to open the file"),
@@ -148,11 +198,11 @@ func TestCSVFileServiceWriteSuccessfulExecution(t *testing.T) {
 	go func() {
 		faresChan <- *fares.NewFare(
 			1,
-			1.0,
+			money.FromFloat(1.0, money.EUR),
 		)
 		faresChan <- *fares.NewFare(
 			2,
-			2.0,
+			money.FromFloat(2.0, money.EUR),
 		)
 		close(faresChan)
 	}()
@@ -160,6 +210,7 @@ func TestCSVFileServiceWriteSuccessfulExecution(t *testing.T) {
 	newCSVFileService().Write(
 		testOutputFile.Name(),
 		faresChan,
+		fares.FareColumns{},
 	)
 
 	file, _ := os.Open(testOutputFile.Name())
@@ -167,7 +218,7 @@ func TestCSVFileServiceWriteSuccessfulExecution(t *testing.T) {
 	reader := csv.NewReader(file)
 	fileRecord, _ := reader.ReadAll()
 
-	assert.Equal(t, [][]string{[]string{"1", "1"}, []string{"2", "2"}}, fileRecord)
+	assert.Equal(t, [][]string{[]string{"1", "1.00"}, []string{"2", "2.00"}}, fileRecord)
 
 }
 
@@ -178,15 +229,16 @@ func TestCSVFileServiceWriteReturnErrorWhenOsCreateFail(t *testing.T) {
 	ok, err := newCSVFileService().Write(
 		"",
 		faresChan,
+		fares.FareColumns{},
 	)
 
 	assert.Error(t, err)
 	assert.Equal(t, ok, false)
 
 	expectedFileError := &fs.PathError{
-		"open",
-		"",
-		syscall.ENOENT,
+		Op:   "open",
+		Path: "",
+		Err:  syscall.ENOENT,
 	}
 	expectedError := FileError{
 		BaseAppError: baseAppErrors.NewBaseAppError(expectedFileError, "unable to create the file"),
@@ -194,3 +246,73 @@ func TestCSVFileServiceWriteReturnErrorWhenOsCreateFail(t *testing.T) {
 
 	assert.Equal(t, expectedError, err)
 }
+
+// Tests the FileService.Write writes a header and the optional columns selected.
+func TestCSVFileServiceWriteWithColumns(t *testing.T) {
+	faresChan := make(chan fares.Fare)
+
+	defer filet.CleanUp(t)
+
+	testOutputFile := filet.TmpFile(
+		t,
+		"",
+		"",
+	)
+
+	go func() {
+		fare := fares.NewFare(
+			1,
+			money.FromFloat(1.0, money.EUR),
+		)
+		fare.DayTypes = []tariffs.DayType{tariffs.Weekday, tariffs.Sunday}
+		faresChan <- *fare
+		close(faresChan)
+	}()
+
+	newCSVFileService().Write(
+		testOutputFile.Name(),
+		faresChan,
+		fares.FareColumns{DayTypes: true},
+	)
+
+	file, _ := os.Open(testOutputFile.Name())
+	defer file.Close()
+	reader := csv.NewReader(file)
+	fileRecord, _ := reader.ReadAll()
+
+	assert.Equal(
+		t,
+		[][]string{{"ride_id", "fare", "day_types"}, {"1", "1.00", "weekday|sunday"}},
+		fileRecord,
+	)
+
+}
+
+// Tests the FileService.WriteRecords writes the header and the records.
+func TestCSVFileServiceWriteRecords(t *testing.T) {
+	defer filet.CleanUp(t)
+
+	testOutputFile := filet.TmpFile(
+		t,
+		"",
+		"",
+	)
+
+	err := newCSVFileService().WriteRecords(
+		testOutputFile.Name(),
+		[]string{"ride_id", "fare_a", "fare_b"},
+		[][]string{{"1", "3.47", "3.60"}, {"2", "5.00", "5.40"}},
+	)
+	assert.NoError(t, err)
+
+	file, _ := os.Open(testOutputFile.Name())
+	defer file.Close()
+	reader := csv.NewReader(file)
+	fileRecord, _ := reader.ReadAll()
+
+	assert.Equal(
+		t,
+		[][]string{{"ride_id", "fare_a", "fare_b"}, {"1", "3.47", "3.60"}, {"2", "5.00", "5.40"}},
+		fileRecord,
+	)
+}
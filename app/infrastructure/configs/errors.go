@@ -0,0 +1,24 @@
+/*
+Package configs
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package configs
+
+import (
+	"errors"
+	baseAppErrors "github.com/iliaskaras/fare-estimation/app/infrastructure/errors"
+)
+
+type ConfigError struct {
+	baseAppErrors.BaseAppError
+}
+
+func NewConfigError(err error, additionalInfo string) ConfigError {
+	return ConfigError{
+		BaseAppError: baseAppErrors.NewBaseAppError(err, additionalInfo),
+	}
+}
+
+var (
+	UnsupportedConfigFileType = errors.New("unsupported config file type")
+)
@@ -0,0 +1,32 @@
+/*
+Package configs
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package configs
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+var supportedConfigFileTypes = []string{".json", ".yaml", ".yml"}
+
+// GetConfigDecoder is responsible for returning the correct ConfigDecoder implementor,
+// based on the config file type provided.
+func GetConfigDecoder(filePath string) (ConfigDecoder, error) {
+	fileExtension := strings.ToLower(filepath.Ext(filePath))
+
+	if fileExtension == ".json" {
+		return newJSONConfigDecoder(), nil
+	}
+
+	if fileExtension == ".yaml" || fileExtension == ".yml" {
+		return newYAMLConfigDecoder(), nil
+	}
+
+	return nil, NewConfigError(
+		UnsupportedConfigFileType,
+		"provided config file type: "+fileExtension+", "+
+			"must be one of the: "+strings.Join(supportedConfigFileTypes[:], ",")+" \n",
+	)
+}
@@ -0,0 +1,49 @@
+/*
+Package configs
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package configs
+
+import (
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Tests the GetConfigDecoder return the implementor matching the provided config file type.
+func TestGetConfigDecoderReturnDecoderByFileType(t *testing.T) {
+	testCases := map[string]string{
+		"tariff.json": "*configs.jsonConfigDecoder",
+		"tariff.yaml": "*configs.yamlConfigDecoder",
+		"tariff.yml":  "*configs.yamlConfigDecoder",
+		"tariff.YAML": "*configs.yamlConfigDecoder",
+	}
+
+	for filePath, expectedDecoderType := range testCases {
+		configDecoder, err := GetConfigDecoder(filePath)
+		assert.NoError(t, err)
+
+		assert.Equal(t, expectedDecoderType, reflect.TypeOf(configDecoder).String())
+	}
+
+}
+
+// Tests the GetConfigDecoder return a ConfigError when the config file type is unsupported.
+func TestGetConfigDecoderReturnConfigErrorWhenFileTypeIsInvalid(t *testing.T) {
+	configDecoder, err := GetConfigDecoder("tariff.toml")
+	assert.Error(t, err)
+
+	_, ok := err.(ConfigError)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, nil, configDecoder)
+	assert.Equal(
+		t,
+		NewConfigError(
+			UnsupportedConfigFileType,
+			"provided config file type: .toml, "+
+				"must be one of the: "+strings.Join(supportedConfigFileTypes[:], ",")+" \n",
+		), err,
+	)
+
+}
@@ -0,0 +1,85 @@
+/*
+Package configs
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package configs
+
+import (
+	"encoding/json"
+	baseAppErrors "github.com/iliaskaras/fare-estimation/app/infrastructure/errors"
+	"gopkg.in/yaml.v3"
+	"os"
+)
+
+type ConfigDecoder interface {
+	Decode(filePath string, target interface{}) error
+}
+
+// jsonConfigDecoder is the ConfigDecoder implementor responsible for decoding .json config files.
+type jsonConfigDecoder struct{}
+
+func newJSONConfigDecoder() ConfigDecoder {
+	return &jsonConfigDecoder{}
+}
+
+// Decode opens the .json file found in filePath and decodes its content into the target.
+// Unknown fields are rejected, so that typos in the config are not silently ignored.
+func (cd *jsonConfigDecoder) Decode(filePath string, target interface{}) error {
+	file, err := openConfigFile(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(target); err != nil {
+		return NewConfigError(err, "unable to decode the config file: "+filePath)
+	}
+
+	return nil
+}
+
+// yamlConfigDecoder is the ConfigDecoder implementor responsible for decoding .yaml and .yml config files.
+type yamlConfigDecoder struct{}
+
+func newYAMLConfigDecoder() ConfigDecoder {
+	return &yamlConfigDecoder{}
+}
+
+// Decode opens the .yaml file found in filePath and decodes its content into the target.
+// Unknown fields are rejected, so that typos in the config are not silently ignored.
+func (cd *yamlConfigDecoder) Decode(filePath string, target interface{}) error {
+	file, err := openConfigFile(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(target); err != nil {
+		return NewConfigError(err, "unable to decode the config file: "+filePath)
+	}
+
+	return nil
+}
+
+// openConfigFile opens the config file found in filePath for reading.
+func openConfigFile(filePath string) (*os.File, error) {
+	if filePath == "" {
+		return nil, baseAppErrors.NewBaseAppError(
+			baseAppErrors.InvalidInputError,
+			"config file path is missing",
+		)
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, NewConfigError(err, "unable to open the config file")
+	}
+
+	return file, nil
+}
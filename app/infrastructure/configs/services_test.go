@@ -0,0 +1,78 @@
+/*
+Package configs
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package configs
+
+import (
+	"github.com/Flaque/filet"
+	baseAppErrors "github.com/iliaskaras/fare-estimation/app/infrastructure/errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type testConfig struct {
+	Name  string  `json:"name" yaml:"name"`
+	Value float64 `json:"value" yaml:"value"`
+}
+
+// Tests the jsonConfigDecoder.Decode decodes a .json file into the target.
+func TestJSONConfigDecoderDecodeSuccessfulExecution(t *testing.T) {
+	defer filet.CleanUp(t)
+	testConfigFile := filet.TmpFile(t, "", `{"name": "athens", "value": 1.30}`)
+
+	var config testConfig
+	err := newJSONConfigDecoder().Decode(testConfigFile.Name(), &config)
+	assert.NoError(t, err)
+
+	assert.Equal(t, testConfig{Name: "athens", Value: 1.30}, config)
+}
+
+// Tests the yamlConfigDecoder.Decode decodes a .yaml file into the target.
+func TestYAMLConfigDecoderDecodeSuccessfulExecution(t *testing.T) {
+	defer filet.CleanUp(t)
+	testConfigFile := filet.TmpFile(t, "", "name: athens\nvalue: 1.30\n")
+
+	var config testConfig
+	err := newYAMLConfigDecoder().Decode(testConfigFile.Name(), &config)
+	assert.NoError(t, err)
+
+	assert.Equal(t, testConfig{Name: "athens", Value: 1.30}, config)
+}
+
+// Tests the ConfigDecoder implementors reject fields that the target does not have.
+func TestConfigDecoderDecodeReturnErrorOnUnknownField(t *testing.T) {
+	defer filet.CleanUp(t)
+	testJSONFile := filet.TmpFile(t, "", `{"name": "athens", "valeu": 1.30}`)
+	testYAMLFile := filet.TmpFile(t, "", "name: athens\nvaleu: 1.30\n")
+
+	var config testConfig
+	err := newJSONConfigDecoder().Decode(testJSONFile.Name(), &config)
+	assert.Error(t, err)
+	_, ok := err.(ConfigError)
+	assert.Equal(t, true, ok)
+
+	err = newYAMLConfigDecoder().Decode(testYAMLFile.Name(), &config)
+	assert.Error(t, err)
+	_, ok = err.(ConfigError)
+	assert.Equal(t, true, ok)
+}
+
+// Tests the ConfigDecoder implementors return an error when the file path is missing or the file does not exist.
+func TestConfigDecoderDecodeReturnErrorWhenFileIsMissing(t *testing.T) {
+	var config testConfig
+
+	err := newJSONConfigDecoder().Decode("", &config)
+	assert.Equal(
+		t,
+		baseAppErrors.NewBaseAppError(baseAppErrors.InvalidInputError, "config file path is missing"),
+		err,
+	)
+
+	err = newYAMLConfigDecoder().Decode(filepath.Join(os.TempDir(), "configthatnotexist.yaml"), &config)
+	assert.Error(t, err)
+	_, ok := err.(ConfigError)
+	assert.Equal(t, true, ok)
+}
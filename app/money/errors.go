@@ -0,0 +1,26 @@
+/*
+Package money
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package money
+
+import (
+	"errors"
+	baseAppErrors "github.com/iliaskaras/fare-estimation/app/infrastructure/errors"
+)
+
+type MoneyError struct {
+	baseAppErrors.BaseAppError
+}
+
+func NewMoneyError(err error, additionalInfo string) MoneyError {
+	return MoneyError{
+		BaseAppError: baseAppErrors.NewBaseAppError(err, additionalInfo),
+	}
+}
+
+var (
+	UnsupportedCurrency     = errors.New("unsupported currency")
+	UnsupportedRoundingMode = errors.New("unsupported rounding mode")
+	MismatchedCurrency      = errors.New("mismatched currency")
+)
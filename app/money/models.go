@@ -0,0 +1,193 @@
+/*
+Package money
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package money
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// microsPerUnit is the number of micro units that a major unit of any Currency is made of.
+const microsPerUnit int64 = 1000000
+
+// Currency is an ISO 4217 currency, along with the number of its minor unit digits.
+type Currency struct {
+	Code       string
+	MinorUnits int
+}
+
+func NewCurrency(code string, minorUnits int) *Currency {
+	return &Currency{
+		code,
+		minorUnits,
+	}
+}
+
+// EUR is the default Currency.
+var EUR = *NewCurrency("EUR", 2)
+
+// RoundingMode is the way that an amount is rounded to a Currency's minor units.
+type RoundingMode string
+
+const (
+	// HalfUp rounds to the nearest minor unit, the halves away from zero.
+	HalfUp RoundingMode = "half_up"
+	// HalfEven rounds to the nearest minor unit, the halves to the even minor unit.
+	HalfEven RoundingMode = "half_even"
+	// UpToNickel rounds up to the next 0.05, or to the next minor unit when that is larger.
+	UpToNickel RoundingMode = "up_to_0.05"
+)
+
+// Money is an amount of a Currency, held in fixed point micro units of the major unit, so that the
+// amounts can be accumulated exactly, and are only rounded to the Currency's minor units when asked to.
+type Money struct {
+	micros   int64
+	Currency Currency
+}
+
+// FromFloat returns the Money of the amount, rounded to the nearest micro unit.
+func FromFloat(amount float64, currency Currency) Money {
+	return Money{
+		micros:   int64(math.Round(amount * float64(microsPerUnit))),
+		Currency: currency,
+	}
+}
+
+// FromMinorUnits returns the Money of the amount in the Currency's minor units, e.g. cents.
+func FromMinorUnits(amount int64, currency Currency) Money {
+	return Money{
+		micros:   amount * minorUnitMicros(currency),
+		Currency: currency,
+	}
+}
+
+// Add returns the sum of the two Money, panicking with a MoneyError when they are not of the same Currency.
+func (m Money) Add(other Money) Money {
+	currency := m.mustMatch(other)
+
+	return Money{micros: m.micros + other.micros, Currency: currency}
+}
+
+// Sub returns the difference of the two Money, panicking with a MoneyError when they are not of the same Currency.
+func (m Money) Sub(other Money) Money {
+	currency := m.mustMatch(other)
+
+	return Money{micros: m.micros - other.micros, Currency: currency}
+}
+
+// Mul returns the Money multiplied by the factor, rounded to the nearest micro unit.
+func (m Money) Mul(factor float64) Money {
+	return Money{micros: int64(math.Round(float64(m.micros) * factor)), Currency: m.Currency}
+}
+
+// Cmp returns -1, 0 or 1 when the Money is less than, equal to or greater than the other Money, panicking with
+// a MoneyError when they are not of the same Currency.
+func (m Money) Cmp(other Money) int {
+	m.mustMatch(other)
+
+	switch {
+	case m.micros < other.micros:
+		return -1
+	case m.micros > other.micros:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// IsZero returns whether the Money is zero.
+func (m Money) IsZero() bool {
+	return m.micros == 0
+}
+
+// Float64 returns the Money in major units, e.g. euros, for the computations that are not money arithmetic.
+func (m Money) Float64() float64 {
+	return float64(m.micros) / float64(microsPerUnit)
+}
+
+// MinorUnits returns the Money in whole minor units of its Currency, e.g. cents, rounded half up.
+func (m Money) MinorUnits() int64 {
+	increment := minorUnitMicros(m.Currency)
+
+	return roundHalfUp(m.micros, increment) / increment
+}
+
+// FloorTo returns the Money rounded down to a multiple of the increment, e.g. to the whole fare drops of a meter.
+func (m Money) FloorTo(increment Money) Money {
+	if increment.micros <= 0 {
+		return m
+	}
+
+	quotient := m.micros / increment.micros
+	if m.micros%increment.micros < 0 {
+		quotient--
+	}
+
+	return Money{micros: quotient * increment.micros, Currency: m.Currency}
+}
+
+// Round returns the Money rounded to its Currency's minor units with the RoundingMode.
+func (m Money) Round(mode RoundingMode) Money {
+	increment := minorUnitMicros(m.Currency)
+
+	switch mode {
+	case HalfEven:
+		return Money{micros: roundHalfEven(m.micros, increment), Currency: m.Currency}
+	case UpToNickel:
+		if nickel := microsPerUnit / 20; nickel > increment {
+			increment = nickel
+		}
+		return Money{micros: roundUp(m.micros, increment), Currency: m.Currency}
+	default:
+		return Money{micros: roundHalfUp(m.micros, increment), Currency: m.Currency}
+	}
+}
+
+// mustMatch returns the Currency of the two Money, panicking with a MoneyError when they are not of the same
+// Currency, since adding up the amounts of two currencies is a bug of the caller. The zero Money, of no
+// Currency, matches any Currency.
+func (m Money) mustMatch(other Money) Currency {
+	if m == (Money{}) {
+		return other.Currency
+	}
+	if other == (Money{}) {
+		return m.Currency
+	}
+	if m.Currency != other.Currency {
+		panic(
+			NewMoneyError(
+				MismatchedCurrency,
+				"currency: "+other.Currency.Code+" must be the currency: "+m.Currency.Code,
+			),
+		)
+	}
+
+	return m.Currency
+}
+
+// String returns the Money formatted to exactly its Currency's minor units, e.g. 3.40, rounding it half up
+// when it is not already rounded.
+func (m Money) String() string {
+	minorUnits := roundHalfUp(m.micros, minorUnitMicros(m.Currency)) / minorUnitMicros(m.Currency)
+
+	sign := ""
+	if minorUnits < 0 {
+		sign = "-"
+		minorUnits = -minorUnits
+	}
+
+	digits := strconv.FormatInt(minorUnits, 10)
+	if m.Currency.MinorUnits == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= m.Currency.MinorUnits {
+		digits = strings.Repeat("0", m.Currency.MinorUnits-len(digits)+1) + digits
+	}
+	point := len(digits) - m.Currency.MinorUnits
+
+	return sign + digits[:point] + "." + digits[point:]
+}
@@ -0,0 +1,117 @@
+/*
+Package money
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package money
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// Tests the Money arithmetic is exact, where the float64 arithmetic is not.
+func TestMoneyArithmetic(t *testing.T) {
+	amount := FromFloat(0, EUR)
+	for i := 0; i < 10; i++ {
+		amount = amount.Add(FromFloat(0.1, EUR))
+	}
+
+	assert.Equal(t, FromFloat(1, EUR), amount)
+	assert.Equal(t, FromMinorUnits(100, EUR), amount)
+	assert.Equal(t, FromFloat(0.7, EUR), amount.Sub(FromFloat(0.3, EUR)))
+	assert.Equal(t, FromFloat(2.22, EUR), FromFloat(0.74, EUR).Mul(3))
+	assert.Equal(t, 1.0, amount.Float64())
+
+	assert.Equal(t, -1, FromFloat(3.46, EUR).Cmp(FromFloat(3.47, EUR)))
+	assert.Equal(t, 0, FromFloat(3.47, EUR).Cmp(FromMinorUnits(347, EUR)))
+	assert.Equal(t, 1, FromFloat(3.48, EUR).Cmp(FromFloat(3.47, EUR)))
+	assert.Equal(t, true, FromFloat(0, EUR).IsZero())
+	assert.Equal(t, false, FromFloat(0.000001, EUR).IsZero())
+}
+
+// Tests the Money arithmetic panics with a MoneyError on the Money of two Currencies, the zero Money matching
+// any Currency.
+func TestMoneyArithmeticPanicsOnMismatchedCurrency(t *testing.T) {
+	usd := *NewCurrency("USD", 2)
+
+	assert.PanicsWithValue(
+		t,
+		NewMoneyError(MismatchedCurrency, "currency: USD must be the currency: EUR"),
+		func() { FromFloat(1, EUR).Add(FromFloat(1, usd)) },
+	)
+	assert.Panics(t, func() { FromFloat(1, EUR).Sub(FromFloat(1, usd)) })
+	assert.Panics(t, func() { FromFloat(1, EUR).Cmp(FromFloat(1, usd)) })
+
+	assert.Equal(t, FromFloat(1, usd), Money{}.Add(FromFloat(1, usd)))
+	assert.Equal(t, FromFloat(1, usd), FromFloat(1, usd).Sub(Money{}))
+}
+
+// Tests the Money Round method on each RoundingMode.
+func TestMoneyRound(t *testing.T) {
+	testCases := []struct {
+		amount   float64
+		mode     RoundingMode
+		expected float64
+	}{
+		{3.464999, HalfUp, 3.46},
+		{3.465, HalfUp, 3.47},
+		{3.475, HalfUp, 3.48},
+		{-3.465, HalfUp, -3.47},
+		{3.465, HalfEven, 3.46},
+		{3.475, HalfEven, 3.48},
+		{3.465001, HalfEven, 3.47},
+		{-3.465, HalfEven, -3.46},
+		{-3.475, HalfEven, -3.48},
+		{3.40, UpToNickel, 3.40},
+		{3.400001, UpToNickel, 3.45},
+		{3.41, UpToNickel, 3.45},
+		{3.46, UpToNickel, 3.50},
+		{-3.46, UpToNickel, -3.45},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(
+			t,
+			FromFloat(testCase.expected, EUR),
+			FromFloat(testCase.amount, EUR).Round(testCase.mode),
+			"%v rounded %s", testCase.amount, testCase.mode,
+		)
+	}
+
+	jpy := *NewCurrency("JPY", 0)
+	assert.Equal(t, FromFloat(1501, jpy), FromFloat(1500.5, jpy).Round(HalfUp))
+	assert.Equal(t, FromFloat(1500, jpy), FromFloat(1500.5, jpy).Round(HalfEven))
+	assert.Equal(t, FromFloat(1501, jpy), FromFloat(1500.01, jpy).Round(UpToNickel))
+}
+
+// Tests the Money String method formats the Money to exactly its Currency's minor units.
+func TestMoneyString(t *testing.T) {
+	assert.Equal(t, "3.47", FromFloat(3.4699999, EUR).String())
+	assert.Equal(t, "13.10", FromFloat(13.1, EUR).String())
+	assert.Equal(t, "0.05", FromFloat(0.05, EUR).String())
+	assert.Equal(t, "0.00", FromFloat(0, EUR).String())
+	assert.Equal(t, "-0.50", FromFloat(-0.5, EUR).String())
+	assert.Equal(t, "1501", FromFloat(1500.5, *NewCurrency("JPY", 0)).String())
+	assert.Equal(t, "1.250", FromFloat(1.25, *NewCurrency("BHD", 3)).String())
+}
+
+// Tests the Money MinorUnits method returns the whole minor units of the Currency.
+func TestMoneyMinorUnits(t *testing.T) {
+	assert.Equal(t, int64(347), FromFloat(3.47, EUR).MinorUnits())
+	assert.Equal(t, int64(347), FromFloat(3.4651, EUR).MinorUnits())
+	assert.Equal(t, int64(-347), FromFloat(-3.465, EUR).MinorUnits())
+	assert.Equal(t, int64(1250), FromFloat(1.25, *NewCurrency("BHD", 3)).MinorUnits())
+	assert.Equal(t, int64(1250), FromFloat(1249.5, *NewCurrency("JPY", 0)).MinorUnits())
+	assert.Equal(t, FromFloat(3.47, EUR), FromMinorUnits(FromFloat(3.47, EUR).MinorUnits(), EUR))
+}
+
+// Tests the Money FloorTo method rounds down to a multiple of the increment.
+func TestMoneyFloorTo(t *testing.T) {
+	drop := FromFloat(0.10, EUR)
+
+	assert.Equal(t, FromFloat(3.40, EUR), FromFloat(3.499999, EUR).FloorTo(drop))
+	assert.Equal(t, FromFloat(3.50, EUR), FromFloat(3.50, EUR).FloorTo(drop))
+	assert.Equal(t, FromFloat(0, EUR), FromFloat(0.09, EUR).FloorTo(drop))
+	assert.Equal(t, FromFloat(-0.10, EUR), FromFloat(-0.01, EUR).FloorTo(drop))
+	assert.Equal(t, FromFloat(3.456, EUR), FromFloat(3.456, EUR).FloorTo(FromFloat(0, EUR)))
+}
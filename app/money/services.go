@@ -0,0 +1,60 @@
+/*
+Package money
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package money
+
+import (
+	"strings"
+)
+
+// currencies are the supported Currency, keyed by their code.
+var currencies = map[string]Currency{
+	"AUD": *NewCurrency("AUD", 2),
+	"BHD": *NewCurrency("BHD", 3),
+	"CAD": *NewCurrency("CAD", 2),
+	"CHF": *NewCurrency("CHF", 2),
+	"CZK": *NewCurrency("CZK", 2),
+	"DKK": *NewCurrency("DKK", 2),
+	"EUR": EUR,
+	"GBP": *NewCurrency("GBP", 2),
+	"HUF": *NewCurrency("HUF", 2),
+	"JPY": *NewCurrency("JPY", 0),
+	"KWD": *NewCurrency("KWD", 3),
+	"NOK": *NewCurrency("NOK", 2),
+	"PLN": *NewCurrency("PLN", 2),
+	"RON": *NewCurrency("RON", 2),
+	"SEK": *NewCurrency("SEK", 2),
+	"TRY": *NewCurrency("TRY", 2),
+	"USD": *NewCurrency("USD", 2),
+}
+
+var roundingModes = []RoundingMode{HalfUp, HalfEven, UpToNickel}
+
+// ParseCurrency returns the Currency of the provided ISO 4217 code, case insensitively.
+func ParseCurrency(code string) (Currency, error) {
+	if currency, ok := currencies[strings.ToUpper(code)]; ok {
+		return currency, nil
+	}
+
+	return Currency{}, NewMoneyError(UnsupportedCurrency, "provided currency: "+code+" is not supported")
+}
+
+// ParseRoundingMode returns the RoundingMode of the provided name.
+func ParseRoundingMode(name string) (RoundingMode, error) {
+	for _, roundingMode := range roundingModes {
+		if string(roundingMode) == name {
+			return roundingMode, nil
+		}
+	}
+
+	supportedRoundingModes := make([]string, len(roundingModes))
+	for i, roundingMode := range roundingModes {
+		supportedRoundingModes[i] = string(roundingMode)
+	}
+
+	return "", NewMoneyError(
+		UnsupportedRoundingMode,
+		"provided rounding mode: "+name+", must be one of the: "+strings.Join(supportedRoundingModes, ","),
+	)
+}
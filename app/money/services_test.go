@@ -0,0 +1,47 @@
+/*
+Package money
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package money
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// Tests the ParseCurrency returns the Currency of a supported code.
+func TestParseCurrency(t *testing.T) {
+	currency, err := ParseCurrency("eur")
+	assert.NoError(t, err)
+	assert.Equal(t, EUR, currency)
+
+	currency, err = ParseCurrency("JPY")
+	assert.NoError(t, err)
+	assert.Equal(t, *NewCurrency("JPY", 0), currency)
+
+	currency, err = ParseCurrency("XYZ")
+	assert.Error(t, err)
+	assert.Equal(t, Currency{}, currency)
+	assert.Equal(t, NewMoneyError(UnsupportedCurrency, "provided currency: XYZ is not supported"), err)
+}
+
+// Tests the ParseRoundingMode returns the RoundingMode of a supported name.
+func TestParseRoundingMode(t *testing.T) {
+	for _, roundingMode := range []RoundingMode{HalfUp, HalfEven, UpToNickel} {
+		parsedRoundingMode, err := ParseRoundingMode(string(roundingMode))
+		assert.NoError(t, err)
+		assert.Equal(t, roundingMode, parsedRoundingMode)
+	}
+
+	roundingMode, err := ParseRoundingMode("down")
+	assert.Error(t, err)
+	assert.Equal(t, RoundingMode(""), roundingMode)
+	assert.Equal(
+		t,
+		NewMoneyError(
+			UnsupportedRoundingMode,
+			"provided rounding mode: down, must be one of the: half_up,half_even,up_to_0.05",
+		),
+		err,
+	)
+}
@@ -0,0 +1,53 @@
+/*
+Package money
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package money
+
+// minorUnitMicros returns the number of micro units that a minor unit of the Currency is made of.
+func minorUnitMicros(currency Currency) int64 {
+	micros := microsPerUnit
+	for i := 0; i < currency.MinorUnits && micros > 1; i++ {
+		micros /= 10
+	}
+
+	return micros
+}
+
+// roundHalfUp rounds the micros to a multiple of the increment, the halves away from zero.
+func roundHalfUp(micros int64, increment int64) int64 {
+	quotient, remainder := micros/increment, micros%increment
+
+	if remainder*2 >= increment {
+		quotient++
+	} else if remainder*2 <= -increment {
+		quotient--
+	}
+
+	return quotient * increment
+}
+
+// roundHalfEven rounds the micros to a multiple of the increment, the halves to the even multiple.
+func roundHalfEven(micros int64, increment int64) int64 {
+	quotient, remainder := micros/increment, micros%increment
+
+	switch {
+	case remainder*2 > increment, remainder*2 == increment && quotient%2 != 0:
+		quotient++
+	case remainder*2 < -increment, remainder*2 == -increment && quotient%2 != 0:
+		quotient--
+	}
+
+	return quotient * increment
+}
+
+// roundUp rounds the micros up to a multiple of the increment.
+func roundUp(micros int64, increment int64) int64 {
+	quotient, remainder := micros/increment, micros%increment
+
+	if remainder > 0 {
+		quotient++
+	}
+
+	return quotient * increment
+}
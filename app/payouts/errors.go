@@ -0,0 +1,25 @@
+/*
+Package payouts
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package payouts
+
+import (
+	"errors"
+	baseAppErrors "github.com/iliaskaras/fare-estimation/app/infrastructure/errors"
+)
+
+type PayoutError struct {
+	baseAppErrors.BaseAppError
+}
+
+func NewPayoutError(err error, additionalInfo string) PayoutError {
+	return PayoutError{
+		BaseAppError: baseAppErrors.NewBaseAppError(err, additionalInfo),
+	}
+}
+
+var (
+	InvalidPayoutRate  = errors.New("invalid payout rate")
+	UnsupportedPricing = errors.New("unsupported pricing")
+)
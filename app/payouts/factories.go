@@ -0,0 +1,20 @@
+/*
+Package payouts
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package payouts
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/money"
+)
+
+// GetPayoutService is responsible for initializing and injecting all the dependencies of the PayoutService,
+// with the PayoutPolicy of the payouts file found in filePath, totalling the payouts in the currency.
+func GetPayoutService(filePath string, currency money.Currency) (*PayoutService, error) {
+	payoutPolicy, err := LoadPayoutPolicy(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPayoutService(payoutPolicy, currency), nil
+}
@@ -0,0 +1,41 @@
+/*
+Package payouts
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package payouts
+
+import (
+	"github.com/Flaque/filet"
+	"github.com/iliaskaras/fare-estimation/app/infrastructure/configs"
+	"github.com/iliaskaras/fare-estimation/app/money"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Tests the GetPayoutService initializes and returns the PayoutService.
+func TestGetPayoutService(t *testing.T) {
+	defer filet.CleanUp(t)
+	payoutsDir := filet.TmpDir(t, "")
+	payoutsPath := filepath.Join(payoutsDir, "payouts.json")
+	filet.File(t, payoutsPath, `{"vat_rate": 0.24, "pricing": "exclusive", "commission_rate": 0.15}`)
+
+	payoutService, err := GetPayoutService(payoutsPath, money.EUR)
+	assert.NoError(t, err)
+
+	returnedServiceType := reflect.TypeOf(payoutService).String()
+	expectedServiceType := "*payouts.PayoutService"
+
+	assert.Equal(t, expectedServiceType, returnedServiceType)
+	assert.Equal(t, NewPayoutPolicy(0.24, ExclusivePricing, 0.15), payoutService.payoutPolicy)
+}
+
+// Tests the GetPayoutService return a ConfigError when the payouts file type is not supported.
+func TestGetPayoutServiceReturnErrorWhenFileTypeIsInvalid(t *testing.T) {
+	payoutService, err := GetPayoutService("payouts.txt", money.EUR)
+	assert.Error(t, err)
+
+	assert.Nil(t, payoutService)
+	assert.IsType(t, configs.ConfigError{}, err)
+}
@@ -0,0 +1,60 @@
+/*
+Package payouts
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package payouts
+
+import (
+	"fmt"
+	"github.com/iliaskaras/fare-estimation/app/money"
+)
+
+// Pricing is whether the fare estimations include the VAT or not.
+type Pricing string
+
+const (
+	// InclusivePricing takes the fare estimations as gross, the VAT being included in them.
+	InclusivePricing Pricing = "inclusive"
+	// ExclusivePricing takes the fare estimations as net, the VAT being charged on top of them.
+	ExclusivePricing Pricing = "exclusive"
+)
+
+// PayoutPolicy is the way that the fare estimations are split between the tax, the platform and the driver.
+// - VATRate: The VAT rate, e.g. 0.24 for 24%.
+// - Pricing: Whether the fare estimations include the VAT or not.
+// - CommissionRate: The platform commission rate charged on the net fare, e.g. 0.15 for 15%.
+type PayoutPolicy struct {
+	VATRate        float64
+	Pricing        Pricing
+	CommissionRate float64
+}
+
+func NewPayoutPolicy(vatRate float64, pricing Pricing, commissionRate float64) *PayoutPolicy {
+	return &PayoutPolicy{
+		VATRate:        vatRate,
+		Pricing:        pricing,
+		CommissionRate: commissionRate,
+	}
+}
+
+// PayoutSummary is the totals of the FarePayouts of the estimated rides.
+type PayoutSummary struct {
+	Rides        int
+	Net          money.Money
+	VAT          money.Money
+	Gross        money.Money
+	Commission   money.Money
+	DriverPayout money.Money
+}
+
+// Report returns the lines that the PayoutSummary is printed with.
+func (ps PayoutSummary) Report() []string {
+	return []string{
+		fmt.Sprintf("Rides: %d", ps.Rides),
+		fmt.Sprintf("Total net: %s %s", ps.Net, ps.Net.Currency.Code),
+		fmt.Sprintf("Total VAT: %s %s", ps.VAT, ps.VAT.Currency.Code),
+		fmt.Sprintf("Total gross: %s %s", ps.Gross, ps.Gross.Currency.Code),
+		fmt.Sprintf("Total commission: %s %s", ps.Commission, ps.Commission.Currency.Code),
+		fmt.Sprintf("Total driver payout: %s %s", ps.DriverPayout, ps.DriverPayout.Currency.Code),
+	}
+}
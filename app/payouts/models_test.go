@@ -0,0 +1,35 @@
+/*
+Package payouts
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package payouts
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// Tests the PayoutSummary Report returns the totals along with their currency.
+func TestPayoutSummaryReport(t *testing.T) {
+	payoutSummary := PayoutSummary{
+		Rides:        2,
+		Net:          euros(19.15),
+		VAT:          euros(4.59),
+		Gross:        euros(23.74),
+		Commission:   euros(2.87),
+		DriverPayout: euros(16.28),
+	}
+
+	assert.Equal(
+		t,
+		[]string{
+			"Rides: 2",
+			"Total net: 19.15 EUR",
+			"Total VAT: 4.59 EUR",
+			"Total gross: 23.74 EUR",
+			"Total commission: 2.87 EUR",
+			"Total driver payout: 16.28 EUR",
+		},
+		payoutSummary.Report(),
+	)
+}
@@ -0,0 +1,124 @@
+/*
+Package payouts
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package payouts
+
+import (
+	"fmt"
+	"github.com/iliaskaras/fare-estimation/app/fares"
+	"github.com/iliaskaras/fare-estimation/app/infrastructure/configs"
+	"github.com/iliaskaras/fare-estimation/app/money"
+)
+
+// payoutsDefinition is the payouts file.
+type payoutsDefinition struct {
+	VATRate        *float64 `json:"vat_rate" yaml:"vat_rate"`
+	Pricing        string   `json:"pricing" yaml:"pricing"`
+	CommissionRate *float64 `json:"commission_rate" yaml:"commission_rate"`
+}
+
+// LoadPayoutPolicy loads the PayoutPolicy of the payouts file found in filePath. The vat_rate and the
+// commission_rate are required, from 0 up to 1, and the pricing is either "inclusive" or "exclusive".
+func LoadPayoutPolicy(filePath string) (*PayoutPolicy, error) {
+	configDecoder, err := configs.GetConfigDecoder(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var definition payoutsDefinition
+	if err := configDecoder.Decode(filePath, &definition); err != nil {
+		return nil, err
+	}
+
+	if definition.VATRate == nil || *definition.VATRate < 0 || *definition.VATRate > 1 {
+		return nil, NewPayoutError(InvalidPayoutRate, "vat_rate must be given, from 0 up to 1")
+	}
+	if definition.CommissionRate == nil || *definition.CommissionRate < 0 || *definition.CommissionRate > 1 {
+		return nil, NewPayoutError(InvalidPayoutRate, "commission_rate must be given, from 0 up to 1")
+	}
+
+	pricing := Pricing(definition.Pricing)
+	if pricing != InclusivePricing && pricing != ExclusivePricing {
+		return nil, NewPayoutError(
+			UnsupportedPricing,
+			fmt.Sprintf("pricing: %q must be one of: %s, %s", definition.Pricing, InclusivePricing, ExclusivePricing),
+		)
+	}
+
+	return NewPayoutPolicy(*definition.VATRate, pricing, *definition.CommissionRate), nil
+}
+
+type PayoutService struct {
+	payoutPolicy *PayoutPolicy
+	summary      PayoutSummary
+}
+
+func NewPayoutService(payoutPolicy *PayoutPolicy, currency money.Currency) *PayoutService {
+	return &PayoutService{
+		payoutPolicy: payoutPolicy,
+		summary: PayoutSummary{
+			Net:          money.FromFloat(0, currency),
+			VAT:          money.FromFloat(0, currency),
+			Gross:        money.FromFloat(0, currency),
+			Commission:   money.FromFloat(0, currency),
+			DriverPayout: money.FromFloat(0, currency),
+		},
+	}
+}
+
+// Payout returns the FarePayout of a fare estimation under the PayoutService's PayoutPolicy. The VAT and the
+// Commission are rounded half up to the minor units of the estimation's currency, so that the Net and the VAT
+// add up to the Gross, and the Commission and the DriverPayout add up to the Net.
+func (ps *PayoutService) Payout(estimation money.Money) fares.FarePayout {
+	var net, vat, gross money.Money
+
+	if ps.payoutPolicy.Pricing == ExclusivePricing {
+		net = estimation
+		vat = net.Mul(ps.payoutPolicy.VATRate).Round(money.HalfUp)
+		gross = net.Add(vat)
+	} else {
+		gross = estimation
+		net = gross.Mul(1 / (1 + ps.payoutPolicy.VATRate)).Round(money.HalfUp)
+		vat = gross.Sub(net)
+	}
+
+	commission := net.Mul(ps.payoutPolicy.CommissionRate).Round(money.HalfUp)
+
+	return fares.FarePayout{
+		Net:          net,
+		VAT:          vat,
+		Gross:        gross,
+		Commission:   commission,
+		DriverPayout: net.Sub(commission),
+	}
+}
+
+// Settle sets the FarePayout of the payable amount of each of the estimated Fares, adding it up to the
+// PayoutService's PayoutSummary, and passes the Fares on. It closes the payoutFaresChan once the faresChan
+// is closed, and it must be the only one settling the Fares of a PayoutService, its PayoutSummary not
+// being guarded.
+// - Receiver of the channel faresChan.
+// - Sender of the channel payoutFaresChan.
+func (ps *PayoutService) Settle(faresChan <-chan fares.Fare, payoutFaresChan chan<- fares.Fare) {
+	for fare := range faresChan {
+		payout := ps.Payout(fare.Payable())
+		fare.Payout = &payout
+
+		ps.summary.Rides += 1
+		ps.summary.Net = ps.summary.Net.Add(payout.Net)
+		ps.summary.VAT = ps.summary.VAT.Add(payout.VAT)
+		ps.summary.Gross = ps.summary.Gross.Add(payout.Gross)
+		ps.summary.Commission = ps.summary.Commission.Add(payout.Commission)
+		ps.summary.DriverPayout = ps.summary.DriverPayout.Add(payout.DriverPayout)
+
+		payoutFaresChan <- fare
+	}
+
+	close(payoutFaresChan)
+}
+
+// Summary returns the PayoutSummary of the settled Fares, to be read once Settle has returned.
+func (ps *PayoutService) Summary() PayoutSummary {
+	return ps.summary
+}
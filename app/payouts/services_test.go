@@ -0,0 +1,150 @@
+/*
+Package payouts
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package payouts
+
+import (
+	"github.com/Flaque/filet"
+	"github.com/iliaskaras/fare-estimation/app/fares"
+	"github.com/iliaskaras/fare-estimation/app/money"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func euros(amount float64) money.Money {
+	return money.FromFloat(amount, money.EUR)
+}
+
+// Tests the LoadPayoutPolicy returns the PayoutPolicy of the payouts file.
+func TestLoadPayoutPolicySuccessfulExecution(t *testing.T) {
+	defer filet.CleanUp(t)
+	payoutsDir := filet.TmpDir(t, "")
+	payoutsPath := filepath.Join(payoutsDir, "payouts.yaml")
+	filet.File(t, payoutsPath, "vat_rate: 0.24\npricing: inclusive\ncommission_rate: 0.15\n")
+
+	payoutPolicy, err := LoadPayoutPolicy(payoutsPath)
+	assert.NoError(t, err)
+
+	assert.Equal(t, NewPayoutPolicy(0.24, InclusivePricing, 0.15), payoutPolicy)
+}
+
+// Tests the LoadPayoutPolicy return a PayoutError when a rate is missing or out of range, or the pricing is unsupported.
+func TestLoadPayoutPolicyReturnErrorWhenPolicyIsInvalid(t *testing.T) {
+	defer filet.CleanUp(t)
+	payoutsDir := filet.TmpDir(t, "")
+	payoutsPath := filepath.Join(payoutsDir, "payouts.yaml")
+
+	testCases := []struct {
+		content       string
+		expectedError error
+	}{
+		{
+			"pricing: inclusive\ncommission_rate: 0.15\n",
+			NewPayoutError(InvalidPayoutRate, "vat_rate must be given, from 0 up to 1"),
+		},
+		{
+			"vat_rate: 0.24\npricing: inclusive\ncommission_rate: 1.5\n",
+			NewPayoutError(InvalidPayoutRate, "commission_rate must be given, from 0 up to 1"),
+		},
+		{
+			"vat_rate: 0.24\npricing: gross\ncommission_rate: 0.15\n",
+			NewPayoutError(UnsupportedPricing, `pricing: "gross" must be one of: inclusive, exclusive`),
+		},
+	}
+
+	for _, testCase := range testCases {
+		if err := os.WriteFile(payoutsPath, []byte(testCase.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		payoutPolicy, err := LoadPayoutPolicy(payoutsPath)
+		assert.Error(t, err)
+
+		assert.Nil(t, payoutPolicy)
+		assert.Equal(t, testCase.expectedError, err)
+	}
+}
+
+// Tests the PayoutService.Payout takes the VAT out of the estimation with the inclusive pricing.
+func TestPayoutWithInclusivePricing(t *testing.T) {
+	payoutService := NewPayoutService(NewPayoutPolicy(0.24, InclusivePricing, 0.15), money.EUR)
+
+	assert.Equal(
+		t,
+		fares.FarePayout{
+			Net:          euros(10),
+			VAT:          euros(2.4),
+			Gross:        euros(12.4),
+			Commission:   euros(1.5),
+			DriverPayout: euros(8.5),
+		},
+		payoutService.Payout(euros(12.4)),
+	)
+	// The rounded Net and VAT still add up to the Gross.
+	assert.Equal(
+		t,
+		fares.FarePayout{
+			Net:          euros(9.15),
+			VAT:          euros(2.19),
+			Gross:        euros(11.34),
+			Commission:   euros(1.37),
+			DriverPayout: euros(7.78),
+		},
+		payoutService.Payout(euros(11.34)),
+	)
+}
+
+// Tests the PayoutService.Payout charges the VAT on top of the estimation with the exclusive pricing.
+func TestPayoutWithExclusivePricing(t *testing.T) {
+	payoutService := NewPayoutService(NewPayoutPolicy(0.24, ExclusivePricing, 0.15), money.EUR)
+
+	assert.Equal(
+		t,
+		fares.FarePayout{
+			Net:          euros(10),
+			VAT:          euros(2.4),
+			Gross:        euros(12.4),
+			Commission:   euros(1.5),
+			DriverPayout: euros(8.5),
+		},
+		payoutService.Payout(euros(10)),
+	)
+}
+
+// Tests the PayoutService.Settle sets the FarePayout of each Fare and totals them in the PayoutSummary.
+func TestSettleSuccessfulExecution(t *testing.T) {
+	payoutService := NewPayoutService(NewPayoutPolicy(0.24, InclusivePricing, 0.15), money.EUR)
+	faresChan := make(chan fares.Fare)
+	payoutFaresChan := make(chan fares.Fare)
+
+	go func() {
+		faresChan <- *fares.NewFare(1, euros(12.4))
+		faresChan <- *fares.NewFare(2, euros(11.34))
+		close(faresChan)
+	}()
+	go payoutService.Settle(faresChan, payoutFaresChan)
+
+	var settledFares []fares.Fare
+	for fare := range payoutFaresChan {
+		settledFares = append(settledFares, fare)
+	}
+
+	assert.Len(t, settledFares, 2)
+	assert.Equal(t, payoutService.Payout(euros(12.4)), *settledFares[0].Payout)
+	assert.Equal(t, payoutService.Payout(euros(11.34)), *settledFares[1].Payout)
+	assert.Equal(
+		t,
+		PayoutSummary{
+			Rides:        2,
+			Net:          euros(19.15),
+			VAT:          euros(4.59),
+			Gross:        euros(23.74),
+			Commission:   euros(2.87),
+			DriverPayout: euros(16.28),
+		},
+		payoutService.Summary(),
+	)
+}
@@ -0,0 +1,26 @@
+/*
+Package pools
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package pools
+
+import (
+	"errors"
+	baseAppErrors "github.com/iliaskaras/fare-estimation/app/infrastructure/errors"
+)
+
+type PoolError struct {
+	baseAppErrors.BaseAppError
+}
+
+func NewPoolError(err error, additionalInfo string) PoolError {
+	return PoolError{
+		BaseAppError: baseAppErrors.NewBaseAppError(err, additionalInfo),
+	}
+}
+
+var (
+	InvalidPassenger       = errors.New("invalid passenger")
+	DuplicatePassenger     = errors.New("duplicate passenger")
+	UnsupportedSplitPolicy = errors.New("unsupported split policy")
+)
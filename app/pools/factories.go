@@ -0,0 +1,39 @@
+/*
+Package pools
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package pools
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/fares"
+	"github.com/iliaskaras/fare-estimation/app/tariffs"
+)
+
+// GetPoolService is responsible for initializing and injecting all the dependencies of the PoolService,
+// pricing the rides with the FareCalculator of the fare strategy and splitting their fares among the
+// passengers with the split policy, either "equal" or "distance".
+func GetPoolService(
+	fareStrategy string,
+	config fares.FareCalculatorConfig,
+	passengers map[int][]Passenger,
+	splitPolicy string,
+) (*PoolService, error) {
+	policy := SplitPolicy(splitPolicy)
+	if policy != EqualSplit && policy != DistanceSplit {
+		return nil, NewPoolError(
+			UnsupportedSplitPolicy,
+			"split policy: "+splitPolicy+" must be one of: "+string(EqualSplit)+", "+string(DistanceSplit),
+		)
+	}
+
+	if config.Tariff == nil {
+		config.Tariff = tariffs.DefaultTariff()
+	}
+
+	fareCalculator, err := fares.GetFareCalculator(fareStrategy, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPoolService(fareCalculator, passengers, policy), nil
+}
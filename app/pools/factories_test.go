@@ -0,0 +1,33 @@
+/*
+Package pools
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package pools
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/fares"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+// Tests the GetPoolService initializes and returns the PoolService.
+func TestGetPoolService(t *testing.T) {
+	poolService, err := GetPoolService(fares.TariffStrategy, fares.FareCalculatorConfig{}, nil, "distance")
+	assert.NoError(t, err)
+
+	returnedServiceType := reflect.TypeOf(poolService).String()
+	expectedServiceType := "*pools.PoolService"
+
+	assert.Equal(t, expectedServiceType, returnedServiceType)
+	assert.Equal(t, DistanceSplit, poolService.splitPolicy)
+}
+
+// Tests the GetPoolService return a PoolError when the split policy is not supported.
+func TestGetPoolServiceReturnErrorWhenSplitPolicyIsInvalid(t *testing.T) {
+	poolService, err := GetPoolService(fares.TariffStrategy, fares.FareCalculatorConfig{}, nil, "random")
+	assert.Error(t, err)
+
+	assert.Nil(t, poolService)
+	assert.Equal(t, NewPoolError(UnsupportedSplitPolicy, "split policy: random must be one of: equal, distance"), err)
+}
@@ -0,0 +1,85 @@
+/*
+Package pools
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package pools
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/money"
+	"strconv"
+)
+
+// SplitPolicy is the way that the fare of the stretches shared by several passengers is split among them.
+type SplitPolicy string
+
+const (
+	// EqualSplit splits a shared stretch equally among the passengers on board.
+	EqualSplit SplitPolicy = "equal"
+	// DistanceSplit splits a shared stretch among the passengers on board in proportion to the
+	// distance that each of them travels in the ride.
+	DistanceSplit SplitPolicy = "distance"
+)
+
+// Passenger is a passenger of a pooled ride, on board from the Pickup up to the Dropoff timestamp.
+// - RideID: The ride that the passenger travels in.
+// - ID: The passenger's identifier, unique within the ride.
+// - Pickup, Dropoff: The unix timestamps that the passenger gets on and off the ride.
+type Passenger struct {
+	RideID  int
+	ID      string
+	Pickup  int64
+	Dropoff int64
+}
+
+func NewPassenger(rideID int, id string, pickup int64, dropoff int64) *Passenger {
+	return &Passenger{
+		RideID:  rideID,
+		ID:      id,
+		Pickup:  pickup,
+		Dropoff: dropoff,
+	}
+}
+
+// PassengerFare is the share of a single passenger in the fare of a pooled ride.
+// - PassengerID: The passenger's identifier, empty when the ride has no passengers in the passengers file.
+// - DistanceKM: The distance that the passenger travelled on board.
+// - Fare: The passenger's share of the RideFare.
+// - RideFare: The fare estimation of the whole ride.
+type PassengerFare struct {
+	RideID      int
+	PassengerID string
+	DistanceKM  float64
+	Fare        money.Money
+	RideFare    money.Money
+}
+
+func NewPassengerFare(
+	rideID int,
+	passengerID string,
+	distanceKM float64,
+	fare money.Money,
+	rideFare money.Money,
+) *PassengerFare {
+	return &PassengerFare{
+		RideID:      rideID,
+		PassengerID: passengerID,
+		DistanceKM:  distanceKM,
+		Fare:        fare,
+		RideFare:    rideFare,
+	}
+}
+
+// PassengerFareHeader returns the names of the columns that the PassengerFare ToStrings returns.
+func PassengerFareHeader() []string {
+	return []string{"ride_id", "passenger_id", "distance_km", "fare", "ride_fare"}
+}
+
+func (pf PassengerFare) ToStrings() []string {
+	return []string{
+		strconv.Itoa(pf.RideID),
+		pf.PassengerID,
+		strconv.FormatFloat(pf.DistanceKM, 'f', 3, 64),
+		pf.Fare.String(),
+		pf.RideFare.String(),
+	}
+}
@@ -0,0 +1,238 @@
+/*
+Package pools
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package pools
+
+import (
+	"encoding/csv"
+	"github.com/iliaskaras/fare-estimation/app/fares"
+	"github.com/iliaskaras/fare-estimation/app/rides"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LoadPassengers parses a .csv file that contains a passenger per row, in the format:
+// ride_id,passenger_id,pickup,dropoff with the pickup and the dropoff being unix timestamps,
+// and returns the Passengers of each RideID in the order of the file. A header row starting
+// with "ride_id" is skipped. Every passenger must have an identifier that is unique within
+// its ride, and a pickup before its dropoff.
+func LoadPassengers(filePath string) (map[int][]Passenger, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, NewPoolError(err, "failure on opening passengers file: "+filePath)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = 4
+	reader.TrimLeadingSpace = true
+
+	passengers := make(map[int][]Passenger)
+	line := 0
+
+	for {
+		fileRecord, err := reader.Read()
+		line += 1
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, NewPoolError(err, "failure on reading passenger records")
+		}
+
+		rawRideID := strings.TrimSpace(fileRecord[0])
+		if line == 1 && strings.EqualFold(rawRideID, "ride_id") {
+			continue
+		}
+
+		rideID, errRideID := strconv.Atoi(rawRideID)
+		pickup, errPickup := strconv.ParseInt(strings.TrimSpace(fileRecord[2]), 10, 64)
+		dropoff, errDropoff := strconv.ParseInt(strings.TrimSpace(fileRecord[3]), 10, 64)
+		if errRideID != nil || errPickup != nil || errDropoff != nil {
+			return nil, NewPoolError(
+				InvalidPassenger,
+				"line: "+strconv.Itoa(line)+" must hold an integer ride id, pickup and dropoff",
+			)
+		}
+
+		passengerID := strings.TrimSpace(fileRecord[1])
+		if passengerID == "" {
+			return nil, NewPoolError(InvalidPassenger, "line: "+strconv.Itoa(line)+" must hold a passenger id")
+		}
+		if pickup >= dropoff {
+			return nil, NewPoolError(
+				InvalidPassenger,
+				"passenger: "+passengerID+" of ride: "+rawRideID+" must be picked up before being dropped off",
+			)
+		}
+
+		for _, passenger := range passengers[rideID] {
+			if passenger.ID == passengerID {
+				return nil, NewPoolError(
+					DuplicatePassenger,
+					"passenger: "+passengerID+" of ride: "+rawRideID+" is defined more than once",
+				)
+			}
+		}
+
+		passengers[rideID] = append(passengers[rideID], *NewPassenger(rideID, passengerID, pickup, dropoff))
+	}
+
+	return passengers, nil
+}
+
+type PoolService struct {
+	fareCalculator fares.FareCalculator
+	passengers     map[int][]Passenger
+	splitPolicy    SplitPolicy
+}
+
+func NewPoolService(
+	fareCalculator fares.FareCalculator,
+	passengers map[int][]Passenger,
+	splitPolicy SplitPolicy,
+) *PoolService {
+	return &PoolService{
+		fareCalculator: fareCalculator,
+		passengers:     passengers,
+		splitPolicy:    splitPolicy,
+	}
+}
+
+// Split prices the filtered RideSegments of each RideID with the PoolService's FareCalculator, and splits the
+// fare of the ride among its Passengers, returning a PassengerFare per passenger sorted by RideID and in the
+// order of the passengers file. The rides without passengers are returned as a single PassengerFare.
+// - Receiver of the channel rideSegmentsChan.
+func (ps *PoolService) Split(rideSegmentsChan <-chan []rides.RideSegment) []PassengerFare {
+	var passengerFares []PassengerFare
+
+	for rideSegments := range rideSegmentsChan {
+		// Case where the RideID had only one RidePosition in the input file.
+		if rideSegments == nil {
+			continue
+		}
+
+		passengerFares = append(passengerFares, ps.SplitRide(rideSegments)...)
+	}
+
+	sort.SliceStable(passengerFares, func(i, j int) bool {
+		return passengerFares[i].RideID < passengerFares[j].RideID
+	})
+
+	return passengerFares
+}
+
+// SplitRide splits the fare of the rideSegments among the Passengers of their RideID. Each RideSegment weighs
+// in the fare with its metered amount, and each of its stretches between the pickups and the dropoffs is split
+// among the passengers on board according to the SplitPolicy. The stretches with no passenger on board, e.g. on
+// the way to the first pickup, are split among all the passengers. The
+// shares are rounded to the minor units of the fare's currency, adding up to the fare of the ride.
+func (ps *PoolService) SplitRide(rideSegments []rides.RideSegment) []PassengerFare {
+	fare := ps.fareCalculator.Calculate(rideSegments)
+	passengers := ps.passengers[fare.RideID]
+
+	if len(passengers) == 0 {
+		var distanceKM float64
+		for _, rideSegment := range rideSegments {
+			distanceKM += rideSegment.DistanceCovered
+		}
+
+		return []PassengerFare{
+			*NewPassengerFare(fare.RideID, "", distanceKM, fare.Estimation(), fare.Estimation()),
+		}
+	}
+
+	distancesKM := make([]float64, len(passengers))
+	for _, rideSegment := range rideSegments {
+		for j, passenger := range passengers {
+			distancesKM[j] += rideSegment.DistanceCovered * onBoardShare(rideSegment, passenger)
+		}
+	}
+
+	policyWeights := make([]float64, len(passengers))
+	for j := range passengers {
+		policyWeights[j] = 1
+		if ps.splitPolicy == DistanceSplit && sum(distancesKM) > 0 {
+			policyWeights[j] = distancesKM[j]
+		}
+	}
+
+	segmentWeights := ps.segmentWeights(rideSegments)
+	shares := make([]float64, len(passengers))
+
+	for i, rideSegment := range rideSegments {
+		for _, stretch := range stretches(rideSegment, passengers) {
+			passengerWeights := make([]float64, len(passengers))
+			for j := range passengers {
+				if stretch.onBoard[j] {
+					passengerWeights[j] = policyWeights[j]
+				}
+			}
+			if sum(passengerWeights) == 0 {
+				copy(passengerWeights, policyWeights)
+			}
+
+			totalWeight := sum(passengerWeights)
+			if totalWeight == 0 {
+				continue
+			}
+			for j := range passengers {
+				shares[j] += segmentWeights[i] * stretch.share * passengerWeights[j] / totalWeight
+			}
+		}
+	}
+
+	passengerAmounts := allocate(fare.Estimation(), shares)
+	passengerFares := make([]PassengerFare, len(passengers))
+	for j, passenger := range passengers {
+		passengerFares[j] = *NewPassengerFare(
+			fare.RideID,
+			passenger.ID,
+			distancesKM[j],
+			passengerAmounts[j],
+			fare.Estimation(),
+		)
+	}
+
+	return passengerFares
+}
+
+// segmentWeights returns the metered amount of each of the rideSegments along with its surge, priced on its
+// own with the PoolService's FareCalculator, or its elapsed time when the FareCalculator does not break its
+// fares down.
+func (ps *PoolService) segmentWeights(rideSegments []rides.RideSegment) []float64 {
+	weights := make([]float64, len(rideSegments))
+	var total float64
+
+	for i, rideSegment := range rideSegments {
+		breakdown := ps.fareCalculator.Calculate([]rides.RideSegment{rideSegment}).Breakdown
+		weights[i] = breakdown.MovingDayAmount.Float64() +
+			breakdown.MovingNightAmount.Float64() +
+			breakdown.IdleAmount.Float64() +
+			breakdown.SurgeAmount.Float64()
+		total += weights[i]
+	}
+
+	if total > 0 {
+		return weights
+	}
+
+	for i, rideSegment := range rideSegments {
+		weights[i] = float64(rideSegment.RidePositions[1].Timestamp - rideSegment.RidePositions[0].Timestamp)
+	}
+	if sum(weights) > 0 {
+		return weights
+	}
+
+	// The RideSegments of no elapsed time weigh equally.
+	for i := range weights {
+		weights[i] = 1
+	}
+
+	return weights
+}
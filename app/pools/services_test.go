@@ -0,0 +1,225 @@
+/*
+Package pools
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package pools
+
+import (
+	"github.com/Flaque/filet"
+	"github.com/iliaskaras/fare-estimation/app/fares"
+	"github.com/iliaskaras/fare-estimation/app/money"
+	"github.com/iliaskaras/fare-estimation/app/rides"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// perKMFareCalculator is a fares.FareCalculator charging a fixed amount per km covered, without a breakdown.
+type perKMFareCalculator struct {
+	amountPerKM float64
+}
+
+func (fc *perKMFareCalculator) Calculate(rideSegments []rides.RideSegment) fares.Fare {
+	distanceCovered := 0.0
+	for _, rideSegment := range rideSegments {
+		distanceCovered += rideSegment.DistanceCovered
+	}
+
+	return *fares.NewFare(rideSegments[0].RideID, money.FromFloat(distanceCovered*fc.amountPerKM, money.EUR))
+}
+
+func euros(amount float64) money.Money {
+	return money.FromFloat(amount, money.EUR)
+}
+
+// rideSegment returns a RideSegment of the ride 1 from the start up to the end timestamp, covering the distanceKM.
+func rideSegment(start int64, end int64, distanceKM float64) rides.RideSegment {
+	return *rides.NewRideSegment(
+		1,
+		[2]rides.RidePosition{
+			*rides.NewRidePosition(1, 37.966660, 23.728308, start),
+			*rides.NewRidePosition(1, 37.966660, 23.728308, end),
+		},
+		0,
+		distanceKM,
+	)
+}
+
+// pooledRideSegments are the RideSegments of a ride of 6 km, 2 km every 100 seconds.
+var pooledRideSegments = []rides.RideSegment{
+	rideSegment(0, 100, 2),
+	rideSegment(100, 200, 2),
+	rideSegment(200, 300, 2),
+}
+
+// pooledPassengers are the Passengers of the ride 1, the passenger b sharing its second RideSegment.
+var pooledPassengers = map[int][]Passenger{
+	1: {
+		*NewPassenger(1, "a", 0, 300),
+		*NewPassenger(1, "b", 100, 200),
+	},
+}
+
+// Tests the LoadPassengers returns the Passengers of each RideID in the order of the file.
+func TestLoadPassengersSuccessfulExecution(t *testing.T) {
+	defer filet.CleanUp(t)
+	passengersFile := filet.TmpFile(t, "", "ride_id,passenger_id,pickup,dropoff\n1,a,0,300\n2,c,10,20\n1,b,100,200\n")
+
+	passengers, err := LoadPassengers(passengersFile.Name())
+	assert.NoError(t, err)
+
+	assert.Equal(
+		t,
+		map[int][]Passenger{
+			1: {*NewPassenger(1, "a", 0, 300), *NewPassenger(1, "b", 100, 200)},
+			2: {*NewPassenger(2, "c", 10, 20)},
+		},
+		passengers,
+	)
+}
+
+// Tests the LoadPassengers return a PoolError when a passenger is invalid or defined more than once.
+func TestLoadPassengersReturnErrorWhenPassengerIsInvalid(t *testing.T) {
+	defer filet.CleanUp(t)
+
+	testCases := []struct {
+		content       string
+		expectedError error
+	}{
+		{"1,a,zero,300\n", NewPoolError(InvalidPassenger, "line: 1 must hold an integer ride id, pickup and dropoff")},
+		{"1, ,0,300\n", NewPoolError(InvalidPassenger, "line: 1 must hold a passenger id")},
+		{
+			"1,a,300,300\n",
+			NewPoolError(InvalidPassenger, "passenger: a of ride: 1 must be picked up before being dropped off"),
+		},
+		{
+			"1,a,0,300\n1,a,100,200\n",
+			NewPoolError(DuplicatePassenger, "passenger: a of ride: 1 is defined more than once"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		passengersFile := filet.TmpFile(t, "", testCase.content)
+
+		passengers, err := LoadPassengers(passengersFile.Name())
+		assert.Error(t, err)
+
+		assert.Nil(t, passengers)
+		assert.Equal(t, testCase.expectedError, err)
+	}
+}
+
+// Tests the PoolService.SplitRide splits the shared RideSegment equally among the passengers on board.
+func TestSplitRideWithEqualSplit(t *testing.T) {
+	poolService := NewPoolService(&perKMFareCalculator{amountPerKM: 1}, pooledPassengers, EqualSplit)
+
+	passengerFares := poolService.SplitRide(pooledRideSegments)
+
+	assert.Equal(
+		t,
+		[]PassengerFare{
+			*NewPassengerFare(1, "a", 6, euros(5), euros(6)),
+			*NewPassengerFare(1, "b", 2, euros(1), euros(6)),
+		},
+		passengerFares,
+	)
+}
+
+// Tests the PoolService.SplitRide splits the shared RideSegment in proportion to the distance of each passenger.
+func TestSplitRideWithDistanceSplit(t *testing.T) {
+	poolService := NewPoolService(&perKMFareCalculator{amountPerKM: 1}, pooledPassengers, DistanceSplit)
+
+	passengerFares := poolService.SplitRide(pooledRideSegments)
+
+	assert.Equal(
+		t,
+		[]PassengerFare{
+			*NewPassengerFare(1, "a", 6, euros(5.5), euros(6)),
+			*NewPassengerFare(1, "b", 2, euros(0.5), euros(6)),
+		},
+		passengerFares,
+	)
+}
+
+// Tests the PoolService.SplitRide splits the RideSegments with no passenger on board among all the passengers,
+// and takes part of a RideSegment for the part of its time that a passenger is on board.
+func TestSplitRideWithPartialSegments(t *testing.T) {
+	poolService := NewPoolService(
+		&perKMFareCalculator{amountPerKM: 1},
+		map[int][]Passenger{1: {*NewPassenger(1, "a", 150, 300), *NewPassenger(1, "b", 150, 250)}},
+		EqualSplit,
+	)
+
+	passengerFares := poolService.SplitRide(pooledRideSegments)
+
+	// The first 150 seconds are split equally, the next 100 seconds shared and the last 50 seconds are of a.
+	assert.Equal(
+		t,
+		[]PassengerFare{
+			*NewPassengerFare(1, "a", 3, euros(3.5), euros(6)),
+			*NewPassengerFare(1, "b", 2, euros(2.5), euros(6)),
+		},
+		passengerFares,
+	)
+}
+
+// Tests the PoolService.SplitRide weighs the RideSegments with their metered amount under a tariff.
+func TestSplitRideWithTariff(t *testing.T) {
+	poolService, err := GetPoolService(
+		fares.TariffStrategy,
+		fares.FareCalculatorConfig{},
+		map[int][]Passenger{1: {*NewPassenger(1, "a", 1405594957, 1405595284), *NewPassenger(1, "b", 1405595284, 1405595562)}},
+		string(EqualSplit),
+	)
+	assert.NoError(t, err)
+
+	ridePositions := []rides.RidePosition{
+		{Id: 1, Lat: 37.966660, Lng: 23.728308, Timestamp: 1405594957},
+		{Id: 1, Lat: 37.954302, Lng: 23.713370, Timestamp: 1405595284},
+		{Id: 1, Lat: 37.938042, Lng: 23.692308, Timestamp: 1405595562},
+	}
+	rideSegments := []rides.RideSegment{
+		*rides.NewRideSegment(1, [2]rides.RidePosition{ridePositions[0], ridePositions[1]}, 20.7, 1.88),
+		*rides.NewRideSegment(1, [2]rides.RidePosition{ridePositions[1], ridePositions[2]}, 33.9, 2.62),
+	}
+
+	passengerFares := poolService.SplitRide(rideSegments)
+	fare := poolService.fareCalculator.Calculate(rideSegments)
+
+	assert.Len(t, passengerFares, 2)
+	assert.Equal(t, fare.Estimation(), passengerFares[0].Fare.Add(passengerFares[1].Fare))
+	// The second passenger travels further, so pays more of the ride.
+	assert.True(t, passengerFares[0].Fare.Cmp(passengerFares[1].Fare) < 0)
+}
+
+// Tests the PoolService.Split returns a PassengerFare per passenger sorted by RideID, and a single one for the
+// rides without passengers.
+func TestSplitSuccessfulExecution(t *testing.T) {
+	rideSegmentsChan := make(chan []rides.RideSegment)
+	poolService := NewPoolService(&perKMFareCalculator{amountPerKM: 1}, pooledPassengers, EqualSplit)
+
+	go func() {
+		rideSegmentsChan <- []rides.RideSegment{{RideID: 2, DistanceCovered: 5}}
+		rideSegmentsChan <- nil
+		rideSegmentsChan <- pooledRideSegments
+		close(rideSegmentsChan)
+	}()
+
+	passengerFares := poolService.Split(rideSegmentsChan)
+
+	assert.Equal(
+		t,
+		[]PassengerFare{
+			*NewPassengerFare(1, "a", 6, euros(5), euros(6)),
+			*NewPassengerFare(1, "b", 2, euros(1), euros(6)),
+			*NewPassengerFare(2, "", 5, euros(5), euros(5)),
+		},
+		passengerFares,
+	)
+}
+
+// Tests the allocate splits the total in whole minor units adding up to the total.
+func TestAllocate(t *testing.T) {
+	assert.Equal(t, []money.Money{euros(0.34), euros(0.33), euros(0.33)}, allocate(euros(1), []float64{1, 1, 1}))
+	assert.Equal(t, []money.Money{euros(0.67), euros(0.33)}, allocate(euros(1), []float64{2, 1}))
+	assert.Equal(t, []money.Money{euros(0.5), euros(0.5)}, allocate(euros(1), []float64{0, 0}))
+}
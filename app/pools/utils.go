@@ -0,0 +1,140 @@
+/*
+Package pools
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package pools
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/money"
+	"github.com/iliaskaras/fare-estimation/app/rides"
+	"math"
+	"sort"
+)
+
+// onBoardShare returns the part of the rideSegment's time that the passenger is on board for, from 0 to 1.
+// A RideSegment of no elapsed time is either wholly on board, or not at all.
+func onBoardShare(rideSegment rides.RideSegment, passenger Passenger) float64 {
+	start := rideSegment.RidePositions[0].Timestamp
+	end := rideSegment.RidePositions[1].Timestamp
+
+	if end <= start {
+		if start >= passenger.Pickup && start < passenger.Dropoff {
+			return 1
+		}
+		return 0
+	}
+
+	overlap := math.Min(float64(end), float64(passenger.Dropoff)) - math.Max(float64(start), float64(passenger.Pickup))
+	if overlap <= 0 {
+		return 0
+	}
+
+	return overlap / float64(end-start)
+}
+
+// stretch is a part of a RideSegment's time between the pickups and the dropoffs of the passengers.
+// - share: The part of the RideSegment's time that the stretch lasts, from 0 to 1.
+// - onBoard: Whether each of the passengers is on board during the stretch.
+type stretch struct {
+	share   float64
+	onBoard []bool
+}
+
+// stretches returns the stretches of the rideSegment, split at the pickups and the dropoffs of the passengers
+// that fall within it. A RideSegment of no elapsed time is a single stretch.
+func stretches(rideSegment rides.RideSegment, passengers []Passenger) []stretch {
+	start := rideSegment.RidePositions[0].Timestamp
+	end := rideSegment.RidePositions[1].Timestamp
+
+	if end <= start {
+		onBoard := make([]bool, len(passengers))
+		for j, passenger := range passengers {
+			onBoard[j] = onBoardShare(rideSegment, passenger) > 0
+		}
+		return []stretch{{share: 1, onBoard: onBoard}}
+	}
+
+	boundaries := []int64{start, end}
+	for _, passenger := range passengers {
+		for _, timestamp := range []int64{passenger.Pickup, passenger.Dropoff} {
+			if timestamp > start && timestamp < end {
+				boundaries = append(boundaries, timestamp)
+			}
+		}
+	}
+	sort.Slice(boundaries, func(i, j int) bool {
+		return boundaries[i] < boundaries[j]
+	})
+
+	var rideStretches []stretch
+	for k := 1; k < len(boundaries); k++ {
+		if boundaries[k] == boundaries[k-1] {
+			continue
+		}
+
+		onBoard := make([]bool, len(passengers))
+		for j, passenger := range passengers {
+			onBoard[j] = passenger.Pickup <= boundaries[k-1] && passenger.Dropoff >= boundaries[k]
+		}
+		rideStretches = append(rideStretches, stretch{
+			share:   float64(boundaries[k]-boundaries[k-1]) / float64(end-start),
+			onBoard: onBoard,
+		})
+	}
+
+	return rideStretches
+}
+
+// allocate splits the total in proportion to the weights, in whole minor units of its currency, with the
+// largest remainder method so that the amounts add up to the total. The weights are split equally when they
+// add up to zero.
+func allocate(total money.Money, weights []float64) []money.Money {
+	totalWeight := sum(weights)
+	if totalWeight <= 0 {
+		weights = make([]float64, len(weights))
+		for i := range weights {
+			weights[i] = 1
+		}
+		totalWeight = float64(len(weights))
+	}
+
+	totalMinorUnits := total.MinorUnits()
+
+	minorUnits := make([]int64, len(weights))
+	remainders := make([]float64, len(weights))
+	var allocated int64
+	for i, weight := range weights {
+		exact := float64(totalMinorUnits) * weight / totalWeight
+		minorUnits[i] = int64(math.Floor(exact))
+		remainders[i] = exact - float64(minorUnits[i])
+		allocated += minorUnits[i]
+	}
+
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return remainders[order[i]] > remainders[order[j]]
+	})
+	for i := 0; allocated < totalMinorUnits; i++ {
+		minorUnits[order[i%len(order)]] += 1
+		allocated += 1
+	}
+
+	amounts := make([]money.Money, len(weights))
+	for i, amount := range minorUnits {
+		amounts[i] = money.FromMinorUnits(amount, total.Currency)
+	}
+
+	return amounts
+}
+
+func sum(values []float64) float64 {
+	var total float64
+	for _, value := range values {
+		total += value
+	}
+
+	return total
+}
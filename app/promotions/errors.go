@@ -0,0 +1,29 @@
+/*
+Package promotions
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package promotions
+
+import (
+	"errors"
+	baseAppErrors "github.com/iliaskaras/fare-estimation/app/infrastructure/errors"
+)
+
+type PromotionError struct {
+	baseAppErrors.BaseAppError
+}
+
+func NewPromotionError(err error, additionalInfo string) PromotionError {
+	return PromotionError{
+		BaseAppError: baseAppErrors.NewBaseAppError(err, additionalInfo),
+	}
+}
+
+var (
+	InvalidPromoRule        = errors.New("invalid promo rule")
+	DuplicatePromoRule      = errors.New("duplicate promo rule")
+	UnsupportedDiscountType = errors.New("unsupported discount type")
+	InvalidPromotion        = errors.New("invalid promotion")
+	DuplicatePromotion      = errors.New("duplicate promotion")
+	UnknownPromoCode        = errors.New("unknown promo code")
+)
@@ -0,0 +1,43 @@
+/*
+Package promotions
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package promotions
+
+import (
+	"sort"
+	"strconv"
+)
+
+// GetPromotionService is responsible for initializing and injecting all the dependencies of the
+// PromotionService, with the PromoRules of the promo rules file found in promoRulesPath and the promo
+// codes of the promotions file found in promotionsPath, every one of which must have a PromoRule.
+func GetPromotionService(promoRulesPath string, promotionsPath string) (*PromotionService, error) {
+	promoRules, err := LoadPromoRules(promoRulesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	promoCodes, err := LoadPromotions(promotionsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// The rides are checked in order, for the same unknown promo code to be reported on every run.
+	rideIDs := make([]int, 0, len(promoCodes))
+	for rideID := range promoCodes {
+		rideIDs = append(rideIDs, rideID)
+	}
+	sort.Ints(rideIDs)
+
+	for _, rideID := range rideIDs {
+		if _, ok := promoRules[promoCodes[rideID]]; !ok {
+			return nil, NewPromotionError(
+				UnknownPromoCode,
+				"ride: "+strconv.Itoa(rideID)+" promo code: "+promoCodes[rideID]+" has no promo rule",
+			)
+		}
+	}
+
+	return NewPromotionService(promoRules, promoCodes), nil
+}
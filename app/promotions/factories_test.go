@@ -0,0 +1,48 @@
+/*
+Package promotions
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package promotions
+
+import (
+	"github.com/Flaque/filet"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Tests the GetPromotionService initializes and returns the PromotionService.
+func TestGetPromotionService(t *testing.T) {
+	defer filet.CleanUp(t)
+	promotionsDir := filet.TmpDir(t, "")
+	promoRulesPath := filepath.Join(promotionsDir, "promo-rules.json")
+	filet.File(t, promoRulesPath, `{"promotions": [{"code": "TWOOFF", "type": "fixed", "amount": 2}]}`)
+	promotionsPath := filepath.Join(promotionsDir, "promotions.csv")
+	filet.File(t, promotionsPath, "1,TWOOFF\n")
+
+	promotionService, err := GetPromotionService(promoRulesPath, promotionsPath)
+	assert.NoError(t, err)
+
+	returnedServiceType := reflect.TypeOf(promotionService).String()
+	expectedServiceType := "*promotions.PromotionService"
+
+	assert.Equal(t, expectedServiceType, returnedServiceType)
+	assert.Equal(t, map[int]string{1: "TWOOFF"}, promotionService.promoCodes)
+}
+
+// Tests the GetPromotionService return a PromotionError when a ride's promo code has no promo rule.
+func TestGetPromotionServiceReturnErrorWhenPromoCodeIsUnknown(t *testing.T) {
+	defer filet.CleanUp(t)
+	promotionsDir := filet.TmpDir(t, "")
+	promoRulesPath := filepath.Join(promotionsDir, "promo-rules.json")
+	filet.File(t, promoRulesPath, `{"promotions": [{"code": "TWOOFF", "type": "fixed", "amount": 2}]}`)
+	promotionsPath := filepath.Join(promotionsDir, "promotions.csv")
+	filet.File(t, promotionsPath, "2,SUMMER20\n1,TWOOFF\n")
+
+	promotionService, err := GetPromotionService(promoRulesPath, promotionsPath)
+	assert.Error(t, err)
+
+	assert.Nil(t, promotionService)
+	assert.Equal(t, NewPromotionError(UnknownPromoCode, "ride: 2 promo code: SUMMER20 has no promo rule"), err)
+}
@@ -0,0 +1,46 @@
+/*
+Package promotions
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package promotions
+
+// DiscountType is the way that the discount of a PromoRule is calculated.
+type DiscountType string
+
+const (
+	// PercentageDiscount takes a percentage off the fare.
+	PercentageDiscount DiscountType = "percentage"
+	// FixedDiscount takes a fixed amount off the fare.
+	FixedDiscount DiscountType = "fixed"
+)
+
+// PromoRule is the rule of a promo code, e.g. 20% off up to 5 EUR, or the first ride free up to 10 EUR
+// as a 100% off up to 10 EUR.
+// - Code: The promo code, unique among the PromoRules.
+// - Type: The DiscountType of the promo code.
+// - Percentage: The percentage taken off the fare, for the PercentageDiscount.
+// - Amount: The amount taken off the fare, for the FixedDiscount.
+// - MaxDiscount: The most that is taken off the fare, zero when the discount is not capped.
+type PromoRule struct {
+	Code        string
+	Type        DiscountType
+	Percentage  float64
+	Amount      float64
+	MaxDiscount float64
+}
+
+func NewPromoRule(
+	code string,
+	discountType DiscountType,
+	percentage float64,
+	amount float64,
+	maxDiscount float64,
+) *PromoRule {
+	return &PromoRule{
+		Code:        code,
+		Type:        discountType,
+		Percentage:  percentage,
+		Amount:      amount,
+		MaxDiscount: maxDiscount,
+	}
+}
@@ -0,0 +1,227 @@
+/*
+Package promotions
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package promotions
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/iliaskaras/fare-estimation/app/fares"
+	"github.com/iliaskaras/fare-estimation/app/infrastructure/configs"
+	"github.com/iliaskaras/fare-estimation/app/money"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// promoRulesDefinition is the promo rules file.
+type promoRulesDefinition struct {
+	Promotions []promoRuleDefinition `json:"promotions" yaml:"promotions"`
+}
+
+type promoRuleDefinition struct {
+	Code        string  `json:"code" yaml:"code"`
+	Type        string  `json:"type" yaml:"type"`
+	Percentage  float64 `json:"percentage" yaml:"percentage"`
+	Amount      float64 `json:"amount" yaml:"amount"`
+	MaxDiscount float64 `json:"max_discount" yaml:"max_discount"`
+}
+
+// LoadPromoRules loads the PromoRules of the promo rules file found in filePath, by their code. Every
+// promo rule requires a unique code and a type, either "percentage" with a percentage above 0 up to 100,
+// or "fixed" with an amount above 0, while its max_discount, if any, must not be negative.
+func LoadPromoRules(filePath string) (map[string]PromoRule, error) {
+	configDecoder, err := configs.GetConfigDecoder(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var definition promoRulesDefinition
+	if err := configDecoder.Decode(filePath, &definition); err != nil {
+		return nil, err
+	}
+
+	promoRules := make(map[string]PromoRule)
+
+	for _, ruleDefinition := range definition.Promotions {
+		if ruleDefinition.Code == "" {
+			return nil, NewPromotionError(InvalidPromoRule, "promo rule has no code")
+		}
+		if _, ok := promoRules[ruleDefinition.Code]; ok {
+			return nil, NewPromotionError(
+				DuplicatePromoRule,
+				"promo rule: "+ruleDefinition.Code+" is defined more than once",
+			)
+		}
+
+		discountType := DiscountType(ruleDefinition.Type)
+		switch discountType {
+		case PercentageDiscount:
+			if ruleDefinition.Percentage <= 0 || ruleDefinition.Percentage > 100 {
+				return nil, NewPromotionError(
+					InvalidPromoRule,
+					"promo rule: "+ruleDefinition.Code+" percentage must be above 0 up to 100",
+				)
+			}
+		case FixedDiscount:
+			if ruleDefinition.Amount <= 0 {
+				return nil, NewPromotionError(
+					InvalidPromoRule,
+					"promo rule: "+ruleDefinition.Code+" amount must be above 0",
+				)
+			}
+		default:
+			return nil, NewPromotionError(
+				UnsupportedDiscountType,
+				fmt.Sprintf(
+					"promo rule: %s type: %q must be one of: %s, %s",
+					ruleDefinition.Code,
+					ruleDefinition.Type,
+					PercentageDiscount,
+					FixedDiscount,
+				),
+			)
+		}
+		if ruleDefinition.MaxDiscount < 0 {
+			return nil, NewPromotionError(
+				InvalidPromoRule,
+				"promo rule: "+ruleDefinition.Code+" max_discount must not be negative",
+			)
+		}
+
+		promoRules[ruleDefinition.Code] = *NewPromoRule(
+			ruleDefinition.Code,
+			discountType,
+			ruleDefinition.Percentage,
+			ruleDefinition.Amount,
+			ruleDefinition.MaxDiscount,
+		)
+	}
+
+	return promoRules, nil
+}
+
+// LoadPromotions parses a .csv file that contains a promotion per row, in the format: ride_id,promo_code
+// and returns the promo code of each RideID. A header row starting with "ride_id" is skipped. Every ride
+// can have a single promo code.
+func LoadPromotions(filePath string) (map[int]string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, NewPromotionError(err, "failure on opening promotions file: "+filePath)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	promoCodes := make(map[int]string)
+	line := 0
+
+	for {
+		fileRecord, err := reader.Read()
+		line += 1
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, NewPromotionError(err, "failure on reading promotion records")
+		}
+
+		rawRideID := strings.TrimSpace(fileRecord[0])
+		if line == 1 && strings.EqualFold(rawRideID, "ride_id") {
+			continue
+		}
+
+		rideID, err := strconv.Atoi(rawRideID)
+		promoCode := strings.TrimSpace(fileRecord[1])
+		if err != nil || promoCode == "" {
+			return nil, NewPromotionError(
+				InvalidPromotion,
+				"line: "+strconv.Itoa(line)+" must hold an integer ride id and a promo code",
+			)
+		}
+		if _, ok := promoCodes[rideID]; ok {
+			return nil, NewPromotionError(
+				DuplicatePromotion,
+				"ride: "+rawRideID+" has more than one promo code",
+			)
+		}
+
+		promoCodes[rideID] = promoCode
+	}
+
+	return promoCodes, nil
+}
+
+type PromotionService struct {
+	promoRules map[string]PromoRule
+	promoCodes map[int]string
+}
+
+func NewPromotionService(promoRules map[string]PromoRule, promoCodes map[int]string) *PromotionService {
+	return &PromotionService{
+		promoRules: promoRules,
+		promoCodes: promoCodes,
+	}
+}
+
+// Discount returns the FareDiscount of the promo code of the Fare's RideID, nil when it has none. The
+// discount is rounded with the Fare's Rounding, so that the amount payable is rounded as a fare is, and
+// capped to the MaxDiscount of the PromoRule, and it never takes the Fare below its MinimumFare, the
+// tolls being passed through.
+func (ps *PromotionService) Discount(fare fares.Fare) *fares.FareDiscount {
+	promoCode, ok := ps.promoCodes[fare.RideID]
+	if !ok {
+		return nil
+	}
+	promoRule := ps.promoRules[promoCode]
+
+	estimation := fare.Estimation()
+	currency := estimation.Currency
+
+	var amount money.Money
+	if promoRule.Type == PercentageDiscount {
+		amount = estimation.Mul(promoRule.Percentage / 100).Round(fare.Rounding)
+	} else {
+		amount = money.FromFloat(promoRule.Amount, currency).Round(fare.Rounding)
+	}
+
+	if promoRule.MaxDiscount > 0 {
+		maxDiscount := money.FromFloat(promoRule.MaxDiscount, currency).Round(fare.Rounding)
+		if amount.Cmp(maxDiscount) > 0 {
+			amount = maxDiscount
+		}
+	}
+
+	floor := money.FromFloat(0, currency).Add(fare.MinimumFare).Add(fare.Breakdown.TollsAmount).Round(fare.Rounding)
+	headroom := estimation.Sub(floor)
+	if headroom.Cmp(money.FromFloat(0, currency)) < 0 {
+		headroom = money.FromFloat(0, currency)
+	}
+	if amount.Cmp(headroom) > 0 {
+		amount = headroom
+	}
+
+	return &fares.FareDiscount{
+		PromoCode: promoCode,
+		Amount:    amount,
+	}
+}
+
+// Apply sets the FareDiscount of each of the estimated Fares, and passes the Fares on. It closes the
+// discountedFaresChan once the faresChan is closed.
+// - Receiver of the channel faresChan.
+// - Sender of the channel discountedFaresChan.
+func (ps *PromotionService) Apply(faresChan <-chan fares.Fare, discountedFaresChan chan<- fares.Fare) {
+	for fare := range faresChan {
+		fare.Discount = ps.Discount(fare)
+
+		discountedFaresChan <- fare
+	}
+
+	close(discountedFaresChan)
+}
@@ -0,0 +1,237 @@
+/*
+Package promotions
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package promotions
+
+import (
+	"github.com/Flaque/filet"
+	"github.com/iliaskaras/fare-estimation/app/fares"
+	"github.com/iliaskaras/fare-estimation/app/money"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func euros(amount float64) money.Money {
+	return money.FromFloat(amount, money.EUR)
+}
+
+// fare returns the Fare of the rideID with the estimation and the built-in tariff's minimum fare.
+func fare(rideID int, estimation float64) fares.Fare {
+	fare := fares.NewFare(rideID, euros(estimation))
+	fare.MinimumFare = euros(3.47)
+
+	return *fare
+}
+
+// testPromoRules are the PromoRules of the promotions that the tests apply.
+var testPromoRules = map[string]PromoRule{
+	"SUMMER20":  *NewPromoRule("SUMMER20", PercentageDiscount, 20, 0, 5),
+	"FIRSTRIDE": *NewPromoRule("FIRSTRIDE", PercentageDiscount, 100, 0, 10),
+	"TWOOFF":    *NewPromoRule("TWOOFF", FixedDiscount, 0, 2, 0),
+}
+
+// Tests the LoadPromoRules returns the PromoRules of the promo rules file by their code.
+func TestLoadPromoRulesSuccessfulExecution(t *testing.T) {
+	defer filet.CleanUp(t)
+	promoRulesDir := filet.TmpDir(t, "")
+	promoRulesPath := filepath.Join(promoRulesDir, "promo-rules.yaml")
+	filet.File(
+		t,
+		promoRulesPath,
+		"promotions:\n"+
+			"  - code: SUMMER20\n    type: percentage\n    percentage: 20\n    max_discount: 5\n"+
+			"  - code: TWOOFF\n    type: fixed\n    amount: 2\n",
+	)
+
+	promoRules, err := LoadPromoRules(promoRulesPath)
+	assert.NoError(t, err)
+
+	assert.Equal(
+		t,
+		map[string]PromoRule{
+			"SUMMER20": *NewPromoRule("SUMMER20", PercentageDiscount, 20, 0, 5),
+			"TWOOFF":   *NewPromoRule("TWOOFF", FixedDiscount, 0, 2, 0),
+		},
+		promoRules,
+	)
+}
+
+// Tests the LoadPromoRules return a PromotionError when a promo rule is invalid or defined more than once.
+func TestLoadPromoRulesReturnErrorWhenPromoRuleIsInvalid(t *testing.T) {
+	defer filet.CleanUp(t)
+	promoRulesDir := filet.TmpDir(t, "")
+	promoRulesPath := filepath.Join(promoRulesDir, "promo-rules.yaml")
+
+	testCases := []struct {
+		content       string
+		expectedError error
+	}{
+		{
+			"promotions:\n  - type: fixed\n    amount: 2\n",
+			NewPromotionError(InvalidPromoRule, "promo rule has no code"),
+		},
+		{
+			"promotions:\n  - code: A\n    type: fixed\n    amount: 2\n  - code: A\n    type: fixed\n    amount: 3\n",
+			NewPromotionError(DuplicatePromoRule, "promo rule: A is defined more than once"),
+		},
+		{
+			"promotions:\n  - code: A\n    type: percentage\n    percentage: 120\n",
+			NewPromotionError(InvalidPromoRule, "promo rule: A percentage must be above 0 up to 100"),
+		},
+		{
+			"promotions:\n  - code: A\n    type: fixed\n",
+			NewPromotionError(InvalidPromoRule, "promo rule: A amount must be above 0"),
+		},
+		{
+			"promotions:\n  - code: A\n    type: free\n",
+			NewPromotionError(UnsupportedDiscountType, `promo rule: A type: "free" must be one of: percentage, fixed`),
+		},
+		{
+			"promotions:\n  - code: A\n    type: fixed\n    amount: 2\n    max_discount: -1\n",
+			NewPromotionError(InvalidPromoRule, "promo rule: A max_discount must not be negative"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		if err := os.WriteFile(promoRulesPath, []byte(testCase.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		promoRules, err := LoadPromoRules(promoRulesPath)
+		assert.Error(t, err)
+
+		assert.Nil(t, promoRules)
+		assert.Equal(t, testCase.expectedError, err)
+	}
+}
+
+// Tests the LoadPromotions returns the promo code of each RideID.
+func TestLoadPromotionsSuccessfulExecution(t *testing.T) {
+	defer filet.CleanUp(t)
+	promotionsFile := filet.TmpFile(t, "", "ride_id,promo_code\n1,SUMMER20\n3, TWOOFF\n")
+
+	promoCodes, err := LoadPromotions(promotionsFile.Name())
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[int]string{1: "SUMMER20", 3: "TWOOFF"}, promoCodes)
+}
+
+// Tests the LoadPromotions return a PromotionError when a promotion is invalid or a ride has more than one.
+func TestLoadPromotionsReturnErrorWhenPromotionIsInvalid(t *testing.T) {
+	defer filet.CleanUp(t)
+
+	testCases := []struct {
+		content       string
+		expectedError error
+	}{
+		{"one,SUMMER20\n", NewPromotionError(InvalidPromotion, "line: 1 must hold an integer ride id and a promo code")},
+		{"1,\n", NewPromotionError(InvalidPromotion, "line: 1 must hold an integer ride id and a promo code")},
+		{"1,SUMMER20\n1,TWOOFF\n", NewPromotionError(DuplicatePromotion, "ride: 1 has more than one promo code")},
+	}
+
+	for _, testCase := range testCases {
+		promotionsFile := filet.TmpFile(t, "", testCase.content)
+
+		promoCodes, err := LoadPromotions(promotionsFile.Name())
+		assert.Error(t, err)
+
+		assert.Nil(t, promoCodes)
+		assert.Equal(t, testCase.expectedError, err)
+	}
+}
+
+// Tests the PromotionService.Discount applies the percentage, the fixed and the capped discounts.
+func TestDiscountSuccessfulExecution(t *testing.T) {
+	promotionService := NewPromotionService(testPromoRules, map[int]string{1: "SUMMER20", 2: "FIRSTRIDE", 3: "TWOOFF"})
+
+	testCases := []struct {
+		fare             fares.Fare
+		expectedDiscount *fares.FareDiscount
+	}{
+		// 20% of 11.34, below the cap.
+		{fare(1, 11.34), &fares.FareDiscount{PromoCode: "SUMMER20", Amount: euros(2.27)}},
+		// 20% of 30.00, capped to 5.00.
+		{fare(1, 30), &fares.FareDiscount{PromoCode: "SUMMER20", Amount: euros(5)}},
+		// The first ride is free up to 10.00.
+		{fare(2, 13.1), &fares.FareDiscount{PromoCode: "FIRSTRIDE", Amount: euros(9.63)}},
+		{fare(3, 13.1), &fares.FareDiscount{PromoCode: "TWOOFF", Amount: euros(2)}},
+		{fare(4, 13.1), nil},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expectedDiscount, promotionService.Discount(testCase.fare))
+	}
+}
+
+// Tests the PromotionService.Discount never takes the fare below its minimum fare, along with its tolls.
+func TestDiscountRespectsMinimumFare(t *testing.T) {
+	promotionService := NewPromotionService(testPromoRules, map[int]string{1: "FIRSTRIDE", 2: "TWOOFF"})
+
+	freeRide := fare(1, 5)
+	assert.Equal(t, euros(1.53), promotionService.Discount(freeRide).Amount)
+
+	minimumFareRide := fare(2, 3.47)
+	assert.Equal(t, euros(0), promotionService.Discount(minimumFareRide).Amount)
+
+	tolledRide := fare(1, 9.07)
+	tolledRide.Breakdown.TollsAmount = euros(2.8)
+	assert.Equal(t, euros(2.8), promotionService.Discount(tolledRide).Amount)
+	tolledRide.Discount = promotionService.Discount(tolledRide)
+	assert.Equal(t, euros(6.27), tolledRide.Payable())
+}
+
+// Tests the PromotionService.Discount rounds the discount with the Fare's Rounding, so that the amount payable
+// stays a multiple of 0.05 with the UpToNickel, the minimum fare being rounded up to it too.
+func TestDiscountRoundsWithFareRounding(t *testing.T) {
+	promotionService := NewPromotionService(
+		map[string]PromoRule{
+			"ONEOFF": *NewPromoRule("ONEOFF", PercentageDiscount, 1, 0, 0),
+			"TWOOFF": *NewPromoRule("TWOOFF", FixedDiscount, 0, 2, 0),
+		},
+		map[int]string{1: "ONEOFF", 2: "TWOOFF"},
+	)
+
+	nickelRide := fare(1, 3.45)
+	nickelRide.Rounding = money.UpToNickel
+	nickelRide.MinimumFare = euros(3)
+	nickelRide.Discount = promotionService.Discount(nickelRide)
+	assert.Equal(t, euros(0.05), nickelRide.Discount.Amount)
+	assert.Equal(t, euros(3.40), nickelRide.Payable())
+
+	halfUpRide := fare(1, 3.45)
+	halfUpRide.MinimumFare = euros(3)
+	assert.Equal(t, euros(0.03), promotionService.Discount(halfUpRide).Amount)
+
+	minimumFareRide := fare(2, 4.50)
+	minimumFareRide.Rounding = money.UpToNickel
+	minimumFareRide.Discount = promotionService.Discount(minimumFareRide)
+	assert.Equal(t, euros(1), minimumFareRide.Discount.Amount)
+	assert.Equal(t, euros(3.50), minimumFareRide.Payable())
+}
+
+// Tests the PromotionService.Apply sets the FareDiscount of each Fare and closes the discounted fares channel.
+func TestApplySuccessfulExecution(t *testing.T) {
+	promotionService := NewPromotionService(testPromoRules, map[int]string{1: "SUMMER20"})
+	faresChan := make(chan fares.Fare)
+	discountedFaresChan := make(chan fares.Fare)
+
+	go func() {
+		faresChan <- fare(1, 11.34)
+		faresChan <- fare(2, 13.1)
+		close(faresChan)
+	}()
+	go promotionService.Apply(faresChan, discountedFaresChan)
+
+	var discountedFares []fares.Fare
+	for discountedFare := range discountedFaresChan {
+		discountedFares = append(discountedFares, discountedFare)
+	}
+
+	assert.Len(t, discountedFares, 2)
+	assert.Equal(t, euros(9.07), discountedFares[0].Payable())
+	assert.Nil(t, discountedFares[1].Discount)
+	assert.Equal(t, euros(13.1), discountedFares[1].Payable())
+}
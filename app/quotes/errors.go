@@ -0,0 +1,24 @@
+/*
+Package quotes
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package quotes
+
+import (
+	"errors"
+	baseAppErrors "github.com/iliaskaras/fare-estimation/app/infrastructure/errors"
+)
+
+type QuoteError struct {
+	baseAppErrors.BaseAppError
+}
+
+func NewQuoteError(err error, additionalInfo string) QuoteError {
+	return QuoteError{
+		BaseAppError: baseAppErrors.NewBaseAppError(err, additionalInfo),
+	}
+}
+
+var (
+	MissingTravelHistory = errors.New("missing travel history")
+)
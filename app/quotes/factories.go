@@ -0,0 +1,31 @@
+/*
+Package quotes
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package quotes
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/distances"
+	"github.com/iliaskaras/fare-estimation/app/fares"
+	"github.com/iliaskaras/fare-estimation/app/tariffs"
+)
+
+// GetQuoteService is responsible for initializing and injecting all the dependencies of the QuoteService,
+// quoting the rides with the FareCalculator of the fare strategy, as the FareService would price them.
+func GetQuoteService(fareStrategy string, config fares.FareCalculatorConfig) (*QuoteService, error) {
+	if config.Tariff == nil {
+		config.Tariff = tariffs.DefaultTariff()
+	}
+
+	fareCalculator, err := fares.GetFareCalculator(fareStrategy, config)
+	if err != nil {
+		return nil, err
+	}
+
+	distanceCalculator, err := distances.GetDistanceCalculatorService(distances.HaversineMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewQuoteService(fareCalculator, distanceCalculator, config.Tariff), nil
+}
@@ -0,0 +1,34 @@
+/*
+Package quotes
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package quotes
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/fares"
+	"github.com/iliaskaras/fare-estimation/app/tariffs"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+// Tests the GetQuoteService initializes and returns the QuoteService, with the built-in Tariff when none is provided.
+func TestGetQuoteService(t *testing.T) {
+	quoteService, err := GetQuoteService(fares.TariffStrategy, fares.FareCalculatorConfig{})
+	assert.NoError(t, err)
+
+	returnedServiceType := reflect.TypeOf(quoteService).String()
+	expectedServiceType := "*quotes.QuoteService"
+
+	assert.Equal(t, expectedServiceType, returnedServiceType)
+	assert.Equal(t, tariffs.DefaultTariff(), quoteService.tariff)
+}
+
+// Tests the GetQuoteService return an error when the fare strategy is not registered.
+func TestGetQuoteServiceReturnErrorWhenFareStrategyIsInvalid(t *testing.T) {
+	quoteService, err := GetQuoteService("random", fares.FareCalculatorConfig{})
+	assert.Error(t, err)
+
+	assert.Nil(t, quoteService)
+	assert.IsType(t, fares.FareStrategyError{}, err)
+}
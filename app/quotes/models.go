@@ -0,0 +1,94 @@
+/*
+Package quotes
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package quotes
+
+import (
+	"fmt"
+	"github.com/iliaskaras/fare-estimation/app/fares"
+	"github.com/iliaskaras/fare-estimation/app/zones"
+	"time"
+)
+
+// The percentiles of the historical TripProfiles that the fare range of a Quote is priced with.
+// - LowPercentile: The Low quote travels the shorter distance, faster and less idle.
+// - HighPercentile: The High quote travels the longer distance, slower and more idle.
+const (
+	LowPercentile      float64 = 25
+	ExpectedPercentile float64 = 50
+	HighPercentile     float64 = 75
+)
+
+// The thresholds of the historical rides that a TravelModel learns from.
+// - MinimumTripKM: The straight line distance that a ride must cover, below it the distance ratio is meaningless.
+// - MinimumHourTrips: The TripProfiles that a departure hour needs for its own to be used, instead of all of them.
+const (
+	MinimumTripKM    float64 = 0.5
+	MinimumHourTrips int     = 5
+)
+
+// TripProfile is how a historical ride travelled, learned out of its filtered RideSegments.
+// - DepartureHour: The hour of the day of the ride's first position, in the TravelModel's Location.
+// - DistanceRatio: The distance covered over the straight line distance between the first and the last position.
+// - MovingSpeedKMH: The mean speed of the ride while moving.
+// - IdleShare: The share of the ride's time spent idle.
+type TripProfile struct {
+	DepartureHour  int
+	DistanceRatio  float64
+	MovingSpeedKMH float64
+	IdleShare      float64
+}
+
+func NewTripProfile(departureHour int, distanceRatio, movingSpeedKMH, idleShare float64) *TripProfile {
+	return &TripProfile{
+		DepartureHour:  departureHour,
+		DistanceRatio:  distanceRatio,
+		MovingSpeedKMH: movingSpeedKMH,
+		IdleShare:      idleShare,
+	}
+}
+
+// TravelModel holds the TripProfiles learned from the processed rides.
+// - Location: The timezone that the departure hours are decided in.
+// - Profiles: The TripProfile of every ride learned from.
+type TravelModel struct {
+	Location *time.Location
+	Profiles []TripProfile
+}
+
+func NewTravelModel(location *time.Location, profiles []TripProfile) *TravelModel {
+	return &TravelModel{
+		Location: location,
+		Profiles: profiles,
+	}
+}
+
+// Quote is the fare range of a ride before it starts, priced with the tariff logic of the fares.
+// - Origin, Destination, Departure: The ride that is quoted.
+// - DistanceKM, DurationSecs: The expected distance and duration of the ride.
+// - Low, Expected, High: The Fare of the ride at the LowPercentile, ExpectedPercentile and HighPercentile.
+type Quote struct {
+	Origin       zones.Point
+	Destination  zones.Point
+	Departure    time.Time
+	DistanceKM   float64
+	DurationSecs float64
+	Low          fares.Fare
+	Expected     fares.Fare
+	High         fares.Fare
+}
+
+// Report returns the Quote as human readable lines.
+func (q Quote) Report() []string {
+	return []string{
+		fmt.Sprintf(
+			"Quote: %s - %s, expected %s",
+			q.Low.Estimation(), q.High.Estimation(), q.Expected.Estimation(),
+		),
+		fmt.Sprintf(
+			"Expected ride: %.3f km in %.0f min, departing at %s",
+			q.DistanceKM, q.DurationSecs/60, q.Departure.Format(time.RFC3339),
+		),
+	}
+}
@@ -0,0 +1,37 @@
+/*
+Package quotes
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package quotes
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/fares"
+	"github.com/iliaskaras/fare-estimation/app/money"
+	"github.com/iliaskaras/fare-estimation/app/zones"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// Tests the Quote Report returns the fare range and the expected ride as lines.
+func TestQuoteReport(t *testing.T) {
+	quote := Quote{
+		Origin:       *zones.NewPoint(37.9838, 23.7275),
+		Destination:  *zones.NewPoint(37.9420, 23.6470),
+		Departure:    time.Date(2014, 7, 17, 10, 0, 0, 0, time.UTC),
+		DistanceKM:   12.5,
+		DurationSecs: 1800,
+		Low:          *fares.NewFare(0, money.FromFloat(11.2, money.EUR)),
+		Expected:     *fares.NewFare(0, money.FromFloat(14.67, money.EUR)),
+		High:         *fares.NewFare(0, money.FromFloat(19.6, money.EUR)),
+	}
+
+	assert.Equal(
+		t,
+		[]string{
+			"Quote: 11.20 - 19.60, expected 14.67",
+			"Expected ride: 12.500 km in 30 min, departing at 2014-07-17T10:00:00Z",
+		},
+		quote.Report(),
+	)
+}
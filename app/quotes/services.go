@@ -0,0 +1,217 @@
+/*
+Package quotes
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package quotes
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/distances"
+	"github.com/iliaskaras/fare-estimation/app/fares"
+	"github.com/iliaskaras/fare-estimation/app/rides"
+	"github.com/iliaskaras/fare-estimation/app/stats"
+	"github.com/iliaskaras/fare-estimation/app/tariffs"
+	"github.com/iliaskaras/fare-estimation/app/zones"
+	"math"
+	"sort"
+	"time"
+)
+
+type QuoteService struct {
+	fareCalculator     fares.FareCalculator
+	distanceCalculator distances.DistanceCalculatorService
+	tariff             *tariffs.Tariff
+}
+
+func NewQuoteService(
+	fareCalculator fares.FareCalculator,
+	distanceCalculator distances.DistanceCalculatorService,
+	tariff *tariffs.Tariff,
+) *QuoteService {
+	return &QuoteService{
+		fareCalculator:     fareCalculator,
+		distanceCalculator: distanceCalculator,
+		tariff:             tariff,
+	}
+}
+
+// Learn learns the TravelModel out of the filtered RideSegments of the processed rides, with a TripProfile
+// for each ride that covered at least the MinimumTripKM in a straight line and moved at all. The RideSegments
+// are considered moving above the idle speed of the QuoteService's Tariff, and the departure hours are
+// decided in its timezone.
+// - Receiver of the channel rideSegmentsChan.
+func (qs *QuoteService) Learn(rideSegmentsChan <-chan []rides.RideSegment) *TravelModel {
+	var profiles []TripProfile
+
+	for rideSegments := range rideSegmentsChan {
+		// Case where the RideID had only one RidePosition in the input file.
+		if rideSegments == nil {
+			continue
+		}
+
+		first := rideSegments[0].RidePositions[0]
+		last := rideSegments[len(rideSegments)-1].RidePositions[1]
+		straightKM := qs.distanceCalculator.GetDistance(first.Lat, first.Lng, last.Lat, last.Lng)
+		if straightKM < MinimumTripKM {
+			continue
+		}
+
+		var distanceKM, movingKM, movingSecs, idleSecs float64
+		for _, rideSegment := range rideSegments {
+			elapsedTimeSecs := float64(
+				rideSegment.RidePositions[1].Timestamp - rideSegment.RidePositions[0].Timestamp,
+			)
+			distanceKM += rideSegment.DistanceCovered
+
+			if rideSegment.Speed > qs.tariff.IdleSpeedKMH {
+				movingKM += rideSegment.DistanceCovered
+				movingSecs += elapsedTimeSecs
+			} else {
+				idleSecs += elapsedTimeSecs
+			}
+		}
+		if movingSecs <= 0 {
+			continue
+		}
+
+		profiles = append(
+			profiles,
+			*NewTripProfile(
+				time.Unix(first.Timestamp, 0).In(qs.tariff.Location).Hour(),
+				distanceKM/straightKM,
+				movingKM/(movingSecs/rides.HourInSeconds),
+				idleSecs/(movingSecs+idleSecs),
+			),
+		)
+	}
+
+	return NewTravelModel(qs.tariff.Location, profiles)
+}
+
+// Quote estimates the fare range of a ride from the origin to the destination departing at the departure
+// time, out of the TripProfiles of the travelModel departing at the same hour of the day, or out of all of
+// them when there are fewer than MinimumHourTrips. The ride's distance is its straight line distance times
+// the distance ratio, covered at the moving speed, followed by the idle time of the idle share, and each of
+// the Low, Expected and High Fare is priced with the QuoteService's FareCalculator, as a completed ride would.
+func (qs *QuoteService) Quote(
+	travelModel *TravelModel,
+	origin zones.Point,
+	destination zones.Point,
+	departure time.Time,
+) (*Quote, error) {
+	profiles := profilesAt(travelModel, departure)
+	if len(profiles) == 0 {
+		return nil, NewQuoteError(MissingTravelHistory, "no rides were learned to quote from")
+	}
+
+	straightKM := qs.distanceCalculator.GetDistance(origin.Lat, origin.Lng, destination.Lat, destination.Lng)
+
+	distanceRatios := make([]float64, len(profiles))
+	movingSpeeds := make([]float64, len(profiles))
+	idleShares := make([]float64, len(profiles))
+	for i, profile := range profiles {
+		distanceRatios[i] = profile.DistanceRatio
+		movingSpeeds[i] = profile.MovingSpeedKMH
+		idleShares[i] = profile.IdleShare
+	}
+	sort.Float64s(distanceRatios)
+	sort.Float64s(movingSpeeds)
+	sort.Float64s(idleShares)
+
+	// The faster the ride, the lower its fare, so the speeds are taken from the opposite percentile.
+	tripAt := func(percentile float64) TripProfile {
+		return *NewTripProfile(
+			departure.In(travelModel.Location).Hour(),
+			distanceRatios[stats.NearestRank(percentile, len(profiles))],
+			movingSpeeds[stats.NearestRank(100-percentile, len(profiles))],
+			idleShares[stats.NearestRank(percentile, len(profiles))],
+		)
+	}
+
+	expectedSegments := tripSegments(origin, destination, departure, straightKM, tripAt(ExpectedPercentile))
+	expectedEnd := expectedSegments[len(expectedSegments)-1].RidePositions[1].Timestamp
+
+	return &Quote{
+		Origin:       origin,
+		Destination:  destination,
+		Departure:    departure,
+		DistanceKM:   straightKM * tripAt(ExpectedPercentile).DistanceRatio,
+		DurationSecs: float64(expectedEnd - departure.Unix()),
+		Low: qs.fareCalculator.Calculate(
+			tripSegments(origin, destination, departure, straightKM, tripAt(LowPercentile)),
+		),
+		Expected: qs.fareCalculator.Calculate(expectedSegments),
+		High: qs.fareCalculator.Calculate(
+			tripSegments(origin, destination, departure, straightKM, tripAt(HighPercentile)),
+		),
+	}, nil
+}
+
+// profilesAt returns the TripProfiles of the travelModel departing at the hour of the day of the departure,
+// or all of them when there are fewer than MinimumHourTrips.
+func profilesAt(travelModel *TravelModel, departure time.Time) []TripProfile {
+	if travelModel == nil {
+		return nil
+	}
+
+	departureHour := departure.In(travelModel.Location).Hour()
+
+	var hourProfiles []TripProfile
+	for _, profile := range travelModel.Profiles {
+		if profile.DepartureHour == departureHour {
+			hourProfiles = append(hourProfiles, profile)
+		}
+	}
+	if len(hourProfiles) < MinimumHourTrips {
+		return travelModel.Profiles
+	}
+
+	return hourProfiles
+}
+
+// tripSegments returns the RideSegments of a ride from the origin to the destination travelling as the
+// TripProfile: a moving RideSegment covering the straightKM times the distance ratio at the moving speed,
+// followed by an idle RideSegment at the destination for the idle share of the ride's time.
+func tripSegments(
+	origin zones.Point,
+	destination zones.Point,
+	departure time.Time,
+	straightKM float64,
+	profile TripProfile,
+) []rides.RideSegment {
+	distanceKM := straightKM * profile.DistanceRatio
+	movingSecs := distanceKM / profile.MovingSpeedKMH * rides.HourInSeconds
+	idleSecs := movingSecs * profile.IdleShare / (1 - profile.IdleShare)
+
+	start := departure.Unix()
+	arrival := start + int64(math.Round(movingSecs))
+	end := arrival + int64(math.Round(idleSecs))
+
+	rideSegments := []rides.RideSegment{
+		*rides.NewRideSegment(
+			0,
+			[2]rides.RidePosition{
+				*rides.NewRidePosition(0, origin.Lat, origin.Lng, start),
+				*rides.NewRidePosition(0, destination.Lat, destination.Lng, arrival),
+			},
+			profile.MovingSpeedKMH,
+			distanceKM,
+		),
+	}
+
+	if end > arrival {
+		rideSegments = append(
+			rideSegments,
+			*rides.NewRideSegment(
+				0,
+				[2]rides.RidePosition{
+					*rides.NewRidePosition(0, destination.Lat, destination.Lng, arrival),
+					*rides.NewRidePosition(0, destination.Lat, destination.Lng, end),
+				},
+				0,
+				0,
+			),
+		)
+	}
+
+	return rideSegments
+}
@@ -0,0 +1,148 @@
+/*
+Package quotes
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package quotes
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/distances"
+	"github.com/iliaskaras/fare-estimation/app/fares"
+	"github.com/iliaskaras/fare-estimation/app/rides"
+	"github.com/iliaskaras/fare-estimation/app/tariffs"
+	"github.com/iliaskaras/fare-estimation/app/zones"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+// mustGetQuoteService returns the QuoteService of the built-in tariff.
+func mustGetQuoteService(t *testing.T) *QuoteService {
+	quoteService, err := GetQuoteService(fares.TariffStrategy, fares.FareCalculatorConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return quoteService
+}
+
+// Tests the QuoteService.Learn learns a TripProfile out of each ride that moved over the MinimumTripKM.
+func TestLearnSuccessfulExecution(t *testing.T) {
+	rideSegmentsChan := make(chan []rides.RideSegment)
+	quoteService := mustGetQuoteService(t)
+	distanceCalculator, _ := distances.GetDistanceCalculatorService(distances.HaversineMethod)
+
+	origin := rides.RidePosition{Id: 1, Lat: 37.966660, Lng: 23.728308, Timestamp: 1405594957}
+	destination := rides.RidePosition{Id: 1, Lat: 37.954302, Lng: 23.713370, Timestamp: 1405595197}
+	waiting := rides.RidePosition{Id: 1, Lat: 37.954302, Lng: 23.713370, Timestamp: 1405595257}
+	nearby := rides.RidePosition{Id: 2, Lat: 37.966627, Lng: 23.728263, Timestamp: 1405595197}
+
+	go func() {
+		rideSegmentsChan <- []rides.RideSegment{
+			{RideID: 1, RidePositions: [2]rides.RidePosition{origin, destination}, Speed: 30, DistanceCovered: 2},
+			{RideID: 1, RidePositions: [2]rides.RidePosition{destination, waiting}, Speed: 0, DistanceCovered: 0},
+		}
+		rideSegmentsChan <- nil
+		// Too short a ride to learn its distance ratio from.
+		rideSegmentsChan <- []rides.RideSegment{
+			{RideID: 2, RidePositions: [2]rides.RidePosition{origin, nearby}, Speed: 30, DistanceCovered: 2},
+		}
+		// A ride that never moved.
+		rideSegmentsChan <- []rides.RideSegment{
+			{RideID: 3, RidePositions: [2]rides.RidePosition{origin, destination}, Speed: 5, DistanceCovered: 0.3},
+		}
+		close(rideSegmentsChan)
+	}()
+
+	travelModel := quoteService.Learn(rideSegmentsChan)
+
+	straightKM := distanceCalculator.GetDistance(origin.Lat, origin.Lng, destination.Lat, destination.Lng)
+	assert.Equal(t, time.UTC, travelModel.Location)
+	assert.Len(t, travelModel.Profiles, 1)
+	assert.Equal(t, 11, travelModel.Profiles[0].DepartureHour)
+	assert.InDelta(t, 2/straightKM, travelModel.Profiles[0].DistanceRatio, 1e-9)
+	assert.InDelta(t, 30, travelModel.Profiles[0].MovingSpeedKMH, 1e-9)
+	assert.InDelta(t, 0.2, travelModel.Profiles[0].IdleShare, 1e-9)
+}
+
+// Tests the QuoteService.Quote prices the Low, Expected and High fares with the percentiles of the TripProfiles.
+func TestQuoteSuccessfulExecution(t *testing.T) {
+	quoteService := mustGetQuoteService(t)
+	distanceCalculator, _ := distances.GetDistanceCalculatorService(distances.HaversineMethod)
+	travelModel := NewTravelModel(
+		time.UTC,
+		[]TripProfile{
+			*NewTripProfile(8, 1.6, 20, 0.3),
+			*NewTripProfile(8, 1.2, 50, 0.4),
+			*NewTripProfile(8, 1.8, 30, 0.1),
+			*NewTripProfile(8, 1.4, 40, 0.2),
+		},
+	)
+	origin := *zones.NewPoint(37.9838, 23.7275)
+	destination := *zones.NewPoint(37.9420, 23.6470)
+	departure := time.Date(2014, 7, 17, 10, 0, 0, 0, time.UTC)
+
+	quote, err := quoteService.Quote(travelModel, origin, destination, departure)
+	assert.NoError(t, err)
+
+	// The built-in tariff's fare of a ride travelling as the TripProfile, at day time.
+	straightKM := distanceCalculator.GetDistance(origin.Lat, origin.Lng, destination.Lat, destination.Lng)
+	expectedFare := func(distanceRatio, movingSpeedKMH, idleShare float64) float64 {
+		distanceKM := straightKM * distanceRatio
+		idleSecs := math.Round(distanceKM / movingSpeedKMH * rides.HourInSeconds * idleShare / (1 - idleShare))
+
+		return tariffs.StandardFare + distanceKM*tariffs.MovingDay + idleSecs/rides.HourInSeconds*tariffs.Idle
+	}
+
+	assert.Equal(t, origin, quote.Origin)
+	assert.Equal(t, destination, quote.Destination)
+	assert.Equal(t, departure, quote.Departure)
+	assert.InDelta(t, straightKM*1.4, quote.DistanceKM, 1e-9)
+	assert.InDelta(t, straightKM*1.4/30*rides.HourInSeconds/(1-0.2), quote.DurationSecs, 1)
+	assert.InDelta(t, expectedFare(1.2, 40, 0.1), quote.Low.Estimation().Float64(), 0.005)
+	assert.InDelta(t, expectedFare(1.4, 30, 0.2), quote.Expected.Estimation().Float64(), 0.005)
+	assert.InDelta(t, expectedFare(1.6, 20, 0.3), quote.High.Estimation().Float64(), 0.005)
+	assert.Equal(t, []string{fares.DefaultZoneName}, quote.Expected.Zones)
+}
+
+// Tests the QuoteService.Quote is charged the minimum fare when the origin is the destination.
+func TestQuoteWhenOriginIsTheDestination(t *testing.T) {
+	quoteService := mustGetQuoteService(t)
+	travelModel := NewTravelModel(time.UTC, []TripProfile{*NewTripProfile(8, 1.4, 30, 0)})
+	point := *zones.NewPoint(37.9838, 23.7275)
+
+	quote, err := quoteService.Quote(travelModel, point, point, time.Date(2014, 7, 17, 10, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+
+	assert.Equal(t, 0.0, quote.DistanceKM)
+	assert.InDelta(t, tariffs.MinimumFare, quote.Low.Estimation().Float64(), 1e-9)
+	assert.InDelta(t, tariffs.MinimumFare, quote.High.Estimation().Float64(), 1e-9)
+}
+
+// Tests the QuoteService.Quote return a QuoteError when no ride was learned.
+func TestQuoteReturnErrorWhenThereIsNoTravelHistory(t *testing.T) {
+	quoteService := mustGetQuoteService(t)
+	point := *zones.NewPoint(37.9838, 23.7275)
+
+	for _, travelModel := range []*TravelModel{nil, NewTravelModel(time.UTC, nil)} {
+		quote, err := quoteService.Quote(travelModel, point, point, time.Now())
+		assert.Error(t, err)
+
+		assert.Nil(t, quote)
+		assert.Equal(t, NewQuoteError(MissingTravelHistory, "no rides were learned to quote from"), err)
+	}
+}
+
+// Tests the profilesAt returns the TripProfiles of the departure hour, or all of them when they are too few.
+func TestProfilesAt(t *testing.T) {
+	var profiles []TripProfile
+	for i := 0; i < MinimumHourTrips; i++ {
+		profiles = append(profiles, *NewTripProfile(8, 1.4, 30, 0.2))
+	}
+	profiles = append(profiles, *NewTripProfile(9, 1.4, 30, 0.2))
+	athens, _ := time.LoadLocation("Europe/Athens")
+	travelModel := NewTravelModel(athens, profiles)
+
+	assert.Len(t, profilesAt(travelModel, time.Date(2014, 7, 17, 5, 30, 0, 0, time.UTC)), MinimumHourTrips)
+	assert.Len(t, profilesAt(travelModel, time.Date(2014, 7, 17, 6, 30, 0, 0, time.UTC)), MinimumHourTrips+1)
+}
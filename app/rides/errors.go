@@ -6,9 +6,74 @@ package rides
 
 import (
 	"errors"
+	baseAppErrors "github.com/iliaskaras/fare-estimation/app/infrastructure/errors"
 )
 
+type PositionFilterError struct {
+	baseAppErrors.BaseAppError
+}
+
+func NewPositionFilterError(err error, additionalInfo string) PositionFilterError {
+	return PositionFilterError{
+		BaseAppError: baseAppErrors.NewBaseAppError(err, additionalInfo),
+	}
+}
+
+// CoordinateRangeError is the error of the CoordinateRangeRule.
+type CoordinateRangeError struct {
+	baseAppErrors.BaseAppError
+}
+
+func NewCoordinateRangeError(err error, additionalInfo string) CoordinateRangeError {
+	return CoordinateRangeError{
+		BaseAppError: baseAppErrors.NewBaseAppError(err, additionalInfo),
+	}
+}
+
+// NullIslandError is the error of the NullIslandRule.
+type NullIslandError struct {
+	baseAppErrors.BaseAppError
+}
+
+func NewNullIslandError(err error, additionalInfo string) NullIslandError {
+	return NullIslandError{
+		BaseAppError: baseAppErrors.NewBaseAppError(err, additionalInfo),
+	}
+}
+
+// ServiceAreaError is the error of the ServiceAreaRule.
+type ServiceAreaError struct {
+	baseAppErrors.BaseAppError
+}
+
+func NewServiceAreaError(err error, additionalInfo string) ServiceAreaError {
+	return ServiceAreaError{
+		BaseAppError: baseAppErrors.NewBaseAppError(err, additionalInfo),
+	}
+}
+
+// TimestampBoundsError is the error of the TimestampBoundsRule.
+type TimestampBoundsError struct {
+	baseAppErrors.BaseAppError
+}
+
+func NewTimestampBoundsError(err error, additionalInfo string) TimestampBoundsError {
+	return TimestampBoundsError{
+		BaseAppError: baseAppErrors.NewBaseAppError(err, additionalInfo),
+	}
+}
+
 var (
-	ErrorParsingRidePosition = errors.New("error while parsing ride position")
-	InvalidLPosition         = errors.New("error while parsing ride position")
+	ErrorParsingRidePosition   = errors.New("error while parsing ride position")
+	InvalidPosition            = errors.New("ride position breaks a validation rule")
+	UnsupportedPositionFilter  = errors.New("unsupported position filter")
+	InvalidPositionFilter      = errors.New("invalid position filter")
+	UnsupportedTimestampPolicy = errors.New("unsupported timestamp policy")
+	UnsupportedValidationRule  = errors.New("unsupported validation rule")
+	InvalidValidationRule      = errors.New("invalid validation rule")
 )
+
+// InvalidLPosition is the InvalidPosition.
+//
+// Deprecated: use InvalidPosition.
+var InvalidLPosition = InvalidPosition
@@ -5,16 +5,226 @@ Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
 package rides
 
 import (
+	"fmt"
 	"github.com/iliaskaras/fare-estimation/app/distances"
+	"strings"
+	"time"
 )
 
+const (
+	defaultPositionFilter  = SpeedPositionFilter
+	defaultTimestampPolicy = RejectTimestamps
+)
+
+var defaultValidationRules = []string{
+	RangeValidationRule,
+	NullIslandValidationRule,
+	TimestampValidationRule,
+}
+
+// DefaultValidationRules returns the names of the PositionRules that the RidePositions are validated with by default,
+// being every supported one.
+func DefaultValidationRules() []string {
+	return append([]string(nil), defaultValidationRules...)
+}
+
+var supportedPositionFilters = []string{
+	SpeedPositionFilter,
+	AccelerationPositionFilter,
+	MedianPositionFilter,
+	KalmanPositionFilter,
+}
+
 // GetRidePositionService is responsible for initializing and injecting all the dependencies
-// of the RidePositionService.
+// of the RidePositionService, cleaning the RidePositions with the PositionFilter of the positionFilters,
+// after validating them with the PositionValidator of the config's ValidationRules, and handling their
+// duplicate and out of order timestamps with the config's TimestampPolicy. The RidePositions
+// dropped are added to the config's RejectionLog, if any.
 func GetRidePositionService(
 	distanceCalculatorMethod distances.DistanceCalculatorService,
+	positionFilters string,
+	config PositionFilterConfig,
 ) (*RidePositionService, error) {
+	if config.TimestampPolicy == "" {
+		config.TimestampPolicy = defaultTimestampPolicy
+	}
+	if config.TimestampPolicy != RejectTimestamps &&
+		config.TimestampPolicy != MergeTimestamps &&
+		config.TimestampPolicy != SortTimestamps {
+		return nil, NewPositionFilterError(
+			UnsupportedTimestampPolicy,
+			"timestamp policy: "+string(config.TimestampPolicy)+" must be one of: "+
+				string(RejectTimestamps)+", "+string(MergeTimestamps)+", "+string(SortTimestamps),
+		)
+	}
+
+	positionValidator, err := GetPositionValidator(config)
+	if err != nil {
+		return nil, err
+	}
+
+	positionFilter, err := GetPositionFilter(distanceCalculatorMethod, positionFilters, config)
+	if err != nil {
+		return nil, err
+	}
 
 	return NewRidePositionService(
 		distanceCalculatorMethod,
+		positionValidator,
+		config.TimestampPolicy,
+		positionFilter,
+		config.RejectionLog,
 	), nil
 }
+
+// GetPositionFilter returns the PositionFilter of the comma separated positionFilters, chained into a
+// FilterPipeline in the given order when more than one, e.g. "speed,median", the SpeedGate being the default.
+// The defaults of the PositionFilterConfig are used for its zero settings, e.g. the default MaxKMPerHour
+// when no MaxKMPerHour is provided.
+func GetPositionFilter(
+	distanceCalculatorMethod distances.DistanceCalculatorService,
+	positionFilters string,
+	config PositionFilterConfig,
+) (PositionFilter, error) {
+	if strings.TrimSpace(positionFilters) == "" {
+		positionFilters = defaultPositionFilter
+	}
+
+	if config.MaxKMPerHour <= 0 {
+		config.MaxKMPerHour = MaxKMPerHour
+	}
+	if config.MaxAcceleration < 0 {
+		return nil, NewPositionFilterError(
+			InvalidPositionFilter,
+			fmt.Sprintf("max acceleration: %v must not be negative", config.MaxAcceleration),
+		)
+	}
+	if config.MaxAcceleration == 0 {
+		config.MaxAcceleration = MaxAcceleration
+	}
+	if config.MedianWindow == 0 {
+		config.MedianWindow = MedianWindow
+	}
+	if config.MedianWindow < 0 || config.MedianWindow%2 == 0 {
+		return nil, NewPositionFilterError(
+			InvalidPositionFilter,
+			fmt.Sprintf("median window: %d must be a positive odd number", config.MedianWindow),
+		)
+	}
+	if config.KalmanMeasurementNoise < 0 || config.KalmanProcessNoise < 0 {
+		return nil, NewPositionFilterError(
+			InvalidPositionFilter,
+			fmt.Sprintf(
+				"kalman measurement noise: %v and process noise: %v must not be negative",
+				config.KalmanMeasurementNoise,
+				config.KalmanProcessNoise,
+			),
+		)
+	}
+	if config.KalmanMeasurementNoise == 0 {
+		config.KalmanMeasurementNoise = KalmanMeasurementNoise
+	}
+	if config.KalmanProcessNoise == 0 {
+		config.KalmanProcessNoise = KalmanProcessNoise
+	}
+
+	var filterPipeline FilterPipeline
+	for _, positionFilter := range strings.Split(positionFilters, ",") {
+		switch strings.TrimSpace(positionFilter) {
+		case SpeedPositionFilter:
+			filterPipeline = append(
+				filterPipeline,
+				NewSpeedGate(distanceCalculatorMethod, config.MaxKMPerHour, config.RecoveryRejections),
+			)
+		case AccelerationPositionFilter:
+			filterPipeline = append(
+				filterPipeline,
+				NewAccelerationGate(config.MaxAcceleration, config.RecoveryRejections),
+			)
+		case MedianPositionFilter:
+			filterPipeline = append(filterPipeline, NewMedianFilter(config.MedianWindow))
+		case KalmanPositionFilter:
+			filterPipeline = append(
+				filterPipeline,
+				NewKalmanSmoother(config.KalmanMeasurementNoise, config.KalmanProcessNoise, config.MaxKMPerHour),
+			)
+		default:
+			return nil, NewPositionFilterError(
+				UnsupportedPositionFilter,
+				"provided position filter: "+positionFilter+", "+
+					"must be one of the: "+strings.Join(supportedPositionFilters, ","),
+			)
+		}
+	}
+
+	if len(filterPipeline) == 1 {
+		return filterPipeline[0], nil
+	}
+
+	return filterPipeline, nil
+}
+
+// GetPositionValidator returns the PositionValidator of the config's comma separated ValidationRules, e.g.
+// "range,null_island", all of them by default, or none with NoValidationRules. The ServiceAreaRule is added
+// when the config has a ServiceArea, and the TimestampBoundsRule checks the timestamps up to now by default.
+func GetPositionValidator(config PositionFilterConfig) (PositionValidator, error) {
+	validationRules := defaultValidationRules
+	switch strings.TrimSpace(config.ValidationRules) {
+	case "":
+	case NoValidationRules:
+		validationRules = nil
+	default:
+		validationRules = strings.Split(config.ValidationRules, ",")
+	}
+
+	if config.MinTimestamp == 0 {
+		config.MinTimestamp = MinTimestamp
+	}
+	if config.MaxTimestamp == 0 {
+		config.MaxTimestamp = time.Now().Unix()
+	}
+	if config.MinTimestamp > config.MaxTimestamp {
+		return nil, NewPositionFilterError(
+			InvalidValidationRule,
+			fmt.Sprintf(
+				"min timestamp: %d must not be after the max timestamp: %d",
+				config.MinTimestamp,
+				config.MaxTimestamp,
+			),
+		)
+	}
+
+	var positionValidator PositionValidator
+	for _, validationRule := range validationRules {
+		switch strings.TrimSpace(validationRule) {
+		case RangeValidationRule:
+			positionValidator = append(positionValidator, NewCoordinateRangeRule())
+		case NullIslandValidationRule:
+			positionValidator = append(positionValidator, NewNullIslandRule())
+		case TimestampValidationRule:
+			positionValidator = append(
+				positionValidator,
+				NewTimestampBoundsRule(config.MinTimestamp, config.MaxTimestamp),
+			)
+		default:
+			return nil, NewPositionFilterError(
+				UnsupportedValidationRule,
+				"provided validation rule: "+validationRule+", "+
+					"must be one of the: "+strings.Join(defaultValidationRules, ",")+" or "+NoValidationRules,
+			)
+		}
+	}
+
+	if config.ServiceArea != nil {
+		serviceArea := *config.ServiceArea
+		if serviceArea.MinLat > serviceArea.MaxLat || serviceArea.MinLng > serviceArea.MaxLng {
+			return nil, NewPositionFilterError(
+				InvalidValidationRule,
+				"service area: "+serviceArea.String()+" must be minLat,minLng,maxLat,maxLng",
+			)
+		}
+		positionValidator = append(positionValidator, NewServiceAreaRule(serviceArea))
+	}
+
+	return positionValidator, nil
+}
@@ -5,21 +5,220 @@ Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
 package rides
 
 import (
+	"errors"
 	"github.com/iliaskaras/fare-estimation/app/distances"
 	"github.com/stretchr/testify/assert"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 // Tests the GetRidePositionService initializes and returns the RidePositionService.
 func TestGetRidePositionService(t *testing.T) {
 	distanceCalculatorMethod, _ := distances.GetDistanceCalculatorService(distances.HaversineMethod)
-	ridePositionService, err := GetRidePositionService(distanceCalculatorMethod)
+	ridePositionService, err := GetRidePositionService(
+		distanceCalculatorMethod,
+		"",
+		PositionFilterConfig{RecoveryRejections: RecoveryRejections},
+	)
 	assert.NoError(t, err)
 
 	returnedServiceType := reflect.TypeOf(ridePositionService).String()
 	expectedServiceType := "*rides.RidePositionService"
 
 	assert.Equal(t, expectedServiceType, returnedServiceType)
+	assert.Equal(
+		t,
+		NewSpeedGate(distanceCalculatorMethod, MaxKMPerHour, RecoveryRejections),
+		ridePositionService.positionFilter,
+	)
 
 }
+
+// Tests the GetRidePositionService uses the provided maximum speed.
+func TestGetRidePositionServiceWithMaxKMPerHour(t *testing.T) {
+	distanceCalculatorMethod, _ := distances.GetDistanceCalculatorService(distances.HaversineMethod)
+	ridePositionService, err := GetRidePositionService(
+		distanceCalculatorMethod,
+		SpeedPositionFilter,
+		PositionFilterConfig{MaxKMPerHour: 130, RecoveryRejections: RecoveryRejections},
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 130.0, ridePositionService.positionFilter.(*SpeedGate).maxKMPerHour)
+}
+
+// Tests the GetPositionFilter chains the PositionFilters into a FilterPipeline in the given order,
+// with the default settings.
+func TestGetPositionFilterReturnsFilterPipeline(t *testing.T) {
+	distanceCalculatorMethod, _ := distances.GetDistanceCalculatorService(distances.HaversineMethod)
+	positionFilter, err := GetPositionFilter(
+		distanceCalculatorMethod,
+		"speed, acceleration,median,kalman",
+		PositionFilterConfig{RecoveryRejections: RecoveryRejections},
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(
+		t,
+		FilterPipeline{
+			NewSpeedGate(distanceCalculatorMethod, MaxKMPerHour, RecoveryRejections),
+			NewAccelerationGate(MaxAcceleration, RecoveryRejections),
+			NewMedianFilter(MedianWindow),
+			NewKalmanSmoother(KalmanMeasurementNoise, KalmanProcessNoise, MaxKMPerHour),
+		},
+		positionFilter,
+	)
+}
+
+// Tests the GetPositionFilter uses the provided settings.
+func TestGetPositionFilterWithConfig(t *testing.T) {
+	distanceCalculatorMethod, _ := distances.GetDistanceCalculatorService(distances.HaversineMethod)
+	positionFilter, err := GetPositionFilter(
+		distanceCalculatorMethod,
+		"median,kalman,acceleration",
+		PositionFilterConfig{
+			MaxKMPerHour:           130,
+			MaxAcceleration:        3,
+			MedianWindow:           7,
+			KalmanMeasurementNoise: 25,
+			KalmanProcessNoise:     1,
+		},
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(
+		t,
+		FilterPipeline{
+			NewMedianFilter(7),
+			NewKalmanSmoother(25, 1, 130),
+			NewAccelerationGate(3, 0),
+		},
+		positionFilter,
+	)
+}
+
+// Tests the GetPositionFilter return a PositionFilterError when a position filter is not supported,
+// or its settings are invalid.
+func TestGetPositionFilterReturnErrorWhenFilterIsInvalid(t *testing.T) {
+	distanceCalculatorMethod, _ := distances.GetDistanceCalculatorService(distances.HaversineMethod)
+
+	positionFilter, err := GetPositionFilter(distanceCalculatorMethod, "speed,particle", PositionFilterConfig{})
+	assert.Nil(t, positionFilter)
+	assert.True(t, errors.Is(err, UnsupportedPositionFilter))
+	assert.IsType(t, PositionFilterError{}, err)
+
+	for _, config := range []PositionFilterConfig{
+		{MedianWindow: 4},
+		{MedianWindow: -3},
+		{MaxAcceleration: -1},
+		{KalmanMeasurementNoise: -10},
+		{KalmanProcessNoise: -2},
+	} {
+		positionFilter, err = GetPositionFilter(distanceCalculatorMethod, MedianPositionFilter, config)
+		assert.Nil(t, positionFilter)
+		assert.True(t, errors.Is(err, InvalidPositionFilter), "config: %+v", config)
+	}
+}
+
+// Tests the GetRidePositionService rejects the duplicate and out of order timestamps by default, and return a
+// PositionFilterError when the TimestampPolicy is not supported.
+func TestGetRidePositionServiceWithTimestampPolicy(t *testing.T) {
+	distanceCalculatorMethod, _ := distances.GetDistanceCalculatorService(distances.HaversineMethod)
+
+	ridePositionService, err := GetRidePositionService(distanceCalculatorMethod, "", PositionFilterConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, RejectTimestamps, ridePositionService.timestampPolicy)
+	assert.Equal(t, RejectTimestamps, ridePositionService.Summary().TimestampPolicy)
+
+	ridePositionService, err = GetRidePositionService(
+		distanceCalculatorMethod,
+		"",
+		PositionFilterConfig{TimestampPolicy: "interpolate"},
+	)
+	assert.Nil(t, ridePositionService)
+	assert.True(t, errors.Is(err, UnsupportedTimestampPolicy))
+	assert.IsType(t, PositionFilterError{}, err)
+}
+
+// Tests the GetPositionValidator returns the PositionRules of the ValidationRules, all but the ServiceAreaRule by
+// default, and the ServiceAreaRule when a ServiceArea is provided.
+func TestGetPositionValidator(t *testing.T) {
+	positionValidator, err := GetPositionValidator(PositionFilterConfig{MaxTimestamp: 1700000000})
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		PositionValidator{
+			NewCoordinateRangeRule(),
+			NewNullIslandRule(),
+			NewTimestampBoundsRule(MinTimestamp, 1700000000),
+		},
+		positionValidator,
+	)
+
+	positionValidator, err = GetPositionValidator(
+		PositionFilterConfig{
+			ValidationRules: "null_island",
+			ServiceArea:     NewBoundingBox(37.8, 23.5, 38.2, 24.1),
+		},
+	)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		PositionValidator{NewNullIslandRule(), NewServiceAreaRule(*NewBoundingBox(37.8, 23.5, 38.2, 24.1))},
+		positionValidator,
+	)
+
+	positionValidator, err = GetPositionValidator(PositionFilterConfig{ValidationRules: NoValidationRules})
+	assert.NoError(t, err)
+	assert.Empty(t, positionValidator)
+
+	positionValidator, err = GetPositionValidator(PositionFilterConfig{})
+	assert.NoError(t, err)
+	assert.NoError(t, positionValidator.validate(*NewRidePosition(1, 37.938598, 23.630322, time.Now().Unix()-60)))
+}
+
+// Tests the DefaultValidationRules returns a copy of the default ValidationRules, that validate the RidePositions
+// as the omitted ones do.
+func TestDefaultValidationRules(t *testing.T) {
+	defaultRules := DefaultValidationRules()
+	assert.Equal(t, []string{RangeValidationRule, NullIslandValidationRule, TimestampValidationRule}, defaultRules)
+
+	positionValidator, err := GetPositionValidator(
+		PositionFilterConfig{ValidationRules: strings.Join(defaultRules, ","), MaxTimestamp: 1700000000},
+	)
+	assert.NoError(t, err)
+	defaultPositionValidator, _ := GetPositionValidator(PositionFilterConfig{MaxTimestamp: 1700000000})
+	assert.Equal(t, defaultPositionValidator, positionValidator)
+
+	defaultRules[0] = NoValidationRules
+	assert.Equal(t, RangeValidationRule, DefaultValidationRules()[0])
+}
+
+// Tests the GetPositionValidator return a PositionFilterError when a validation rule is not supported, or its
+// settings are invalid.
+func TestGetPositionValidatorReturnErrorWhenRuleIsInvalid(t *testing.T) {
+	positionValidator, err := GetPositionValidator(PositionFilterConfig{ValidationRules: "range,speed"})
+	assert.Nil(t, positionValidator)
+	assert.True(t, errors.Is(err, UnsupportedValidationRule))
+	assert.IsType(t, PositionFilterError{}, err)
+
+	for _, config := range []PositionFilterConfig{
+		{MinTimestamp: 1700000000, MaxTimestamp: 1600000000},
+		{ServiceArea: NewBoundingBox(38.2, 23.5, 37.8, 24.1)},
+		{ServiceArea: NewBoundingBox(37.8, 24.1, 38.2, 23.5)},
+	} {
+		positionValidator, err = GetPositionValidator(config)
+		assert.Nil(t, positionValidator)
+		assert.True(t, errors.Is(err, InvalidValidationRule), "config: %+v", config)
+	}
+
+	ridePositionService, err := GetRidePositionService(
+		nil,
+		"",
+		PositionFilterConfig{ValidationRules: "range,speed"},
+	)
+	assert.Nil(t, ridePositionService)
+	assert.True(t, errors.Is(err, UnsupportedValidationRule))
+}
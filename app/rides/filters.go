@@ -0,0 +1,422 @@
+/*
+Package rides
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package rides
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/distances"
+	"math"
+	"sort"
+)
+
+const (
+	kmPerHourInMeters = 1000.0 / HourInSeconds
+)
+
+// PositionFilter cleans the RidePositions of a single RideID out of the GPS noise, returning the
+// RidePositions kept, in order, either by dropping the erroneous ones or by correcting their coordinates,
+// along with the RejectedPositions of the ones dropped.
+type PositionFilter interface {
+	Filter(ridePositions []RidePosition) ([]RidePosition, []RejectedPosition)
+}
+
+// FilterPipeline is the PositionFilter that chains its PositionFilters, each one cleaning the
+// RidePositions kept by the previous one.
+type FilterPipeline []PositionFilter
+
+func (fp FilterPipeline) Filter(ridePositions []RidePosition) ([]RidePosition, []RejectedPosition) {
+	var rejectedPositions []RejectedPosition
+	for _, positionFilter := range fp {
+		var filterRejectedPositions []RejectedPosition
+		ridePositions, filterRejectedPositions = positionFilter.Filter(ridePositions)
+		rejectedPositions = append(rejectedPositions, filterRejectedPositions...)
+	}
+
+	return ridePositions, rejectedPositions
+}
+
+// SpeedGate is the PositionFilter that drops the RidePositions reached above the maxKMPerHour from the
+// last RidePosition kept, re-anchoring on the recoveryRejections agreeing rejected RidePositions when the
+// last RidePosition kept is the outlier.
+type SpeedGate struct {
+	distanceCalculator distances.DistanceCalculatorService
+	maxKMPerHour       float64
+	recoveryRejections int
+}
+
+func NewSpeedGate(
+	distanceCalculator distances.DistanceCalculatorService,
+	maxKMPerHour float64,
+	recoveryRejections int,
+) *SpeedGate {
+	return &SpeedGate{
+		distanceCalculator: distanceCalculator,
+		maxKMPerHour:       maxKMPerHour,
+		recoveryRejections: recoveryRejections,
+	}
+}
+
+// Filter is responsibly for filtering out the second RidePosition out of a segment, if the calculated
+// Speed km/hour is greater than the maxKMPerHour.
+// When the current RidePosition is itself the erroneous one, e.g. the first RidePosition of a ride
+// being a GPS teleport, every following RidePosition is rejected against it. Thus, once the last
+// recoveryRejections consecutive rejected RidePositions agree with each other, while fewer segments
+// have been kept, the kept RidePositions are dropped as outliers and the filtering is re-anchored on
+// the agreeing RidePositions. A current RidePosition backed by enough segments is kept instead,
+// the rejections being a genuine gap of the GPS signal or a stretch driven above the maxKMPerHour.
+func (sg *SpeedGate) Filter(ridePositions []RidePosition) ([]RidePosition, []RejectedPosition) {
+	ridePositionsSize := len(ridePositions)
+	if ridePositionsSize == 0 {
+		return nil, nil
+	}
+
+	filteredRidePositions := []RidePosition{ridePositions[0]}
+	var rejectedPositions []RejectedPosition
+	// The index of the RidePosition of each of the rejectedPositions.
+	var rejectedIndexes []int
+
+	i := 0
+	j := 1
+	for j < ridePositionsSize {
+		// Sanity check on the segment speed, if is greater than the maxKMPerHour,
+		// then this means that the check failed and the second part of the
+		// segment, which is the next RidePosition, needs to be skipped because
+		// is found to be erroneous. The skip happen by just increasing the next
+		// index j.
+		speed := sg.speed(ridePositions[i], ridePositions[j])
+		if speed > sg.maxKMPerHour {
+			// The RidePositions from the index i + 1 up to the index j have all been rejected against
+			// the current RidePosition. When the last recoveryRejections of them agree with each other,
+			// while the current RidePosition is backed by fewer segments, it is the outlier, thus
+			// its segments are dropped and the filtering restarts from the agreeing RidePositions.
+			if sg.recoveryRejections >= MinRecoveryRejections &&
+				len(filteredRidePositions)-1 < sg.recoveryRejections &&
+				j-i >= sg.recoveryRejections &&
+				sg.agree(ridePositions[j-sg.recoveryRejections+1:j+1]) {
+				i = j - sg.recoveryRejections + 1
+				j = i + 1
+
+				// The agreeing RidePositions are no longer rejected, while the RidePositions kept are.
+				for len(rejectedIndexes) > 0 && rejectedIndexes[len(rejectedIndexes)-1] >= i {
+					rejectedIndexes = rejectedIndexes[:len(rejectedIndexes)-1]
+					rejectedPositions = rejectedPositions[:len(rejectedPositions)-1]
+				}
+				for _, outlierRidePosition := range filteredRidePositions {
+					rejectedPositions = append(
+						rejectedPositions,
+						*NewRejectedPosition(outlierRidePosition, AnchorOutlier, sg.speed(outlierRidePosition, ridePositions[i])),
+					)
+				}
+
+				filteredRidePositions = []RidePosition{ridePositions[i]}
+				continue
+			}
+
+			rejectedPositions = append(rejectedPositions, *NewRejectedPosition(ridePositions[j], SpeedAboveLimit, speed))
+			rejectedIndexes = append(rejectedIndexes, j)
+			j += 1
+			continue
+		}
+
+		// The two RidePositions are valid entries, thus the next RidePosition is kept and
+		// becomes the current RidePosition in the next loop.
+		filteredRidePositions = append(filteredRidePositions, ridePositions[j])
+		i = j
+		j = i + 1
+	}
+
+	return filteredRidePositions, rejectedPositions
+}
+
+// agree returns whether each of the consecutive ridePositions is reachable from the previous one
+// within the maxKMPerHour.
+func (sg *SpeedGate) agree(ridePositions []RidePosition) bool {
+	for k := 1; k < len(ridePositions); k++ {
+		if sg.speed(ridePositions[k-1], ridePositions[k]) > sg.maxKMPerHour {
+			return false
+		}
+	}
+
+	return true
+}
+
+// speed returns the speed in km/hour of the segment between the two RidePositions.
+func (sg *SpeedGate) speed(from RidePosition, to RidePosition) float64 {
+	return segmentSpeed(sg.distanceCalculator, from, to)
+}
+
+// AccelerationGate is the PositionFilter that drops the RidePositions that would change the velocity of the
+// ride by more than the maxAcceleration in meters/second², against the velocity of the last segment kept,
+// re-anchoring on the recoveryRejections agreeing rejected RidePositions as the SpeedGate does.
+// Unlike the SpeedGate, it catches the spikes that stay below the max speed, e.g. a vehicle stopped at
+// the lights appearing to jump at 80km/hour for a single RidePosition.
+type AccelerationGate struct {
+	maxAcceleration    float64
+	recoveryRejections int
+}
+
+func NewAccelerationGate(maxAcceleration float64, recoveryRejections int) *AccelerationGate {
+	return &AccelerationGate{
+		maxAcceleration:    maxAcceleration,
+		recoveryRejections: recoveryRejections,
+	}
+}
+
+// Filter keeps the first two RidePositions, there being no previous segment to check the first one
+// against, and then each RidePosition reached with an acceleration within the maxAcceleration. The
+// velocities are the east and north meters/second of the segments, so that a turn is an acceleration too.
+// When the second RidePosition is itself a spike, its velocity rejects every following RidePosition,
+// thus once the last recoveryRejections consecutive rejected RidePositions agree with each other, while
+// fewer segments have been kept, the kept RidePositions are dropped as outliers and the filtering is
+// re-anchored on the agreeing RidePositions.
+func (ag *AccelerationGate) Filter(ridePositions []RidePosition) ([]RidePosition, []RejectedPosition) {
+	if len(ridePositions) == 0 {
+		return nil, nil
+	}
+
+	filteredRidePositions := []RidePosition{ridePositions[0]}
+	var rejectedPositions []RejectedPosition
+	// The index of the RidePosition of each of the rejectedPositions.
+	var rejectedIndexes []int
+	var previousVelocity *[2]float64
+
+	i := 0
+	for j := 1; j < len(ridePositions); j++ {
+		velocity := segmentVelocity(ridePositions[i], ridePositions[j])
+
+		if previousVelocity != nil && ag.exceeds(*previousVelocity, velocity, ridePositions[i], ridePositions[j]) {
+			if ag.recoveryRejections >= MinRecoveryRejections &&
+				len(filteredRidePositions)-1 < ag.recoveryRejections &&
+				j-i >= ag.recoveryRejections &&
+				ag.agree(ridePositions[j-ag.recoveryRejections+1:j+1]) {
+				i = j - ag.recoveryRejections + 1
+
+				// The agreeing RidePositions are no longer rejected, while the RidePositions kept are.
+				for len(rejectedIndexes) > 0 && rejectedIndexes[len(rejectedIndexes)-1] >= i {
+					rejectedIndexes = rejectedIndexes[:len(rejectedIndexes)-1]
+					rejectedPositions = rejectedPositions[:len(rejectedPositions)-1]
+				}
+				for _, outlierRidePosition := range filteredRidePositions {
+					rejectedPositions = append(
+						rejectedPositions,
+						*NewRejectedPosition(
+							outlierRidePosition,
+							AnchorOutlier,
+							planarSpeed(segmentVelocity(outlierRidePosition, ridePositions[i])),
+						),
+					)
+				}
+
+				filteredRidePositions = []RidePosition{ridePositions[i]}
+				previousVelocity = nil
+				j = i
+				continue
+			}
+
+			rejectedPositions = append(
+				rejectedPositions,
+				*NewRejectedPosition(ridePositions[j], AccelerationAboveLimit, planarSpeed(velocity)),
+			)
+			rejectedIndexes = append(rejectedIndexes, j)
+			continue
+		}
+
+		filteredRidePositions = append(filteredRidePositions, ridePositions[j])
+		previousVelocity = &velocity
+		i = j
+	}
+
+	return filteredRidePositions, rejectedPositions
+}
+
+// exceeds returns whether changing from the previousVelocity to the velocity of the segment between the
+// two RidePositions takes an acceleration above the maxAcceleration.
+func (ag *AccelerationGate) exceeds(previousVelocity, velocity [2]float64, from, to RidePosition) bool {
+	elapsedTimeSecs := float64(to.Timestamp - from.Timestamp)
+	velocityChange := math.Hypot(velocity[0]-previousVelocity[0], velocity[1]-previousVelocity[1])
+
+	return velocityChange/elapsedTimeSecs > ag.maxAcceleration
+}
+
+// agree returns whether each of the consecutive ridePositions is reached from the previous one within
+// the maxAcceleration.
+func (ag *AccelerationGate) agree(ridePositions []RidePosition) bool {
+	for k := 2; k < len(ridePositions); k++ {
+		previousVelocity := segmentVelocity(ridePositions[k-2], ridePositions[k-1])
+		velocity := segmentVelocity(ridePositions[k-1], ridePositions[k])
+		if ag.exceeds(previousVelocity, velocity, ridePositions[k-1], ridePositions[k]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// segmentVelocity returns the east and north velocity in meters/second of the segment between the two
+// RidePositions.
+func segmentVelocity(from, to RidePosition) [2]float64 {
+	elapsedTimeSecs := float64(to.Timestamp - from.Timestamp)
+	east, north := planarOffset(from, to)
+
+	return [2]float64{east / elapsedTimeSecs, north / elapsedTimeSecs}
+}
+
+// planarSpeed returns the speed in km/hour of the velocity in meters/second.
+func planarSpeed(velocity [2]float64) float64 {
+	return math.Hypot(velocity[0], velocity[1]) / kmPerHourInMeters
+}
+
+// MedianFilter is the PositionFilter that replaces the coordinates of each RidePosition with the median
+// latitude and longitude of the window RidePositions centred on it, the window shrinking at the ends of
+// the ride. It keeps every RidePosition, removing the isolated spikes and the jitter of the urban canyons.
+type MedianFilter struct {
+	window int
+}
+
+func NewMedianFilter(window int) *MedianFilter {
+	return &MedianFilter{
+		window: window,
+	}
+}
+
+func (mf *MedianFilter) Filter(ridePositions []RidePosition) ([]RidePosition, []RejectedPosition) {
+	if len(ridePositions) == 0 {
+		return nil, nil
+	}
+
+	filteredRidePositions := make([]RidePosition, len(ridePositions))
+	halfWindow := mf.window / 2
+
+	for k, ridePosition := range ridePositions {
+		// Shrinks the window symmetrically at the ends, so that it stays centred on the RidePosition.
+		reach := int(math.Min(float64(halfWindow), math.Min(float64(k), float64(len(ridePositions)-1-k))))
+		window := ridePositions[k-reach : k+reach+1]
+
+		lats := make([]float64, len(window))
+		lngs := make([]float64, len(window))
+		for w, windowRidePosition := range window {
+			lats[w] = windowRidePosition.Lat
+			lngs[w] = windowRidePosition.Lng
+		}
+
+		ridePosition.Lat = median(lats)
+		ridePosition.Lng = median(lngs)
+		filteredRidePositions[k] = ridePosition
+	}
+
+	return filteredRidePositions, nil
+}
+
+// KalmanSmoother is the PositionFilter that smooths the coordinates of the RidePositions with a constant
+// velocity Kalman filter, followed by a Rauch-Tung-Striebel backward pass, so that each RidePosition is
+// estimated out of the whole ride. The RidePositions are measured with the measurementNoise in meters,
+// and the vehicle is modelled to accelerate randomly with the processNoise in meters/second², starting
+// with a velocity uncertainty of the maxKMPerHour. It keeps every RidePosition.
+type KalmanSmoother struct {
+	measurementNoise float64
+	processNoise     float64
+	maxKMPerHour     float64
+}
+
+func NewKalmanSmoother(measurementNoise float64, processNoise float64, maxKMPerHour float64) *KalmanSmoother {
+	return &KalmanSmoother{
+		measurementNoise: measurementNoise,
+		processNoise:     processNoise,
+		maxKMPerHour:     maxKMPerHour,
+	}
+}
+
+// Filter smooths the RidePositions on a local plane in meters around the first RidePosition, smoothing the
+// east and the north axis independently. The RidePositions with a timestamp that is not after the previous
+// one are treated as measured at the same time.
+func (ks *KalmanSmoother) Filter(ridePositions []RidePosition) ([]RidePosition, []RejectedPosition) {
+	if len(ridePositions) == 0 {
+		return nil, nil
+	}
+
+	origin := ridePositions[0]
+
+	elapsedTimesSecs := make([]float64, len(ridePositions))
+	easts := make([]float64, len(ridePositions))
+	norths := make([]float64, len(ridePositions))
+	for k, ridePosition := range ridePositions {
+		if k > 0 {
+			elapsedTimesSecs[k] = math.Max(0, float64(ridePosition.Timestamp-ridePositions[k-1].Timestamp))
+		}
+		easts[k], norths[k] = planarOffset(origin, ridePosition)
+	}
+
+	smoothedEasts := ks.smooth(easts, elapsedTimesSecs)
+	smoothedNorths := ks.smooth(norths, elapsedTimesSecs)
+
+	filteredRidePositions := make([]RidePosition, len(ridePositions))
+	for k, ridePosition := range ridePositions {
+		ridePosition.Lat, ridePosition.Lng = planarPosition(origin, smoothedEasts[k], smoothedNorths[k])
+		filteredRidePositions[k] = ridePosition
+	}
+
+	return filteredRidePositions, nil
+}
+
+// smooth returns the smoothed positions of a single axis, each measured the elapsed time after the previous.
+func (ks *KalmanSmoother) smooth(measurements []float64, elapsedTimesSecs []float64) []float64 {
+	size := len(measurements)
+	measurementVariance := ks.measurementNoise * ks.measurementNoise
+	processVariance := ks.processNoise * ks.processNoise
+
+	// The filtered and the predicted state, position and velocity, and covariance of each measurement.
+	filteredStates := make([]kalmanState, size)
+	predictedStates := make([]kalmanState, size)
+
+	// Starts at the first measurement standing still, with a velocity uncertainty of the max speed.
+	maxVelocity := ks.maxKMPerHour * kmPerHourInMeters
+	state := kalmanState{
+		position:   measurements[0],
+		covariance: [2][2]float64{{measurementVariance, 0}, {0, maxVelocity * maxVelocity}},
+	}
+
+	for k := 0; k < size; k++ {
+		if k > 0 {
+			state = state.predict(elapsedTimesSecs[k], processVariance)
+		}
+		predictedStates[k] = state
+
+		state = state.update(measurements[k], measurementVariance)
+		filteredStates[k] = state
+	}
+
+	smoothed := make([]float64, size)
+	smoothedState := filteredStates[size-1]
+	smoothed[size-1] = smoothedState.position
+
+	for k := size - 2; k >= 0; k-- {
+		smoothedState = filteredStates[k].smooth(smoothedState, predictedStates[k+1], elapsedTimesSecs[k+1])
+		smoothed[k] = smoothedState.position
+	}
+
+	return smoothed
+}
+
+// segmentSpeed returns the speed in km/hour of the segment between the two RidePositions.
+func segmentSpeed(
+	distanceCalculator distances.DistanceCalculatorService,
+	from RidePosition,
+	to RidePosition,
+) float64 {
+	elapsedTimeSecs := to.Timestamp - from.Timestamp
+	distanceCovered := distanceCalculator.GetDistance(from.Lat, from.Lng, to.Lat, to.Lng)
+
+	return (distanceCovered / float64(elapsedTimeSecs)) * HourInSeconds
+}
+
+// median returns the median of the values, sorting them in place.
+func median(values []float64) float64 {
+	sort.Float64s(values)
+	middle := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[middle-1] + values[middle]) / 2
+	}
+
+	return values[middle]
+}
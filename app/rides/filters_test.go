@@ -0,0 +1,206 @@
+/*
+Package rides
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package rides
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/distances"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"strconv"
+	"testing"
+)
+
+// ridePositionTimestamps returns the Timestamps of the RidePositions.
+func ridePositionTimestamps(ridePositions []RidePosition) []int64 {
+	var timestamps []int64
+	for _, ridePosition := range ridePositions {
+		timestamps = append(timestamps, ridePosition.Timestamp)
+	}
+
+	return timestamps
+}
+
+// Tests the SpeedGate keeps the RidePositions of the segments that the RidePositionService returns.
+func TestSpeedGateFilter(t *testing.T) {
+	distanceCalculatorMethod, _ := distances.GetDistanceCalculatorService(distances.HaversineMethod)
+	speedGate := NewSpeedGate(distanceCalculatorMethod, MaxKMPerHour, RecoveryRejections)
+
+	filteredRidePositions, rejectedPositions := speedGate.Filter(nil)
+	assert.Nil(t, filteredRidePositions)
+	assert.Nil(t, rejectedPositions)
+
+	filteredRidePositions, rejectedPositions = speedGate.Filter(athensTrace(5, map[int]float64{3: 0.02}))
+	assert.Equal(
+		t,
+		[]int64{1405594957, 1405594967, 1405594977, 1405594997},
+		ridePositionTimestamps(filteredRidePositions),
+	)
+	assert.Len(t, rejectedPositions, 1)
+	assert.Equal(t, SpeedAboveLimit, rejectedPositions[0].Reason)
+	assert.Equal(t, "6", rejectedPositions[0].Fields[0])
+	assert.Equal(t, "1405594987", rejectedPositions[0].Fields[3])
+	assert.Greater(t, rejectedPositions[0].Speed, float64(MaxKMPerHour))
+}
+
+// Tests the SpeedGate records the RidePositions kept before re-anchoring as anchor outliers, with their
+// speed to the new anchor, and drops the rejections of the RidePositions the new anchor backs.
+func TestSpeedGateFilterRecordsAnchorOutliers(t *testing.T) {
+	distanceCalculatorMethod, _ := distances.GetDistanceCalculatorService(distances.HaversineMethod)
+	speedGate := NewSpeedGate(distanceCalculatorMethod, MaxKMPerHour, RecoveryRejections)
+
+	filteredRidePositions, rejectedPositions := speedGate.Filter(athensTrace(6, map[int]float64{0: 0.02}))
+
+	assert.Equal(
+		t,
+		[]int64{1405594967, 1405594977, 1405594987, 1405594997, 1405595007},
+		ridePositionTimestamps(filteredRidePositions),
+	)
+	assert.Len(t, rejectedPositions, 1)
+	assert.Equal(t, AnchorOutlier, rejectedPositions[0].Reason)
+	assert.Equal(t, "1405594957", rejectedPositions[0].Fields[3])
+	assert.Greater(t, rejectedPositions[0].Speed, float64(MaxKMPerHour))
+}
+
+// Tests the AccelerationGate drops a RidePosition below the max speed but reached with a sudden acceleration,
+// a vehicle stopped at the lights appearing to jump 25 meters away for a single RidePosition sampled every second.
+func TestAccelerationGateFilter(t *testing.T) {
+	distanceCalculatorMethod, _ := distances.GetDistanceCalculatorService(distances.HaversineMethod)
+
+	ridePositions := athensTrace(6, nil)
+	for k := range ridePositions {
+		ridePositions[k].Lat = 37.975500
+		ridePositions[k].Timestamp = 1405594957 + int64(k)
+	}
+	ridePositions[3].Lat = 37.975725
+
+	speedGate := NewSpeedGate(distanceCalculatorMethod, MaxKMPerHour, RecoveryRejections)
+	filteredRidePositions, rejectedPositions := speedGate.Filter(ridePositions)
+	assert.Len(t, filteredRidePositions, 6)
+	assert.Nil(t, rejectedPositions)
+
+	accelerationGate := NewAccelerationGate(MaxAcceleration, RecoveryRejections)
+	filteredRidePositions, rejectedPositions = accelerationGate.Filter(nil)
+	assert.Nil(t, filteredRidePositions)
+	assert.Nil(t, rejectedPositions)
+
+	filteredRidePositions, rejectedPositions = accelerationGate.Filter(ridePositions)
+	assert.Equal(
+		t,
+		[]int64{1405594957, 1405594958, 1405594959, 1405594961, 1405594962},
+		ridePositionTimestamps(filteredRidePositions),
+	)
+	assert.Len(t, rejectedPositions, 1)
+	assert.Equal(t, AccelerationAboveLimit, rejectedPositions[0].Reason)
+	assert.Equal(t, "1405594960", rejectedPositions[0].Fields[3])
+	assert.InDelta(t, 90, rejectedPositions[0].Speed, 1)
+
+	filteredRidePositions, _ = accelerationGate.Filter(athensTrace(6, nil))
+	assert.Len(t, filteredRidePositions, 6)
+}
+
+// Tests the AccelerationGate re-anchors on the agreeing RidePositions when the second RidePosition is a spike,
+// instead of rejecting the rest of the ride against it.
+func TestAccelerationGateFilterWithSpikedSecondPosition(t *testing.T) {
+	ridePositions := athensTrace(8, nil)
+	for k := range ridePositions {
+		ridePositions[k].Lat = 37.975500
+		ridePositions[k].Timestamp = 1405594957 + int64(k)
+	}
+	ridePositions[1].Lat = 37.975725
+
+	filteredRidePositions, rejectedPositions := NewAccelerationGate(MaxAcceleration, RecoveryRejections).Filter(
+		ridePositions,
+	)
+	assert.Equal(
+		t,
+		[]int64{1405594959, 1405594960, 1405594961, 1405594962, 1405594963, 1405594964},
+		ridePositionTimestamps(filteredRidePositions),
+	)
+	assert.Len(t, rejectedPositions, 2)
+	for k, rejectedPosition := range rejectedPositions {
+		assert.Equal(t, AnchorOutlier, rejectedPosition.Reason)
+		assert.Equal(t, strconv.FormatInt(ridePositions[k].Timestamp, 10), rejectedPosition.Fields[3])
+	}
+
+	filteredRidePositions, rejectedPositions = NewAccelerationGate(MaxAcceleration, 0).Filter(ridePositions)
+	assert.Equal(
+		t,
+		[]int64{1405594957, 1405594958, 1405594962, 1405594963, 1405594964},
+		ridePositionTimestamps(filteredRidePositions),
+	)
+	assert.Len(t, rejectedPositions, 3)
+}
+
+// Tests the MedianFilter keeps every RidePosition, replacing a spike with the median of its window.
+func TestMedianFilterFilter(t *testing.T) {
+	medianFilter := NewMedianFilter(3)
+	ridePositions := athensTrace(5, map[int]float64{2: 0.02})
+
+	filteredRidePositions, rejectedPositions := medianFilter.Filter(ridePositions)
+
+	assert.Nil(t, rejectedPositions)
+	filteredNil, _ := medianFilter.Filter(nil)
+	assert.Nil(t, filteredNil)
+	assert.Equal(t, ridePositionTimestamps(ridePositions), ridePositionTimestamps(filteredRidePositions))
+	assert.Equal(t, ridePositions[0], filteredRidePositions[0])
+	assert.Equal(t, ridePositions[3].Lat, filteredRidePositions[2].Lat)
+	assert.Equal(t, ridePositions[4], filteredRidePositions[4])
+	assert.InDelta(t, 37.996500, ridePositions[2].Lat, 1e-9, "the RidePositions are not modified")
+}
+
+// Tests the KalmanSmoother keeps every RidePosition, bringing the RidePositions jittering around a straight
+// road closer to it.
+func TestKalmanSmootherFilter(t *testing.T) {
+	kalmanSmoother := NewKalmanSmoother(KalmanMeasurementNoise, KalmanProcessNoise, MaxKMPerHour)
+
+	ridePositions := athensTrace(30, nil)
+	jitter := []float64{0.00012, -0.00009, 0.00005, -0.00013, 0.00008, -0.00004}
+	for k := range ridePositions {
+		ridePositions[k].Lng += jitter[k%len(jitter)]
+	}
+
+	filteredRidePositions, rejectedPositions := kalmanSmoother.Filter(ridePositions)
+
+	assert.Nil(t, rejectedPositions)
+	filteredNil, _ := kalmanSmoother.Filter(nil)
+	assert.Nil(t, filteredNil)
+	assert.Equal(t, ridePositionTimestamps(ridePositions), ridePositionTimestamps(filteredRidePositions))
+
+	var jitterError, smoothedError float64
+	for k := range ridePositions {
+		jitterError += math.Abs(ridePositions[k].Lng - 23.734900)
+		smoothedError += math.Abs(filteredRidePositions[k].Lng - 23.734900)
+		assert.InDelta(t, 37.975500+float64(k)*0.0005, filteredRidePositions[k].Lat, 0.00002)
+	}
+	assert.Less(t, smoothedError, jitterError/2)
+}
+
+// Tests the FilterPipeline chains its PositionFilters in order, the SpeedGate dropping a spike that the
+// MedianFilter would otherwise spread to its neighbours.
+func TestFilterPipelineFilter(t *testing.T) {
+	distanceCalculatorMethod, _ := distances.GetDistanceCalculatorService(distances.HaversineMethod)
+	ridePositions := athensTrace(7, map[int]float64{3: 0.02, 4: 0.02})
+
+	speedGate := NewSpeedGate(distanceCalculatorMethod, MaxKMPerHour, RecoveryRejections)
+	medianFilter := NewMedianFilter(3)
+
+	filteredRidePositions, rejectedPositions := FilterPipeline{speedGate, medianFilter}.Filter(ridePositions)
+
+	assert.Equal(
+		t,
+		[]int64{1405594957, 1405594967, 1405594977, 1405595007, 1405595017},
+		ridePositionTimestamps(filteredRidePositions),
+	)
+	for k, ridePosition := range filteredRidePositions {
+		assert.Less(t, ridePosition.Lat, 37.98, "ride position: %d", k)
+	}
+	assert.Len(t, rejectedPositions, 2)
+
+	filteredRidePositions, _ = FilterPipeline{medianFilter, speedGate}.Filter(ridePositions)
+	assert.Len(t, filteredRidePositions, 5)
+	filteredRidePositions, rejectedPositions = FilterPipeline{}.Filter(ridePositions)
+	assert.Equal(t, ridePositions, filteredRidePositions)
+	assert.Nil(t, rejectedPositions)
+}
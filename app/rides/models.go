@@ -5,13 +5,193 @@ Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
 package rides
 
 import (
+	"fmt"
+	"sort"
 	"strconv"
+	"sync"
 )
 
+// The default speed thresholds, which the tariff and the command line can override.
+// - MinimumHourKM: The speed that a RideSegment is considered moving above, and idle at or below.
+// - MaxKMPerHour: The speed that a RideSegment is considered erroneous above.
 const (
 	MinimumHourKM float64 = 10.0
+	MaxKMPerHour  float64 = 100.0
 )
 
+// The thresholds of the anchor recovery, that is how many consecutive rejected RidePositions must agree
+// with each other for the filtering to be re-anchored on them.
+// - RecoveryRejections: The default number of the agreeing rejected RidePositions.
+// - MinRecoveryRejections: The fewest agreeing rejected RidePositions that the anchor recovery works with,
+// a single rejected RidePosition being a spike.
+const (
+	RecoveryRejections    int = 3
+	MinRecoveryRejections int = 2
+)
+
+// The names of the PositionFilters, that the RidePositions can be cleaned with.
+// - SpeedPositionFilter: The SpeedGate, dropping the RidePositions reached above the max speed.
+// - AccelerationPositionFilter: The AccelerationGate, dropping the RidePositions reached above the max acceleration.
+// - MedianPositionFilter: The MedianFilter, replacing the coordinates with their sliding window median.
+// - KalmanPositionFilter: The KalmanSmoother, smoothing the coordinates with a constant velocity model.
+const (
+	SpeedPositionFilter        = "speed"
+	AccelerationPositionFilter = "acceleration"
+	MedianPositionFilter       = "median"
+	KalmanPositionFilter       = "kalman"
+)
+
+// The default settings of the PositionFilters, which the command line can override.
+// - MaxAcceleration: The acceleration in meters/second² that a RidePosition is considered erroneous above,
+// about the hardest braking of a car.
+// - MedianWindow: The number of the RidePositions that the MedianFilter takes the median of.
+// - KalmanMeasurementNoise: The accuracy in meters of the RidePositions, that the KalmanSmoother expects.
+// - KalmanProcessNoise: The random acceleration in meters/second² of the vehicles, that the KalmanSmoother expects.
+const (
+	MaxAcceleration        float64 = 8.0
+	MedianWindow           int     = 5
+	KalmanMeasurementNoise float64 = 10.0
+	KalmanProcessNoise     float64 = 2.0
+)
+
+// The names of the PositionRules, that the RidePositions are validated with.
+// - RangeValidationRule: The CoordinateRangeRule, the latitude within ±90 and the longitude within ±180 degrees.
+// - NullIslandValidationRule: The NullIslandRule, dropping the RidePositions at the 0,0 coordinates.
+// - TimestampValidationRule: The TimestampBoundsRule, dropping the RidePositions before the MinTimestamp or in
+// the future.
+// - NoValidationRules: Validates none of the RidePositions.
+const (
+	RangeValidationRule      = "range"
+	NullIslandValidationRule = "null_island"
+	TimestampValidationRule  = "timestamp"
+	NoValidationRules        = "none"
+)
+
+// MinTimestamp is the default earliest timestamp of a RidePosition, 2000-01-01T00:00:00Z.
+const MinTimestamp int64 = 946684800
+
+// TimestampPolicy is the way that the RidePositions with a timestamp that is not after the previous one's,
+// being duplicate or out of order, are handled before the RidePositions are filtered.
+type TimestampPolicy string
+
+// The TimestampPolicies.
+// - RejectTimestamps: Drops the RidePositions with a timestamp that is not after the last one kept.
+// - MergeTimestamps: Merges the RidePositions with the same timestamp as the last one kept into it, averaging their
+// coordinates, and drops the RidePositions with an earlier timestamp.
+// - SortTimestamps: Sorts the RidePositions by timestamp, keeping the order of the ones with the same timestamp,
+// and merges the duplicate ones.
+const (
+	RejectTimestamps TimestampPolicy = "reject"
+	MergeTimestamps  TimestampPolicy = "merge"
+	SortTimestamps   TimestampPolicy = "sort"
+)
+
+// PositionFilterConfig holds the settings of the cleaning of the RidePositions, the defaults being used for the
+// zero ones, apart from the RecoveryRejections, below MinRecoveryRejections disabling the anchor recovery.
+// - TimestampPolicy: The TimestampPolicy, RejectTimestamps by default.
+// - MaxKMPerHour: The setting of the SpeedGate.
+// - MaxAcceleration: The setting of the AccelerationGate.
+// - RecoveryRejections: The setting of the anchor recovery of the SpeedGate and the AccelerationGate.
+// - MedianWindow: The setting of the MedianFilter, which must be odd.
+// - KalmanMeasurementNoise, KalmanProcessNoise: The settings of the KalmanSmoother.
+// - RejectionLog: The RejectionLog that the RidePositions dropped are added to, if any.
+// - ValidationRules: The comma separated names of the PositionRules, all but the ServiceAreaRule by default, or
+// NoValidationRules.
+// - ServiceArea: The BoundingBox of the ServiceAreaRule, which is applied when provided.
+// - MinTimestamp, MaxTimestamp: The settings of the TimestampBoundsRule, the MaxTimestamp being now by default.
+type PositionFilterConfig struct {
+	TimestampPolicy        TimestampPolicy
+	MaxKMPerHour           float64
+	RecoveryRejections     int
+	MaxAcceleration        float64
+	MedianWindow           int
+	KalmanMeasurementNoise float64
+	KalmanProcessNoise     float64
+	RejectionLog           *RejectionLog
+	ValidationRules        string
+	ServiceArea            *BoundingBox
+	MinTimestamp           int64
+	MaxTimestamp           int64
+}
+
+// BoundingBox is an area between two latitudes and two longitudes, e.g. the service area of the rides.
+type BoundingBox struct {
+	MinLat float64
+	MinLng float64
+	MaxLat float64
+	MaxLng float64
+}
+
+func NewBoundingBox(minLat float64, minLng float64, maxLat float64, maxLng float64) *BoundingBox {
+	return &BoundingBox{
+		MinLat: minLat,
+		MinLng: minLng,
+		MaxLat: maxLat,
+		MaxLng: maxLng,
+	}
+}
+
+// Contains returns whether the coordinates are within the BoundingBox, its edges included.
+func (bb BoundingBox) Contains(lat float64, lng float64) bool {
+	return lat >= bb.MinLat && lat <= bb.MaxLat && lng >= bb.MinLng && lng <= bb.MaxLng
+}
+
+// String returns the BoundingBox as minLat,minLng,maxLat,maxLng.
+func (bb BoundingBox) String() string {
+	return fmt.Sprintf("%v,%v,%v,%v", bb.MinLat, bb.MinLng, bb.MaxLat, bb.MaxLng)
+}
+
+// FilterSummary counts the RidePositions of the filtered rides, telling the data problems of their timestamps
+// apart from the GPS noise.
+// - TimestampPolicy: The TimestampPolicy that the duplicate and out of order RidePositions are handled with.
+// - Rides, RidePositions: The number of the filtered rides and of their RidePositions.
+// - DuplicateTimestamps, DuplicateRides: The number of the RidePositions with the same timestamp as the previous
+// one, and of the rides with any.
+// - OutOfOrderTimestamps, OutOfOrderRides: The number of the RidePositions with an earlier timestamp than the
+// previous one, and of the rides with any.
+// - ValidationDropped: The number of the RidePositions dropped by the PositionValidator as invalid.
+// - TimestampDropped: The number of the RidePositions dropped by the TimestampPolicy, rejected or merged.
+// - FilterDropped: The number of the RidePositions dropped by the PositionFilter as GPS noise.
+type FilterSummary struct {
+	TimestampPolicy      TimestampPolicy
+	Rides                int
+	RidePositions        int
+	DuplicateTimestamps  int
+	DuplicateRides       int
+	OutOfOrderTimestamps int
+	OutOfOrderRides      int
+	ValidationDropped    int
+	TimestampDropped     int
+	FilterDropped        int
+}
+
+// Add returns the FilterSummary added up with the other FilterSummary.
+func (fs FilterSummary) Add(other FilterSummary) FilterSummary {
+	fs.Rides += other.Rides
+	fs.RidePositions += other.RidePositions
+	fs.DuplicateTimestamps += other.DuplicateTimestamps
+	fs.DuplicateRides += other.DuplicateRides
+	fs.OutOfOrderTimestamps += other.OutOfOrderTimestamps
+	fs.OutOfOrderRides += other.OutOfOrderRides
+	fs.ValidationDropped += other.ValidationDropped
+	fs.TimestampDropped += other.TimestampDropped
+	fs.FilterDropped += other.FilterDropped
+
+	return fs
+}
+
+// Report returns the FilterSummary as human readable lines.
+func (fs FilterSummary) Report() []string {
+	return []string{
+		fmt.Sprintf("Rides filtered: %d, with %d positions", fs.Rides, fs.RidePositions),
+		fmt.Sprintf("Duplicate timestamps: %d, in %d rides", fs.DuplicateTimestamps, fs.DuplicateRides),
+		fmt.Sprintf("Out of order timestamps: %d, in %d rides", fs.OutOfOrderTimestamps, fs.OutOfOrderRides),
+		fmt.Sprintf("Positions dropped by the validation rules: %d", fs.ValidationDropped),
+		fmt.Sprintf("Positions dropped by the %s timestamp policy: %d", fs.TimestampPolicy, fs.TimestampDropped),
+		fmt.Sprintf("Positions dropped by the position filters: %d", fs.FilterDropped),
+	}
+}
+
 type RideSegment struct {
 	RideID          int
 	RidePositions   [2]RidePosition
@@ -33,19 +213,24 @@ func NewRideSegment(
 	}
 }
 
+// RidePosition is a position of a ride.
+// - Id: The RideID of the ride.
+// - Lat, Lng, Timestamp: The coordinates of the position, and its unix timestamp.
+// - Line: The line of the position in the file it was read from, zero when not read from a file.
 type RidePosition struct {
 	Id        int
 	Lat       float64
 	Lng       float64
 	Timestamp int64
+	Line      int
 }
 
 func NewRidePosition(id int, lat, lng float64, timestamp int64) *RidePosition {
 	return &RidePosition{
-		id,
-		lat,
-		lng,
-		timestamp,
+		Id:        id,
+		Lat:       lat,
+		Lng:       lng,
+		Timestamp: timestamp,
 	}
 }
 
@@ -60,8 +245,8 @@ func Unmarshal(body []string) (*RidePosition, error) {
 		return nil, ErrorParsingRidePosition
 	}
 
-	// There should be a sanity check for lat and lng as well, but there
-	// is no information about their formats in the file input.
+	// The lat, lng and timestamp are checked by the PositionValidator of the RidePositionService,
+	// with the configured PositionRules.
 	ridePosition := NewRidePosition(
 		int(id),
 		lat,
@@ -71,3 +256,138 @@ func Unmarshal(body []string) (*RidePosition, error) {
 
 	return ridePosition, nil
 }
+
+// RejectReason is the reason that a row of the ride positions file was discarded for.
+type RejectReason string
+
+// The RejectReasons.
+// - ParseError: The row could not be unmarshalled into a RidePosition.
+// - BadTimestamp: The timestamp of the RidePosition was not after the previous one's, see the TimestampPolicy.
+// - MergedTimestamp: The RidePosition shared the timestamp of the previous one, and was merged into it.
+// - SpeedAboveLimit: The RidePosition was reached above the max speed, by the SpeedGate.
+// - AnchorOutlier: The RidePosition was kept as the anchor of the SpeedGate, until found to be the outlier.
+// - AccelerationAboveLimit: The RidePosition was reached above the max acceleration, by the AccelerationGate.
+// - CoordinatesOutOfRange, NullIsland, OutsideServiceArea, TimestampOutOfBounds: The RidePosition broke the
+// CoordinateRangeRule, the NullIslandRule, the ServiceAreaRule or the TimestampBoundsRule.
+// - InvalidPositionReason: The RidePosition broke any other PositionRule.
+const (
+	ParseError             RejectReason = "parse_error"
+	BadTimestamp           RejectReason = "bad_timestamp"
+	MergedTimestamp        RejectReason = "merged_timestamp"
+	SpeedAboveLimit        RejectReason = "speed_above_limit"
+	AnchorOutlier          RejectReason = "anchor_outlier"
+	AccelerationAboveLimit RejectReason = "acceleration_above_limit"
+	CoordinatesOutOfRange  RejectReason = "coordinates_out_of_range"
+	NullIsland             RejectReason = "null_island"
+	OutsideServiceArea     RejectReason = "outside_service_area"
+	TimestampOutOfBounds   RejectReason = "timestamp_out_of_bounds"
+	InvalidPositionReason  RejectReason = "invalid_position"
+)
+
+// hasSpeed returns whether the RidePositions discarded for the RejectReason are discarded on a segment,
+// having a speed, rather than on their own.
+func (rr RejectReason) hasSpeed() bool {
+	switch rr {
+	case ParseError, CoordinatesOutOfRange, NullIsland, OutsideServiceArea, TimestampOutOfBounds, InvalidPositionReason:
+		return false
+	default:
+		return true
+	}
+}
+
+// RejectedPosition is a discarded row of the ride positions file.
+// - Line: The line of the row in the file.
+// - Fields: The ride_id, lat, lng and timestamp fields of the row.
+// - Reason: The RejectReason that the row was discarded for.
+// - Speed: The speed in km/hour of the segment that the RidePosition was discarded on, not set on a ParseError
+// nor on a broken PositionRule.
+type RejectedPosition struct {
+	Line   int
+	Fields []string
+	Reason RejectReason
+	Speed  float64
+}
+
+// NewRejectedPosition returns the RejectedPosition of the RidePosition discarded for the reason, on a segment
+// of the speed.
+func NewRejectedPosition(ridePosition RidePosition, reason RejectReason, speed float64) *RejectedPosition {
+	return &RejectedPosition{
+		Line: ridePosition.Line,
+		Fields: []string{
+			strconv.Itoa(ridePosition.Id),
+			strconv.FormatFloat(ridePosition.Lat, 'f', -1, 64),
+			strconv.FormatFloat(ridePosition.Lng, 'f', -1, 64),
+			strconv.FormatInt(ridePosition.Timestamp, 10),
+		},
+		Reason: reason,
+		Speed:  speed,
+	}
+}
+
+// NewParseErrorRejectedPosition returns the RejectedPosition of the row in the line that could not be unmarshalled.
+func NewParseErrorRejectedPosition(line int, record []string) *RejectedPosition {
+	fields := make([]string, 4)
+	copy(fields, record)
+
+	return &RejectedPosition{
+		Line:   line,
+		Fields: fields,
+		Reason: ParseError,
+	}
+}
+
+// RejectedPositionHeader returns the names of the columns that the RejectedPosition ToStrings returns.
+func RejectedPositionHeader() []string {
+	return []string{"line", "ride_id", "lat", "lng", "timestamp", "reason", "speed"}
+}
+
+// ToStrings returns the columns of the RejectedPosition, the speed being empty on a ParseError or a broken
+// PositionRule, and +Inf or NaN on the zero elapsed time of a BadTimestamp or a MergedTimestamp.
+func (rp RejectedPosition) ToStrings() []string {
+	speed := ""
+	if rp.Reason.hasSpeed() {
+		speed = strconv.FormatFloat(rp.Speed, 'f', 2, 64)
+	}
+
+	return append(append([]string{strconv.Itoa(rp.Line)}, rp.Fields...), string(rp.Reason), speed)
+}
+
+// RejectionLog collects the RejectedPositions of the rows discarded while reading and filtering the rides. It is
+// safe to use from several goroutines, and a nil RejectionLog discards the RejectedPositions.
+type RejectionLog struct {
+	mutex             sync.Mutex
+	rejectedPositions []RejectedPosition
+}
+
+func NewRejectionLog() *RejectionLog {
+	return &RejectionLog{}
+}
+
+// Add adds the RejectedPositions to the RejectionLog.
+func (rl *RejectionLog) Add(rejectedPositions ...RejectedPosition) {
+	if rl == nil {
+		return
+	}
+
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	rl.rejectedPositions = append(rl.rejectedPositions, rejectedPositions...)
+}
+
+// RejectedPositions returns the RejectedPositions added, sorted by line.
+func (rl *RejectionLog) RejectedPositions() []RejectedPosition {
+	if rl == nil {
+		return nil
+	}
+
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	rejectedPositions := make([]RejectedPosition, len(rl.rejectedPositions))
+	copy(rejectedPositions, rl.rejectedPositions)
+	sort.SliceStable(rejectedPositions, func(i, j int) bool {
+		return rejectedPositions[i].Line < rejectedPositions[j].Line
+	})
+
+	return rejectedPositions
+}
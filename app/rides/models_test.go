@@ -45,3 +45,69 @@ func TestRidePositionUnmarshalRaiseErr(t *testing.T) {
 	}
 
 }
+
+// Tests the FilterSummary Add and Report methods.
+func TestFilterSummaryReport(t *testing.T) {
+	filterSummary := FilterSummary{TimestampPolicy: MergeTimestamps}.Add(
+		FilterSummary{
+			Rides:                2,
+			RidePositions:        40,
+			DuplicateTimestamps:  3,
+			DuplicateRides:       1,
+			OutOfOrderTimestamps: 2,
+			OutOfOrderRides:      1,
+			ValidationDropped:    2,
+			TimestampDropped:     5,
+			FilterDropped:        4,
+		},
+	).Add(FilterSummary{Rides: 1, RidePositions: 10, FilterDropped: 1})
+
+	assert.Equal(
+		t,
+		[]string{
+			"Rides filtered: 3, with 50 positions",
+			"Duplicate timestamps: 3, in 1 rides",
+			"Out of order timestamps: 2, in 1 rides",
+			"Positions dropped by the validation rules: 2",
+			"Positions dropped by the merge timestamp policy: 5",
+			"Positions dropped by the position filters: 5",
+		},
+		filterSummary.Report(),
+	)
+}
+
+// Tests the RejectedPosition ToStrings method, the speed being empty on a ParseError.
+func TestRejectedPositionToStrings(t *testing.T) {
+	ridePosition := NewRidePosition(1, 37.938598, 23.630322, 1405596152)
+	ridePosition.Line = 12
+
+	assert.Equal(
+		t,
+		[]string{"12", "1", "37.938598", "23.630322", "1405596152", "speed_above_limit", "132.46"},
+		NewRejectedPosition(*ridePosition, SpeedAboveLimit, 132.456).ToStrings(),
+	)
+	assert.Equal(
+		t,
+		[]string{"3", "1", "invalidFloat", "", "", "parse_error", ""},
+		NewParseErrorRejectedPosition(3, []string{"1", "invalidFloat"}).ToStrings(),
+	)
+	assert.Equal(t, len(RejectedPositionHeader()), len(NewParseErrorRejectedPosition(3, nil).ToStrings()))
+}
+
+// Tests the RejectionLog returns the RejectedPositions added sorted by line, and that a nil RejectionLog
+// discards them.
+func TestRejectionLogRejectedPositions(t *testing.T) {
+	rejectionLog := NewRejectionLog()
+	rejectionLog.Add(*NewParseErrorRejectedPosition(9, nil), *NewParseErrorRejectedPosition(2, nil))
+	rejectionLog.Add(*NewParseErrorRejectedPosition(5, nil))
+
+	var lines []int
+	for _, rejectedPosition := range rejectionLog.RejectedPositions() {
+		lines = append(lines, rejectedPosition.Line)
+	}
+	assert.Equal(t, []int{2, 5, 9}, lines)
+
+	var nilRejectionLog *RejectionLog
+	nilRejectionLog.Add(*NewParseErrorRejectedPosition(2, nil))
+	assert.Nil(t, nilRejectionLog.RejectedPositions())
+}
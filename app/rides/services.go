@@ -6,26 +6,42 @@ package rides
 
 import (
 	"github.com/iliaskaras/fare-estimation/app/distances"
+	"sync"
 )
 
 const (
 	HourInSeconds = 3600
-	maxKMPerHour  = 100
 )
 
 type RidePositionService struct {
 	distanceCalculator distances.DistanceCalculatorService
+	positionValidator  PositionValidator
+	timestampPolicy    TimestampPolicy
+	positionFilter     PositionFilter
+	rejectionLog       *RejectionLog
+	summaryMutex       sync.Mutex
+	summary            FilterSummary
 }
 
-func NewRidePositionService(distanceCalculator distances.DistanceCalculatorService) *RidePositionService {
+func NewRidePositionService(
+	distanceCalculator distances.DistanceCalculatorService,
+	positionValidator PositionValidator,
+	timestampPolicy TimestampPolicy,
+	positionFilter PositionFilter,
+	rejectionLog *RejectionLog,
+) *RidePositionService {
 	return &RidePositionService{
 		distanceCalculator: distanceCalculator,
+		positionValidator:  positionValidator,
+		timestampPolicy:    timestampPolicy,
+		positionFilter:     positionFilter,
+		rejectionLog:       rejectionLog,
+		summary:            FilterSummary{TimestampPolicy: timestampPolicy},
 	}
 }
 
-// FilterOnSegmentSpeed filters the erroneous RidePosition by using the segment Speed as a filter.
-// More specifically, is responsibly for filtering out the second RidePosition out of a RideSegment,
-// if the calculated Speed km/hour > 100km.
+// FilterOnSegmentSpeed filters the erroneous RidePosition by using the segment Speed as a filter,
+// with RecordRide on the RidePositions of each RideID.
 // - Receiver of the channel ridePositionsChan,
 // - Pusher to the channel the rideSegmentsChan, where all the filtered RideSegment are pushed.
 func (ss *RidePositionService) FilterOnSegmentSpeed(
@@ -35,70 +51,88 @@ func (ss *RidePositionService) FilterOnSegmentSpeed(
 
 	// Receives the RidePositions.
 	for unfilteredRidePositions := range ridePositionsChan {
-		ridePositionsSize := len(unfilteredRidePositions)
-		var filteredRideSegments []RideSegment
-
-		i := 0
-		j := 1
-		for i < ridePositionsSize {
-
-			currentRidePosition := unfilteredRidePositions[i]
-
-			if j >= ridePositionsSize {
-				// Case where we are at the end of the RidePositions,
-				// and there is nothing to evaluate the current RidePosition with.
-				break
-			}
-
-			nextRidePosition := unfilteredRidePositions[j]
-
-			// Calculate the elapsed time given the two ride position timestamps in seconds.
-			elapsedTimeSecs := nextRidePosition.Timestamp - currentRidePosition.Timestamp
-
-			// Calculate the distance covered.
-			distanceCovered := ss.distanceCalculator.GetDistance(
-				currentRidePosition.Lat,
-				currentRidePosition.Lng,
-				nextRidePosition.Lat,
-				nextRidePosition.Lng,
-			)
-
-			segmentSpeed := (distanceCovered / float64(elapsedTimeSecs)) * HourInSeconds
-
-			// Sanity check on the segmentSpeed, if is greater than the maxKMPerHour,
-			// then this means that the check failed and the second part of the
-			// segment, which is the nextRidePosition, needs to be skipped because
-			// is found to be erroneous. The skip happen by just increasing the next
-			// index j.
-			if segmentSpeed > maxKMPerHour {
-				j += 1
-				continue
-			}
-
-			// The two RidePositions are valid entries, thus:
-			// 1. We are adding the RideSegment of the current and previous RidePositions.
-			// 2. Changing the list indexes in such way that the current nextRidePosition will
-			//    become the currentRidePosition in the next loop, by changing current index
-			//    i to be equal to this loop's next index j, and the next iteration's
-			//	  next index j, to show on the immediate next item in the list.
-			filteredRideSegments = append(
-				filteredRideSegments,
-				*NewRideSegment(
-					currentRidePosition.Id,
-					[2]RidePosition{
-						currentRidePosition,
-						nextRidePosition,
-					},
-					segmentSpeed,
-					distanceCovered,
-				),
-			)
-			i = j
-			j = i + 1
-		}
+		rideSegmentsChan <- ss.RecordRide(unfilteredRidePositions)
+	}
+
+}
+
+// FilterRide filters the erroneous RidePosition of a single RideID, dropping the ones that break the service's
+// PositionValidator, handling the ones with a timestamp that is not after the previous one's with the service's
+// TimestampPolicy, and then filtering them with the service's PositionFilter, the SpeedGate by default. It returns
+// the RideSegments between each two consecutive RidePositions kept, or nil when no RideSegment is left.
+func (ss *RidePositionService) FilterRide(unfilteredRidePositions []RidePosition) []RideSegment {
+	rideSegments, _, _ := ss.filterRide(unfilteredRidePositions)
+
+	return rideSegments
+}
+
+// RecordRide filters the erroneous RidePosition of a single RideID as FilterRide does, adding the counts of
+// the ride up to the service's FilterSummary, and the RidePositions dropped to its RejectionLog, if any.
+// It is safe to call from several goroutines.
+func (ss *RidePositionService) RecordRide(unfilteredRidePositions []RidePosition) []RideSegment {
+	rideSegments, rejectedPositions, filterSummary := ss.filterRide(unfilteredRidePositions)
+	ss.rejectionLog.Add(rejectedPositions...)
 
-		rideSegmentsChan <- filteredRideSegments
+	ss.summaryMutex.Lock()
+	defer ss.summaryMutex.Unlock()
+	ss.summary = ss.summary.Add(filterSummary)
 
+	return rideSegments
+}
+
+// Summary returns the FilterSummary of the rides recorded, to be read once the filtering has returned.
+func (ss *RidePositionService) Summary() FilterSummary {
+	ss.summaryMutex.Lock()
+	defer ss.summaryMutex.Unlock()
+
+	return ss.summary
+}
+
+// filterRide returns the RideSegments of a single RideID's filtered RidePositions, along with the
+// RejectedPositions of the ones dropped and the FilterSummary of the ride.
+func (ss *RidePositionService) filterRide(
+	unfilteredRidePositions []RidePosition,
+) ([]RideSegment, []RejectedPosition, FilterSummary) {
+	validRidePositions, rejectedPositions := ss.positionValidator.Validate(unfilteredRidePositions)
+	orderedRidePositions, orderRejectedPositions, filterSummary := orderRide(
+		ss.distanceCalculator,
+		ss.timestampPolicy,
+		validRidePositions,
+	)
+	filteredRidePositions, filterRejectedPositions := ss.positionFilter.Filter(orderedRidePositions)
+	rejectedPositions = append(append(rejectedPositions, orderRejectedPositions...), filterRejectedPositions...)
+
+	// The ride is counted with all of its RidePositions, even when none of them is valid.
+	if len(unfilteredRidePositions) > 0 {
+		filterSummary.Rides = 1
+	}
+	filterSummary.RidePositions = len(unfilteredRidePositions)
+	filterSummary.ValidationDropped = len(unfilteredRidePositions) - len(validRidePositions)
+	filterSummary.FilterDropped = len(orderedRidePositions) - len(filteredRidePositions)
+
+	var filteredRideSegments []RideSegment
+	for k := 1; k < len(filteredRidePositions); k++ {
+		currentRidePosition := filteredRidePositions[k-1]
+		nextRidePosition := filteredRidePositions[k]
+
+		filteredRideSegments = append(
+			filteredRideSegments,
+			*NewRideSegment(
+				currentRidePosition.Id,
+				[2]RidePosition{
+					currentRidePosition,
+					nextRidePosition,
+				},
+				segmentSpeed(ss.distanceCalculator, currentRidePosition, nextRidePosition),
+				ss.distanceCalculator.GetDistance(
+					currentRidePosition.Lat,
+					currentRidePosition.Lng,
+					nextRidePosition.Lat,
+					nextRidePosition.Lng,
+				),
+			),
+		)
 	}
 
+	return filteredRideSegments, rejectedPositions, filterSummary
 }
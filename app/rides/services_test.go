@@ -7,6 +7,7 @@ package rides
 import (
 	"github.com/iliaskaras/fare-estimation/app/distances"
 	"github.com/stretchr/testify/assert"
+	"math"
 	"testing"
 )
 
@@ -21,6 +22,8 @@ func TestFilterOnSegmentSpeedSuccessfulExecution(t *testing.T) {
 	distanceCalculatorMethod, _ := distances.GetDistanceCalculatorService(distances.HaversineMethod)
 	ridePositionService, _ := GetRidePositionService(
 		distanceCalculatorMethod,
+		SpeedPositionFilter,
+		PositionFilterConfig{MaxKMPerHour: MaxKMPerHour, RecoveryRejections: RecoveryRejections},
 	)
 	var expectedRideSegments = [][]RideSegment{
 		{
@@ -266,3 +269,478 @@ func TestFilterOnSegmentSpeedSuccessfulExecution(t *testing.T) {
 	}
 
 }
+
+// Tests the RidePositionService.FilterOnSegmentSpeed filters on the configured maximum speed.
+func TestFilterOnSegmentSpeedWithMaxKMPerHour(t *testing.T) {
+	distanceCalculatorMethod, _ := distances.GetDistanceCalculatorService(distances.HaversineMethod)
+	ridePositions := []RidePosition{
+		{
+			Id:        3,
+			Lat:       37.926738,
+			Lng:       23.935701,
+			Timestamp: 1405591810,
+		},
+		{
+			Id:        3,
+			Lat:       37.927245,
+			Lng:       23.935000,
+			Timestamp: 1405591818,
+		},
+	}
+
+	for maxKMPerHour, expectedRideSegments := range map[float64]int{30: 0, 40: 1} {
+		ridePositionService, _ := GetRidePositionService(
+			distanceCalculatorMethod,
+			SpeedPositionFilter,
+			PositionFilterConfig{MaxKMPerHour: maxKMPerHour, RecoveryRejections: RecoveryRejections},
+		)
+		ridePositionsChan := make(chan []RidePosition)
+		rideSegmentsChan := make(chan []RideSegment)
+
+		go func() {
+			ridePositionsChan <- ridePositions
+			close(ridePositionsChan)
+		}()
+
+		go func() {
+			ridePositionService.FilterOnSegmentSpeed(
+				ridePositionsChan,
+				rideSegmentsChan,
+			)
+			close(rideSegmentsChan)
+		}()
+
+		for rideSegments := range rideSegmentsChan {
+			assert.Len(t, rideSegments, expectedRideSegments, "max km/hour: %v", maxKMPerHour)
+		}
+	}
+}
+
+// Tests the RidePositionService.FilterRide returns nil when no RideSegment is left.
+func TestFilterRideReturnNilWithoutRideSegments(t *testing.T) {
+	distanceCalculatorMethod, _ := distances.GetDistanceCalculatorService(distances.HaversineMethod)
+	ridePositionService, _ := GetRidePositionService(
+		distanceCalculatorMethod,
+		SpeedPositionFilter,
+		PositionFilterConfig{MaxKMPerHour: MaxKMPerHour, RecoveryRejections: RecoveryRejections},
+	)
+
+	assert.Nil(t, ridePositionService.FilterRide(nil))
+	assert.Nil(t, ridePositionService.FilterRide([]RidePosition{{Id: 4, Lat: 37.926738, Lng: 23.935701, Timestamp: 1405591810}}))
+	assert.Nil(
+		t,
+		ridePositionService.FilterRide(
+			[]RidePosition{
+				{Id: 5, Lat: 37.926738, Lng: 23.935701, Timestamp: 1405591810},
+				{Id: 5, Lat: 38.926738, Lng: 23.935701, Timestamp: 1405591820},
+			},
+		),
+	)
+}
+
+// athensTrace returns the RidePositions of a ride driven north at about 20km/hour in the centre of Athens,
+// one every 10 seconds, with the RidePositions of the spikes moved by their offset in degrees of latitude.
+func athensTrace(size int, spikes map[int]float64) []RidePosition {
+	ridePositions := make([]RidePosition, size)
+	for k := range ridePositions {
+		ridePositions[k] = RidePosition{
+			Id:        6,
+			Lat:       37.975500 + float64(k)*0.0005 + spikes[k],
+			Lng:       23.734900,
+			Timestamp: 1405594957 + int64(k)*10,
+		}
+	}
+
+	return ridePositions
+}
+
+// segmentTimestamps returns the Timestamps of the RidePositions of each RideSegment.
+func segmentTimestamps(rideSegments []RideSegment) [][2]int64 {
+	var timestamps [][2]int64
+	for _, rideSegment := range rideSegments {
+		timestamps = append(
+			timestamps,
+			[2]int64{rideSegment.RidePositions[0].Timestamp, rideSegment.RidePositions[1].Timestamp},
+		)
+	}
+
+	return timestamps
+}
+
+// Tests the RidePositionService.FilterRide re-anchors when the first RidePosition is a GPS teleport,
+// about 11km away from the rest of the ride.
+func TestFilterRideRecoversFromTeleportedFirstPosition(t *testing.T) {
+	distanceCalculatorMethod, _ := distances.GetDistanceCalculatorService(distances.HaversineMethod)
+	ridePositionService, _ := GetRidePositionService(
+		distanceCalculatorMethod,
+		SpeedPositionFilter,
+		PositionFilterConfig{MaxKMPerHour: MaxKMPerHour, RecoveryRejections: RecoveryRejections},
+	)
+
+	rideSegments := ridePositionService.FilterRide(athensTrace(7, map[int]float64{0: 0.1}))
+
+	assert.Equal(
+		t,
+		[][2]int64{
+			{1405594967, 1405594977},
+			{1405594977, 1405594987},
+			{1405594987, 1405594997},
+			{1405594997, 1405595007},
+			{1405595007, 1405595017},
+		},
+		segmentTimestamps(rideSegments),
+	)
+}
+
+// Tests the RidePositionService.FilterRide returns nil for a ride with a teleported first RidePosition
+// when the anchor recovery is disabled.
+func TestFilterRideWithoutRecoveryRejectsRideAfterTeleportedFirstPosition(t *testing.T) {
+	distanceCalculatorMethod, _ := distances.GetDistanceCalculatorService(distances.HaversineMethod)
+
+	for _, recoveryRejections := range []int{0, 1} {
+		ridePositionService, _ := GetRidePositionService(
+			distanceCalculatorMethod,
+			SpeedPositionFilter,
+			PositionFilterConfig{MaxKMPerHour: MaxKMPerHour, RecoveryRejections: recoveryRejections},
+		)
+
+		assert.Nil(
+			t,
+			ridePositionService.FilterRide(athensTrace(7, map[int]float64{0: 0.1})),
+			"recovery rejections: %d",
+			recoveryRejections,
+		)
+	}
+}
+
+// Tests the RidePositionService.FilterRide drops the RideSegment of a stale cold start fix, the first two
+// RidePositions being reported at the last known location of the device before the ride's actual positions.
+func TestFilterRideDropsStaleColdStartPositions(t *testing.T) {
+	distanceCalculatorMethod, _ := distances.GetDistanceCalculatorService(distances.HaversineMethod)
+	ridePositionService, _ := GetRidePositionService(
+		distanceCalculatorMethod,
+		SpeedPositionFilter,
+		PositionFilterConfig{MaxKMPerHour: MaxKMPerHour, RecoveryRejections: RecoveryRejections},
+	)
+
+	ridePositions := athensTrace(8, nil)
+	for k := 0; k < 2; k++ {
+		ridePositions[k].Lat = 38.044000
+		ridePositions[k].Lng = 23.801000
+	}
+
+	rideSegments := ridePositionService.FilterRide(ridePositions)
+
+	assert.Len(t, rideSegments, 5)
+	assert.Equal(t, [2]int64{1405594977, 1405594987}, segmentTimestamps(rideSegments)[0])
+	assert.Equal(t, [2]int64{1405595017, 1405595027}, segmentTimestamps(rideSegments)[4])
+}
+
+// Tests the RidePositionService.FilterRide drops a single spike in the middle of a ride without re-anchoring.
+func TestFilterRideDropsSingleSpike(t *testing.T) {
+	distanceCalculatorMethod, _ := distances.GetDistanceCalculatorService(distances.HaversineMethod)
+	ridePositionService, _ := GetRidePositionService(
+		distanceCalculatorMethod,
+		SpeedPositionFilter,
+		PositionFilterConfig{MaxKMPerHour: MaxKMPerHour, RecoveryRejections: RecoveryRejections},
+	)
+
+	rideSegments := ridePositionService.FilterRide(athensTrace(7, map[int]float64{3: 0.02}))
+
+	assert.Equal(
+		t,
+		[][2]int64{
+			{1405594957, 1405594967},
+			{1405594967, 1405594977},
+			{1405594977, 1405594997},
+			{1405594997, 1405595007},
+			{1405595007, 1405595017},
+		},
+		segmentTimestamps(rideSegments),
+	)
+}
+
+// Tests the RidePositionService.FilterRide drops a multipath burst of two RidePositions reflected off the same
+// building, which agree with each other but are fewer than the recovery rejections, returning to the anchor.
+func TestFilterRideDropsMultipathBurst(t *testing.T) {
+	distanceCalculatorMethod, _ := distances.GetDistanceCalculatorService(distances.HaversineMethod)
+	ridePositionService, _ := GetRidePositionService(
+		distanceCalculatorMethod,
+		SpeedPositionFilter,
+		PositionFilterConfig{MaxKMPerHour: MaxKMPerHour, RecoveryRejections: RecoveryRejections},
+	)
+
+	ridePositions := athensTrace(8, nil)
+	for k := 3; k < 5; k++ {
+		ridePositions[k].Lat = 37.981200
+		ridePositions[k].Lng = 23.742300
+	}
+
+	rideSegments := ridePositionService.FilterRide(ridePositions)
+
+	assert.Equal(
+		t,
+		[][2]int64{
+			{1405594957, 1405594967},
+			{1405594967, 1405594977},
+			{1405594977, 1405595007},
+			{1405595007, 1405595017},
+			{1405595017, 1405595027},
+		},
+		segmentTimestamps(rideSegments),
+	)
+}
+
+// Tests the RidePositionService.FilterRide keeps a current RidePosition backed by enough RideSegments, instead of
+// re-anchoring on the agreeing RidePositions that a genuine jump of the GPS, e.g. after a tunnel, is followed by.
+func TestFilterRideKeepsBackedAnchor(t *testing.T) {
+	distanceCalculatorMethod, _ := distances.GetDistanceCalculatorService(distances.HaversineMethod)
+	ridePositionService, _ := GetRidePositionService(
+		distanceCalculatorMethod,
+		SpeedPositionFilter,
+		PositionFilterConfig{MaxKMPerHour: MaxKMPerHour, RecoveryRejections: RecoveryRejections},
+	)
+
+	rideSegments := ridePositionService.FilterRide(
+		athensTrace(8, map[int]float64{4: 0.02, 5: 0.02, 6: 0.02, 7: 0.02}),
+	)
+
+	assert.Equal(
+		t,
+		[][2]int64{
+			{1405594957, 1405594967},
+			{1405594967, 1405594977},
+			{1405594977, 1405594987},
+		},
+		segmentTimestamps(rideSegments),
+	)
+}
+
+// disorderedTrace returns the RidePositions of the athensTrace with the third one sent twice, with the
+// same timestamp, and the fourth and fifth one delivered out of order.
+func disorderedTrace() []RidePosition {
+	ridePositions := athensTrace(6, nil)
+	ridePositions[2].Timestamp = ridePositions[1].Timestamp
+	ridePositions[3], ridePositions[4] = ridePositions[4], ridePositions[3]
+
+	return ridePositions
+}
+
+// Tests the RidePositionService.FilterRide handles the duplicate and out of order timestamps of a ride with
+// each TimestampPolicy, every RideSegment having a finite speed.
+func TestFilterRideWithTimestampPolicies(t *testing.T) {
+	distanceCalculatorMethod, _ := distances.GetDistanceCalculatorService(distances.HaversineMethod)
+	ridePositions := disorderedTrace()
+
+	for timestampPolicy, expectedTimestamps := range map[TimestampPolicy][][2]int64{
+		RejectTimestamps: {
+			{1405594957, 1405594967},
+			{1405594967, 1405594997},
+			{1405594997, 1405595007},
+		},
+		MergeTimestamps: {
+			{1405594957, 1405594967},
+			{1405594967, 1405594997},
+			{1405594997, 1405595007},
+		},
+		SortTimestamps: {
+			{1405594957, 1405594967},
+			{1405594967, 1405594987},
+			{1405594987, 1405594997},
+			{1405594997, 1405595007},
+		},
+	} {
+		ridePositionService, err := GetRidePositionService(
+			distanceCalculatorMethod,
+			SpeedPositionFilter,
+			PositionFilterConfig{TimestampPolicy: timestampPolicy, RecoveryRejections: RecoveryRejections},
+		)
+		assert.NoError(t, err)
+
+		rideSegments := ridePositionService.FilterRide(ridePositions)
+
+		assert.Equal(t, expectedTimestamps, segmentTimestamps(rideSegments), "timestamp policy: %s", timestampPolicy)
+		for _, rideSegment := range rideSegments {
+			assert.False(t, math.IsNaN(rideSegment.Speed) || math.IsInf(rideSegment.Speed, 0))
+		}
+	}
+	assert.Equal(t, disorderedTrace(), ridePositions, "the RidePositions are not modified")
+}
+
+// Tests the RidePositionService.FilterRide merges the coordinates of the RidePositions with the same timestamp,
+// unless rejecting them.
+func TestFilterRideMergesDuplicateTimestamps(t *testing.T) {
+	distanceCalculatorMethod, _ := distances.GetDistanceCalculatorService(distances.HaversineMethod)
+	ridePositions := disorderedTrace()
+
+	for timestampPolicy, expectedLat := range map[TimestampPolicy]float64{
+		RejectTimestamps: ridePositions[1].Lat,
+		MergeTimestamps:  (ridePositions[1].Lat + ridePositions[2].Lat) / 2,
+		SortTimestamps:   (ridePositions[1].Lat + ridePositions[2].Lat) / 2,
+	} {
+		ridePositionService, _ := GetRidePositionService(
+			distanceCalculatorMethod,
+			SpeedPositionFilter,
+			PositionFilterConfig{TimestampPolicy: timestampPolicy},
+		)
+
+		rideSegments := ridePositionService.FilterRide(ridePositions)
+
+		assert.Equal(t, expectedLat, rideSegments[0].RidePositions[1].Lat, "timestamp policy: %s", timestampPolicy)
+	}
+}
+
+// Tests the RidePositionService.FilterOnSegmentSpeed counts the duplicate and out of order timestamps, and the
+// RidePositions dropped by the TimestampPolicy and by the PositionFilter, in its FilterSummary.
+func TestFilterOnSegmentSpeedRecordsFilterSummary(t *testing.T) {
+	distanceCalculatorMethod, _ := distances.GetDistanceCalculatorService(distances.HaversineMethod)
+	ridePositionService, _ := GetRidePositionService(
+		distanceCalculatorMethod,
+		SpeedPositionFilter,
+		PositionFilterConfig{TimestampPolicy: SortTimestamps},
+	)
+	ridePositionsChan := make(chan []RidePosition)
+	rideSegmentsChan := make(chan []RideSegment)
+
+	go func() {
+		ridePositionsChan <- disorderedTrace()
+		ridePositionsChan <- athensTrace(7, map[int]float64{3: 0.02})
+		ridePositionsChan <- athensTrace(4, nil)
+		close(ridePositionsChan)
+	}()
+
+	go func() {
+		ridePositionService.FilterOnSegmentSpeed(ridePositionsChan, rideSegmentsChan)
+		close(rideSegmentsChan)
+	}()
+
+	for range rideSegmentsChan {
+	}
+
+	assert.Equal(
+		t,
+		FilterSummary{
+			TimestampPolicy:      SortTimestamps,
+			Rides:                3,
+			RidePositions:        17,
+			DuplicateTimestamps:  1,
+			DuplicateRides:       1,
+			OutOfOrderTimestamps: 1,
+			OutOfOrderRides:      1,
+			TimestampDropped:     1,
+			FilterDropped:        1,
+		},
+		ridePositionService.Summary(),
+	)
+	assert.Nil(t, ridePositionService.FilterRide(disorderedTrace()[:1]))
+	assert.Equal(t, 3, ridePositionService.Summary().Rides, "the FilterRide is not recorded")
+}
+
+// Tests the RidePositionService.RecordRide adds the RidePositions dropped by the TimestampPolicy and by the
+// PositionFilter to its RejectionLog, with the speed they were dropped on.
+func TestRecordRideAddsRejectedPositions(t *testing.T) {
+	distanceCalculatorMethod, _ := distances.GetDistanceCalculatorService(distances.HaversineMethod)
+	rejectionLog := NewRejectionLog()
+	ridePositionService, _ := GetRidePositionService(
+		distanceCalculatorMethod,
+		SpeedPositionFilter,
+		PositionFilterConfig{RejectionLog: rejectionLog},
+	)
+
+	spikedRidePositions := athensTrace(5, map[int]float64{3: 0.02})
+	ridePositions := disorderedTrace()
+	for k := range ridePositions {
+		ridePositions[k].Line = k + 2
+	}
+	for k := range spikedRidePositions {
+		spikedRidePositions[k].Line = k + 8
+	}
+
+	ridePositionService.RecordRide(spikedRidePositions)
+	ridePositionService.RecordRide(ridePositions)
+	ridePositionService.FilterRide(ridePositions)
+
+	rejectedPositions := rejectionLog.RejectedPositions()
+	assert.Len(t, rejectedPositions, 3)
+
+	assert.Equal(t, 4, rejectedPositions[0].Line)
+	assert.Equal(t, BadTimestamp, rejectedPositions[0].Reason)
+	assert.True(t, math.IsInf(rejectedPositions[0].Speed, 1))
+
+	assert.Equal(t, 6, rejectedPositions[1].Line)
+	assert.Equal(t, BadTimestamp, rejectedPositions[1].Reason)
+	assert.Less(t, rejectedPositions[1].Speed, 0.0)
+
+	assert.Equal(t, 11, rejectedPositions[2].Line)
+	assert.Equal(t, SpeedAboveLimit, rejectedPositions[2].Reason)
+	assert.Greater(t, rejectedPositions[2].Speed, float64(MaxKMPerHour))
+}
+
+// Tests the RidePositionService.RecordRide adds the RidePositions merged by the TimestampPolicy to its
+// RejectionLog apart from the ones dropped, with a reason of their own.
+func TestRecordRideAddsMergedPositions(t *testing.T) {
+	distanceCalculatorMethod, _ := distances.GetDistanceCalculatorService(distances.HaversineMethod)
+
+	for timestampPolicy, expectedReasons := range map[TimestampPolicy][]RejectReason{
+		MergeTimestamps: {MergedTimestamp, BadTimestamp},
+		SortTimestamps:  {MergedTimestamp},
+	} {
+		rejectionLog := NewRejectionLog()
+		ridePositionService, _ := GetRidePositionService(
+			distanceCalculatorMethod,
+			SpeedPositionFilter,
+			PositionFilterConfig{TimestampPolicy: timestampPolicy, RejectionLog: rejectionLog},
+		)
+
+		ridePositions := disorderedTrace()
+		for k := range ridePositions {
+			ridePositions[k].Line = k + 2
+		}
+		ridePositionService.RecordRide(ridePositions)
+
+		rejectedPositions := rejectionLog.RejectedPositions()
+		assert.Len(t, rejectedPositions, len(expectedReasons), timestampPolicy)
+		for k, expectedReason := range expectedReasons {
+			assert.Equal(t, expectedReason, rejectedPositions[k].Reason, timestampPolicy)
+		}
+		assert.Equal(t, 4, rejectedPositions[0].Line, timestampPolicy)
+	}
+}
+
+// Tests the RidePositionService.RecordRide drops the invalid RidePositions before filtering them, counting them
+// in its FilterSummary and adding them to its RejectionLog.
+func TestRecordRideDropsInvalidRidePositions(t *testing.T) {
+	distanceCalculatorMethod, _ := distances.GetDistanceCalculatorService(distances.HaversineMethod)
+	rejectionLog := NewRejectionLog()
+	ridePositionService, _ := GetRidePositionService(
+		distanceCalculatorMethod,
+		SpeedPositionFilter,
+		PositionFilterConfig{RejectionLog: rejectionLog},
+	)
+
+	ridePositions := athensTrace(5, nil)
+	ridePositions[2].Lat, ridePositions[2].Lng = 0, 0
+	for k := range ridePositions {
+		ridePositions[k].Line = k + 1
+	}
+
+	rideSegments := ridePositionService.RecordRide(ridePositions)
+	nullIslandRide := ridePositionService.RecordRide([]RidePosition{*NewRidePosition(7, 0, 0, 1405594957)})
+
+	assert.Equal(
+		t,
+		[][2]int64{{1405594957, 1405594967}, {1405594967, 1405594987}, {1405594987, 1405594997}},
+		segmentTimestamps(rideSegments),
+	)
+	assert.Nil(t, nullIslandRide)
+	assert.Equal(
+		t,
+		FilterSummary{TimestampPolicy: RejectTimestamps, Rides: 2, RidePositions: 6, ValidationDropped: 2},
+		ridePositionService.Summary(),
+	)
+
+	rejectedPositions := rejectionLog.RejectedPositions()
+	assert.Len(t, rejectedPositions, 2)
+	assert.Equal(t, NullIsland, rejectedPositions[0].Reason)
+	assert.Equal(t, NullIsland, rejectedPositions[1].Reason)
+}
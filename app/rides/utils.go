@@ -0,0 +1,190 @@
+/*
+Package rides
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package rides
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/distances"
+	"math"
+	"sort"
+)
+
+const (
+	earthMetersRadius  = 6371000
+	metersPerLatDegree = earthMetersRadius * math.Pi / 180
+)
+
+// planarOffset returns the east and the north offset in meters of the RidePosition to, from the RidePosition
+// from, on the plane tangent to the earth at the RidePosition from, which is accurate within a city.
+func planarOffset(from RidePosition, to RidePosition) (float64, float64) {
+	metersPerLngDegree := metersPerLatDegree * math.Cos(from.Lat*math.Pi/180)
+
+	return (to.Lng - from.Lng) * metersPerLngDegree, (to.Lat - from.Lat) * metersPerLatDegree
+}
+
+// planarPosition returns the latitude and the longitude of the east and the north offset in meters from the
+// RidePosition from, the inverse of the planarOffset.
+func planarPosition(from RidePosition, east float64, north float64) (float64, float64) {
+	metersPerLngDegree := metersPerLatDegree * math.Cos(from.Lat*math.Pi/180)
+
+	return from.Lat + north/metersPerLatDegree, from.Lng + east/metersPerLngDegree
+}
+
+// kalmanState is the constant velocity state of a single axis of the KalmanSmoother.
+// - position, velocity: The estimated position in meters and velocity in meters/second.
+// - covariance: The covariance of the position and the velocity.
+type kalmanState struct {
+	position   float64
+	velocity   float64
+	covariance [2][2]float64
+}
+
+// predict returns the state the elapsed time later, the velocity changing with the processVariance.
+func (ks kalmanState) predict(elapsedTimeSecs float64, processVariance float64) kalmanState {
+	dt := elapsedTimeSecs
+	p := ks.covariance
+
+	return kalmanState{
+		position: ks.position + ks.velocity*dt,
+		velocity: ks.velocity,
+		covariance: [2][2]float64{
+			{
+				p[0][0] + dt*(p[0][1]+p[1][0]) + dt*dt*p[1][1] + processVariance*dt*dt*dt*dt/4,
+				p[0][1] + dt*p[1][1] + processVariance*dt*dt*dt/2,
+			},
+			{
+				p[1][0] + dt*p[1][1] + processVariance*dt*dt*dt/2,
+				p[1][1] + processVariance*dt*dt,
+			},
+		},
+	}
+}
+
+// update returns the state corrected with the position measured with the measurementVariance.
+func (ks kalmanState) update(measurement float64, measurementVariance float64) kalmanState {
+	p := ks.covariance
+	innovationVariance := p[0][0] + measurementVariance
+	positionGain := p[0][0] / innovationVariance
+	velocityGain := p[1][0] / innovationVariance
+	innovation := measurement - ks.position
+
+	return kalmanState{
+		position: ks.position + positionGain*innovation,
+		velocity: ks.velocity + velocityGain*innovation,
+		covariance: [2][2]float64{
+			{(1 - positionGain) * p[0][0], (1 - positionGain) * p[0][1]},
+			{p[1][0] - velocityGain*p[0][0], p[1][1] - velocityGain*p[0][1]},
+		},
+	}
+}
+
+// smooth returns the filtered state corrected backwards with the smoothed state that follows it, which was
+// predicted as the predicted state the elapsed time later. The covariance is kept as filtered.
+func (ks kalmanState) smooth(smoothed kalmanState, predicted kalmanState, elapsedTimeSecs float64) kalmanState {
+	dt := elapsedTimeSecs
+	p := ks.covariance
+	pp := predicted.covariance
+
+	determinant := pp[0][0]*pp[1][1] - pp[0][1]*pp[1][0]
+	if determinant == 0 {
+		return ks
+	}
+
+	// The smoother gain is the filtered covariance times the transposed transition, times the inverse of the
+	// predicted covariance.
+	crossCovariance := [2][2]float64{
+		{p[0][0] + dt*p[0][1], p[0][1]},
+		{p[1][0] + dt*p[1][1], p[1][1]},
+	}
+	inverse := [2][2]float64{
+		{pp[1][1] / determinant, -pp[0][1] / determinant},
+		{-pp[1][0] / determinant, pp[0][0] / determinant},
+	}
+	var gain [2][2]float64
+	for r := 0; r < 2; r++ {
+		for c := 0; c < 2; c++ {
+			gain[r][c] = crossCovariance[r][0]*inverse[0][c] + crossCovariance[r][1]*inverse[1][c]
+		}
+	}
+
+	positionCorrection := smoothed.position - predicted.position
+	velocityCorrection := smoothed.velocity - predicted.velocity
+
+	return kalmanState{
+		position:   ks.position + gain[0][0]*positionCorrection + gain[0][1]*velocityCorrection,
+		velocity:   ks.velocity + gain[1][0]*positionCorrection + gain[1][1]*velocityCorrection,
+		covariance: ks.covariance,
+	}
+}
+
+// orderRide handles the RidePositions of a single RideID with a timestamp that is not after the previous one's
+// with the TimestampPolicy, returning the RidePositions kept, each with a timestamp after the previous one's,
+// the RejectedPositions of the ones dropped, with their speed from the last one kept, and the FilterSummary
+// of the ride, apart from its FilterDropped. The ridePositions are not modified.
+func orderRide(
+	distanceCalculator distances.DistanceCalculatorService,
+	timestampPolicy TimestampPolicy,
+	ridePositions []RidePosition,
+) ([]RidePosition, []RejectedPosition, FilterSummary) {
+	filterSummary := FilterSummary{TimestampPolicy: timestampPolicy, RidePositions: len(ridePositions)}
+	if len(ridePositions) == 0 {
+		return nil, nil, filterSummary
+	}
+	filterSummary.Rides = 1
+
+	for k := 1; k < len(ridePositions); k++ {
+		if ridePositions[k].Timestamp == ridePositions[k-1].Timestamp {
+			filterSummary.DuplicateTimestamps += 1
+		}
+		if ridePositions[k].Timestamp < ridePositions[k-1].Timestamp {
+			filterSummary.OutOfOrderTimestamps += 1
+		}
+	}
+	if filterSummary.DuplicateTimestamps > 0 {
+		filterSummary.DuplicateRides = 1
+	}
+	if filterSummary.OutOfOrderTimestamps > 0 {
+		filterSummary.OutOfOrderRides = 1
+	}
+
+	if timestampPolicy == SortTimestamps && filterSummary.OutOfOrderTimestamps > 0 {
+		sortedRidePositions := make([]RidePosition, len(ridePositions))
+		copy(sortedRidePositions, ridePositions)
+		sort.SliceStable(sortedRidePositions, func(i, j int) bool {
+			return sortedRidePositions[i].Timestamp < sortedRidePositions[j].Timestamp
+		})
+		ridePositions = sortedRidePositions
+	}
+
+	orderedRidePositions := []RidePosition{ridePositions[0]}
+	var rejectedPositions []RejectedPosition
+	// The number of the RidePositions merged into the last one kept, including itself.
+	merged := 1
+
+	for _, ridePosition := range ridePositions[1:] {
+		last := &orderedRidePositions[len(orderedRidePositions)-1]
+
+		if ridePosition.Timestamp > last.Timestamp {
+			orderedRidePositions = append(orderedRidePositions, ridePosition)
+			merged = 1
+			continue
+		}
+
+		// The RidePosition is dropped, after its coordinates are averaged into the last one kept when merging.
+		reason := BadTimestamp
+		speed := segmentSpeed(distanceCalculator, *last, ridePosition)
+		if ridePosition.Timestamp == last.Timestamp && timestampPolicy != RejectTimestamps {
+			reason = MergedTimestamp
+			last.Lat = (last.Lat*float64(merged) + ridePosition.Lat) / float64(merged+1)
+			last.Lng = (last.Lng*float64(merged) + ridePosition.Lng) / float64(merged+1)
+			merged += 1
+		}
+
+		rejectedPositions = append(rejectedPositions, *NewRejectedPosition(ridePosition, reason, speed))
+	}
+
+	filterSummary.TimestampDropped = len(ridePositions) - len(orderedRidePositions)
+
+	return orderedRidePositions, rejectedPositions, filterSummary
+}
@@ -0,0 +1,168 @@
+/*
+Package rides
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package rides
+
+import (
+	"fmt"
+	"math"
+)
+
+// PositionRule is a validation rule of a RidePosition, returning the error of its own type when the
+// RidePosition breaks it, wrapping the InvalidPosition.
+type PositionRule interface {
+	Validate(ridePosition RidePosition) error
+}
+
+// PositionValidator validates the RidePositions of a single RideID with its PositionRules, before they are
+// ordered and filtered, dropping the RidePositions that break any of them.
+type PositionValidator []PositionRule
+
+// Validate returns the valid RidePositions, in order, along with the RejectedPositions of the invalid ones,
+// with the RejectReason of the first PositionRule that each one breaks.
+func (pv PositionValidator) Validate(ridePositions []RidePosition) ([]RidePosition, []RejectedPosition) {
+	if len(pv) == 0 {
+		return ridePositions, nil
+	}
+
+	var validRidePositions []RidePosition
+	var rejectedPositions []RejectedPosition
+
+	for _, ridePosition := range ridePositions {
+		if err := pv.validate(ridePosition); err != nil {
+			rejectedPositions = append(
+				rejectedPositions,
+				*NewRejectedPosition(ridePosition, validationRejectReason(err), 0),
+			)
+			continue
+		}
+		validRidePositions = append(validRidePositions, ridePosition)
+	}
+
+	return validRidePositions, rejectedPositions
+}
+
+// validate returns the error of the first PositionRule that the RidePosition breaks, if any.
+func (pv PositionValidator) validate(ridePosition RidePosition) error {
+	for _, positionRule := range pv {
+		if err := positionRule.Validate(ridePosition); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CoordinateRangeRule is the PositionRule that the latitude of a RidePosition must be within ±90 degrees
+// and its longitude within ±180 degrees, which the coordinates that are not a number are not.
+type CoordinateRangeRule struct{}
+
+func NewCoordinateRangeRule() *CoordinateRangeRule {
+	return &CoordinateRangeRule{}
+}
+
+func (cr *CoordinateRangeRule) Validate(ridePosition RidePosition) error {
+	if !(math.Abs(ridePosition.Lat) <= 90) || !(math.Abs(ridePosition.Lng) <= 180) {
+		return NewCoordinateRangeError(
+			InvalidPosition,
+			fmt.Sprintf(
+				"lat: %v and lng: %v must be within -90 and 90, and -180 and 180",
+				ridePosition.Lat,
+				ridePosition.Lng,
+			),
+		)
+	}
+
+	return nil
+}
+
+// NullIslandRule is the PositionRule that a RidePosition must not be at the null island, the 0,0 coordinates
+// that the GPS receivers report when they have no fix.
+type NullIslandRule struct{}
+
+func NewNullIslandRule() *NullIslandRule {
+	return &NullIslandRule{}
+}
+
+func (ni *NullIslandRule) Validate(ridePosition RidePosition) error {
+	if ridePosition.Lat == 0 && ridePosition.Lng == 0 {
+		return NewNullIslandError(InvalidPosition, "lat: 0 and lng: 0 is the null island")
+	}
+
+	return nil
+}
+
+// ServiceAreaRule is the PositionRule that a RidePosition must be within the BoundingBox of the service area.
+type ServiceAreaRule struct {
+	serviceArea BoundingBox
+}
+
+func NewServiceAreaRule(serviceArea BoundingBox) *ServiceAreaRule {
+	return &ServiceAreaRule{
+		serviceArea: serviceArea,
+	}
+}
+
+func (sa *ServiceAreaRule) Validate(ridePosition RidePosition) error {
+	if !sa.serviceArea.Contains(ridePosition.Lat, ridePosition.Lng) {
+		return NewServiceAreaError(
+			InvalidPosition,
+			fmt.Sprintf(
+				"lat: %v and lng: %v must be within the service area: %s",
+				ridePosition.Lat,
+				ridePosition.Lng,
+				sa.serviceArea,
+			),
+		)
+	}
+
+	return nil
+}
+
+// TimestampBoundsRule is the PositionRule that the timestamp of a RidePosition must be within the
+// minTimestamp and the maxTimestamp, neither before the GPS data can be from nor in the future.
+type TimestampBoundsRule struct {
+	minTimestamp int64
+	maxTimestamp int64
+}
+
+func NewTimestampBoundsRule(minTimestamp int64, maxTimestamp int64) *TimestampBoundsRule {
+	return &TimestampBoundsRule{
+		minTimestamp: minTimestamp,
+		maxTimestamp: maxTimestamp,
+	}
+}
+
+func (tb *TimestampBoundsRule) Validate(ridePosition RidePosition) error {
+	if ridePosition.Timestamp < tb.minTimestamp || ridePosition.Timestamp > tb.maxTimestamp {
+		return NewTimestampBoundsError(
+			InvalidPosition,
+			fmt.Sprintf(
+				"timestamp: %d must be within %d and %d",
+				ridePosition.Timestamp,
+				tb.minTimestamp,
+				tb.maxTimestamp,
+			),
+		)
+	}
+
+	return nil
+}
+
+// validationRejectReason returns the RejectReason of the error of a PositionRule, the InvalidPositionReason
+// for the rules without a RejectReason of their own.
+func validationRejectReason(err error) RejectReason {
+	switch err.(type) {
+	case CoordinateRangeError:
+		return CoordinatesOutOfRange
+	case NullIslandError:
+		return NullIsland
+	case ServiceAreaError:
+		return OutsideServiceArea
+	case TimestampBoundsError:
+		return TimestampOutOfBounds
+	default:
+		return InvalidPositionReason
+	}
+}
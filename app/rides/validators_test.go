@@ -0,0 +1,119 @@
+/*
+Package rides
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package rides
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+// Tests each PositionRule returns the error of its own type, wrapping the InvalidPosition, only for the
+// RidePositions that break it.
+func TestPositionRulesValidate(t *testing.T) {
+	validRidePosition := *NewRidePosition(1, 37.938598, 23.630322, 1405596152)
+
+	for _, test := range []struct {
+		positionRule        PositionRule
+		invalidRidePosition RidePosition
+		expectedErrorType   error
+	}{
+		{NewCoordinateRangeRule(), *NewRidePosition(1, 91, 23.630322, 1405596152), CoordinateRangeError{}},
+		{NewCoordinateRangeRule(), *NewRidePosition(1, 37.938598, -180.5, 1405596152), CoordinateRangeError{}},
+		{NewCoordinateRangeRule(), *NewRidePosition(1, math.NaN(), 23.630322, 1405596152), CoordinateRangeError{}},
+		{NewNullIslandRule(), *NewRidePosition(1, 0, 0, 1405596152), NullIslandError{}},
+		{
+			NewServiceAreaRule(*NewBoundingBox(37.8, 23.5, 38.2, 24.1)),
+			*NewRidePosition(1, 40.640063, 22.944419, 1405596152),
+			ServiceAreaError{},
+		},
+		{
+			NewTimestampBoundsRule(MinTimestamp, 1700000000),
+			*NewRidePosition(1, 37.938598, 23.630322, 0),
+			TimestampBoundsError{},
+		},
+		{
+			NewTimestampBoundsRule(MinTimestamp, 1700000000),
+			*NewRidePosition(1, 37.938598, 23.630322, 1700000001),
+			TimestampBoundsError{},
+		},
+	} {
+		assert.NoError(t, test.positionRule.Validate(validRidePosition))
+
+		err := test.positionRule.Validate(test.invalidRidePosition)
+		assert.IsType(t, test.expectedErrorType, err, "ride position: %+v", test.invalidRidePosition)
+		assert.True(t, errors.Is(err, InvalidPosition))
+		assert.True(t, errors.Is(err, InvalidLPosition))
+	}
+	assert.NoError(t, NewNullIslandRule().Validate(*NewRidePosition(1, 0, 23.630322, 1405596152)))
+}
+
+// Tests the PositionValidator drops the RidePositions that break any of its PositionRules, rejecting them with
+// the RejectReason of the first one broken, and keeps every RidePosition without PositionRules.
+func TestPositionValidatorValidate(t *testing.T) {
+	positionValidator := PositionValidator{
+		NewCoordinateRangeRule(),
+		NewNullIslandRule(),
+		NewTimestampBoundsRule(MinTimestamp, 1700000000),
+	}
+	ridePositions := athensTrace(5, nil)
+	ridePositions[1].Lat, ridePositions[1].Lng = 0, 0
+	ridePositions[2].Lat = 137.9755
+	ridePositions[3].Timestamp = 1800000000
+	for k := range ridePositions {
+		ridePositions[k].Line = k + 1
+	}
+
+	validRidePositions, rejectedPositions := positionValidator.Validate(ridePositions)
+
+	assert.Equal(t, []RidePosition{ridePositions[0], ridePositions[4]}, validRidePositions)
+	assert.Equal(
+		t,
+		[]RejectedPosition{
+			*NewRejectedPosition(ridePositions[1], NullIsland, 0),
+			*NewRejectedPosition(ridePositions[2], CoordinatesOutOfRange, 0),
+			*NewRejectedPosition(ridePositions[3], TimestampOutOfBounds, 0),
+		},
+		rejectedPositions,
+	)
+	assert.Equal(t, "", rejectedPositions[0].ToStrings()[6])
+
+	validRidePositions, rejectedPositions = PositionValidator{}.Validate(ridePositions)
+	assert.Equal(t, ridePositions, validRidePositions)
+	assert.Nil(t, rejectedPositions)
+}
+
+// bannedTimestampRule is a PositionRule without a RejectReason of its own, breaking on a single timestamp.
+type bannedTimestampRule struct {
+	timestamp int64
+}
+
+func (br bannedTimestampRule) Validate(ridePosition RidePosition) error {
+	if ridePosition.Timestamp == br.timestamp {
+		return fmt.Errorf("timestamp: %d is banned: %w", ridePosition.Timestamp, InvalidPosition)
+	}
+
+	return nil
+}
+
+// Tests the PositionValidator rejects the RidePositions breaking a PositionRule without a RejectReason of its
+// own with the InvalidPositionReason.
+func TestPositionValidatorValidateWithCustomRule(t *testing.T) {
+	ridePositions := athensTrace(2, nil)
+	positionValidator := PositionValidator{bannedTimestampRule{timestamp: ridePositions[1].Timestamp}}
+
+	validRidePositions, rejectedPositions := positionValidator.Validate(ridePositions)
+
+	assert.Equal(t, []RidePosition{ridePositions[0]}, validRidePositions)
+	assert.Equal(
+		t,
+		[]RejectedPosition{*NewRejectedPosition(ridePositions[1], InvalidPositionReason, 0)},
+		rejectedPositions,
+	)
+	assert.Equal(t, "invalid_position", rejectedPositions[0].ToStrings()[5])
+	assert.Equal(t, "", rejectedPositions[0].ToStrings()[6])
+}
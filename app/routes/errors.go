@@ -0,0 +1,26 @@
+/*
+Package routes
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package routes
+
+import (
+	"errors"
+	baseAppErrors "github.com/iliaskaras/fare-estimation/app/infrastructure/errors"
+)
+
+type RouteError struct {
+	baseAppErrors.BaseAppError
+}
+
+func NewRouteError(err error, additionalInfo string) RouteError {
+	return RouteError{
+		BaseAppError: baseAppErrors.NewBaseAppError(err, additionalInfo),
+	}
+}
+
+var (
+	MissingRouteZones = errors.New("missing route zones")
+	UnknownRouteZone  = errors.New("unknown route zone")
+	InvalidRoutePrice = errors.New("invalid route price")
+)
@@ -0,0 +1,20 @@
+/*
+Package routes
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package routes
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/infrastructure/configs"
+)
+
+// GetFixedRouteService is responsible for initializing and injecting all the dependencies
+// of the FixedRouteService, based on the fixed routes file type provided.
+func GetFixedRouteService(filePath string) (*FixedRouteService, error) {
+	configDecoder, err := configs.GetConfigDecoder(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewFixedRouteService(configDecoder), nil
+}
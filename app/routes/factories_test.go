@@ -0,0 +1,29 @@
+/*
+Package routes
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package routes
+
+import (
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+// Tests the GetFixedRouteService return the FixedRouteService for the .yaml, .yml and .json files.
+func TestGetFixedRouteService(t *testing.T) {
+	for _, filePath := range []string{"routes.yaml", "routes.yml", "routes.json"} {
+		fixedRouteService, err := GetFixedRouteService(filePath)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "*routes.FixedRouteService", reflect.TypeOf(fixedRouteService).String())
+	}
+}
+
+// Tests the GetFixedRouteService return an error when the fixed routes file type is unsupported.
+func TestGetFixedRouteServiceReturnErrorWhenFileTypeIsInvalid(t *testing.T) {
+	fixedRouteService, err := GetFixedRouteService("routes.csv")
+	assert.Error(t, err)
+
+	assert.Nil(t, fixedRouteService)
+}
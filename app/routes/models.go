@@ -0,0 +1,36 @@
+/*
+Package routes
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package routes
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/zones"
+)
+
+// FixedRoute is a route charged with a fixed Price instead of the metered fare, e.g. an airport flat rate.
+// A ride follows the route when it starts in the Origin zone and ends in the Destination zone, or the
+// other way around when the route applies to BothDirections.
+type FixedRoute struct {
+	Name           string
+	Origin         zones.Zone
+	Destination    zones.Zone
+	Price          float64
+	BothDirections bool
+}
+
+func NewFixedRoute(
+	name string,
+	origin zones.Zone,
+	destination zones.Zone,
+	price float64,
+	bothDirections bool,
+) *FixedRoute {
+	return &FixedRoute{
+		name,
+		origin,
+		destination,
+		price,
+		bothDirections,
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Package routes
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package routes
+
+import (
+	"fmt"
+	"github.com/iliaskaras/fare-estimation/app/infrastructure/configs"
+	"github.com/iliaskaras/fare-estimation/app/rides"
+	"github.com/iliaskaras/fare-estimation/app/zones"
+	"path/filepath"
+)
+
+// fixedRoutesDefinition is the fixed routes file, referring to the zones of a GeoJSON file by their name.
+type fixedRoutesDefinition struct {
+	Zones  string                 `json:"zones" yaml:"zones"`
+	Routes []fixedRouteDefinition `json:"routes" yaml:"routes"`
+}
+
+type fixedRouteDefinition struct {
+	Name           string   `json:"name" yaml:"name"`
+	Origin         string   `json:"origin" yaml:"origin"`
+	Destination    string   `json:"destination" yaml:"destination"`
+	Price          *float64 `json:"price" yaml:"price"`
+	BothDirections bool     `json:"both_directions" yaml:"both_directions"`
+}
+
+type FixedRouteService struct {
+	configDecoder configs.ConfigDecoder
+}
+
+func NewFixedRouteService(configDecoder configs.ConfigDecoder) *FixedRouteService {
+	return &FixedRouteService{
+		configDecoder: configDecoder,
+	}
+}
+
+// Load reads the fixed routes file found in filePath, along with the GeoJSON zones file that it
+// points to, and returns its FixedRoute. A relative zones file path is resolved against the
+// directory of the fixed routes file. Every route must refer to known zones and have a price.
+func (fs *FixedRouteService) Load(filePath string) ([]FixedRoute, error) {
+	var definition fixedRoutesDefinition
+
+	if err := fs.configDecoder.Decode(filePath, &definition); err != nil {
+		return nil, err
+	}
+
+	if definition.Zones == "" {
+		return nil, NewRouteError(MissingRouteZones, "the fixed routes file must point to a zones file")
+	}
+
+	zonesPath := definition.Zones
+	if !filepath.IsAbs(zonesPath) {
+		zonesPath = filepath.Join(filepath.Dir(filePath), zonesPath)
+	}
+
+	zoneService, err := zones.GetZoneService(zonesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	loadedZones, err := zoneService.Load(zonesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	zonesByName := make(map[string]zones.Zone)
+	for _, zone := range loadedZones {
+		zonesByName[zone.Name] = zone
+	}
+
+	var fixedRoutes []FixedRoute
+
+	for _, route := range definition.Routes {
+		origin, ok := zonesByName[route.Origin]
+		if !ok {
+			return nil, NewRouteError(UnknownRouteZone, "route: "+route.Name+" origin zone: "+route.Origin+" is unknown")
+		}
+
+		destination, ok := zonesByName[route.Destination]
+		if !ok {
+			return nil, NewRouteError(
+				UnknownRouteZone,
+				"route: "+route.Name+" destination zone: "+route.Destination+" is unknown",
+			)
+		}
+
+		if route.Price == nil || *route.Price < 0 {
+			return nil, NewRouteError(
+				InvalidRoutePrice,
+				fmt.Sprintf("route: %s must have a price that is not negative", route.Name),
+			)
+		}
+
+		fixedRoutes = append(
+			fixedRoutes,
+			*NewFixedRoute(route.Name, origin, destination, *route.Price, route.BothDirections),
+		)
+	}
+
+	return fixedRoutes, nil
+}
+
+// Match returns the first of the fixedRoutes that the ride follows, recognized by the first and the
+// last position of its filtered RideSegments, or nil if the ride follows none of them.
+func Match(fixedRoutes []FixedRoute, rideSegments []rides.RideSegment) *FixedRoute {
+	if len(rideSegments) == 0 {
+		return nil
+	}
+
+	first := rideSegments[0].RidePositions[0]
+	last := rideSegments[len(rideSegments)-1].RidePositions[1]
+
+	for i, fixedRoute := range fixedRoutes {
+		if fixedRoute.Origin.Contains(first.Lat, first.Lng) &&
+			fixedRoute.Destination.Contains(last.Lat, last.Lng) {
+			return &fixedRoutes[i]
+		}
+
+		if fixedRoute.BothDirections &&
+			fixedRoute.Destination.Contains(first.Lat, first.Lng) &&
+			fixedRoute.Origin.Contains(last.Lat, last.Lng) {
+			return &fixedRoutes[i]
+		}
+	}
+
+	return nil
+}
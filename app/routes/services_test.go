@@ -0,0 +1,183 @@
+/*
+Package routes
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package routes
+
+import (
+	"github.com/Flaque/filet"
+	"github.com/iliaskaras/fare-estimation/app/rides"
+	"github.com/iliaskaras/fare-estimation/app/zones"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testZones = `{
+	"type": "FeatureCollection",
+	"features": [
+		{
+			"type": "Feature",
+			"properties": {"name": "airport"},
+			"geometry": {
+				"type": "Polygon",
+				"coordinates": [[[23.93, 37.92], [23.96, 37.92], [23.96, 37.95], [23.93, 37.95], [23.93, 37.92]]]
+			}
+		},
+		{
+			"type": "Feature",
+			"properties": {"name": "centre"},
+			"geometry": {
+				"type": "Polygon",
+				"coordinates": [[[23.70, 37.95], [23.76, 37.95], [23.76, 38.00], [23.70, 38.00], [23.70, 37.95]]]
+			}
+		}
+	]
+}`
+
+// writeRoutesFiles creates a temporary zones file and a fixed routes file with the given content
+// next to it, returning the fixed routes file path.
+func writeRoutesFiles(t *testing.T, content string) string {
+	dir := filet.TmpDir(t, "")
+	if err := os.WriteFile(filepath.Join(dir, "zones.geojson"), []byte(testZones), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	filePath := filepath.Join(dir, "routes.yaml")
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return filePath
+}
+
+// Tests the FixedRouteService.Load loads the FixedRoute of a fixed routes file, resolving its zones.
+func TestFixedRouteServiceLoadSuccessfulExecution(t *testing.T) {
+	defer filet.CleanUp(t)
+	filePath := writeRoutesFiles(
+		t,
+		"zones: zones.geojson\n"+
+			"routes:\n"+
+			"  - name: airport-centre\n"+
+			"    origin: airport\n"+
+			"    destination: centre\n"+
+			"    price: 40\n"+
+			"    both_directions: true\n",
+	)
+
+	fixedRoutes, err := mustGetFixedRouteService(t).Load(filePath)
+	assert.NoError(t, err)
+
+	assert.Len(t, fixedRoutes, 1)
+	assert.Equal(t, "airport-centre", fixedRoutes[0].Name)
+	assert.Equal(t, "airport", fixedRoutes[0].Origin.Name)
+	assert.Equal(t, "centre", fixedRoutes[0].Destination.Name)
+	assert.Equal(t, 40.0, fixedRoutes[0].Price)
+	assert.Equal(t, true, fixedRoutes[0].BothDirections)
+}
+
+// Tests the FixedRouteService.Load returns a RouteError on invalid fixed routes.
+func TestFixedRouteServiceLoadReturnRouteErrorOnInvalidRoutes(t *testing.T) {
+	defer filet.CleanUp(t)
+
+	testCases := []struct {
+		content       string
+		expectedError RouteError
+	}{
+		{
+			content: "routes:\n" +
+				"  - name: airport-centre\n" +
+				"    origin: airport\n" +
+				"    destination: centre\n" +
+				"    price: 40\n",
+			expectedError: NewRouteError(MissingRouteZones, "the fixed routes file must point to a zones file"),
+		},
+		{
+			content: "zones: zones.geojson\n" +
+				"routes:\n" +
+				"  - name: port-centre\n" +
+				"    origin: port\n" +
+				"    destination: centre\n" +
+				"    price: 30\n",
+			expectedError: NewRouteError(UnknownRouteZone, "route: port-centre origin zone: port is unknown"),
+		},
+		{
+			content: "zones: zones.geojson\n" +
+				"routes:\n" +
+				"  - name: airport-port\n" +
+				"    origin: airport\n" +
+				"    destination: port\n" +
+				"    price: 30\n",
+			expectedError: NewRouteError(UnknownRouteZone, "route: airport-port destination zone: port is unknown"),
+		},
+		{
+			content: "zones: zones.geojson\n" +
+				"routes:\n" +
+				"  - name: airport-centre\n" +
+				"    origin: airport\n" +
+				"    destination: centre\n",
+			expectedError: NewRouteError(InvalidRoutePrice, "route: airport-centre must have a price that is not negative"),
+		},
+		{
+			content: "zones: zones.geojson\n" +
+				"routes:\n" +
+				"  - name: airport-centre\n" +
+				"    origin: airport\n" +
+				"    destination: centre\n" +
+				"    price: -40\n",
+			expectedError: NewRouteError(InvalidRoutePrice, "route: airport-centre must have a price that is not negative"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		fixedRoutes, err := mustGetFixedRouteService(t).Load(writeRoutesFiles(t, testCase.content))
+		assert.Error(t, err)
+
+		assert.Nil(t, fixedRoutes)
+		assert.Equal(t, testCase.expectedError, err)
+	}
+}
+
+// Tests the Match returns the FixedRoute that the first and the last ride positions follow.
+func TestMatch(t *testing.T) {
+	airport := *zones.NewZone(
+		"airport",
+		[]zones.Polygon{{{{Lat: 37.92, Lng: 23.93}, {Lat: 37.92, Lng: 23.96}, {Lat: 37.95, Lng: 23.96}, {Lat: 37.92, Lng: 23.93}}}},
+		nil,
+	)
+	centre := *zones.NewZone(
+		"centre",
+		[]zones.Polygon{{{{Lat: 37.95, Lng: 23.70}, {Lat: 37.95, Lng: 23.76}, {Lat: 38.00, Lng: 23.76}, {Lat: 37.95, Lng: 23.70}}}},
+		nil,
+	)
+	oneWay := []FixedRoute{*NewFixedRoute("airport-centre", airport, centre, 40, false)}
+	bothWays := []FixedRoute{*NewFixedRoute("airport-centre", airport, centre, 40, true)}
+
+	airportPosition := rides.RidePosition{Id: 1, Lat: 37.936, Lng: 23.947}
+	centrePosition := rides.RidePosition{Id: 1, Lat: 37.975, Lng: 23.735}
+	elsewherePosition := rides.RidePosition{Id: 1, Lat: 38.050, Lng: 23.800}
+
+	segments := func(first rides.RidePosition, last rides.RidePosition) []rides.RideSegment {
+		return []rides.RideSegment{
+			{RideID: 1, RidePositions: [2]rides.RidePosition{first, elsewherePosition}},
+			{RideID: 1, RidePositions: [2]rides.RidePosition{elsewherePosition, last}},
+		}
+	}
+
+	assert.Equal(t, &oneWay[0], Match(oneWay, segments(airportPosition, centrePosition)))
+	assert.Nil(t, Match(oneWay, segments(centrePosition, airportPosition)))
+	assert.Equal(t, &bothWays[0], Match(bothWays, segments(centrePosition, airportPosition)))
+	assert.Nil(t, Match(bothWays, segments(airportPosition, elsewherePosition)))
+	assert.Nil(t, Match(bothWays, nil))
+}
+
+// mustGetFixedRouteService returns the FixedRouteService of the .yaml fixed routes files.
+func mustGetFixedRouteService(t *testing.T) *FixedRouteService {
+	fixedRouteService, err := GetFixedRouteService("routes.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return fixedRouteService
+}
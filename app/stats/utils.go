@@ -0,0 +1,23 @@
+/*
+Package stats
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package stats
+
+import (
+	"math"
+)
+
+// NearestRank returns the index of the percentile, out of a count of sorted values, with the nearest rank method.
+// The index is clamped to the first and the last of the values.
+func NearestRank(percentile float64, count int) int {
+	rank := int(math.Ceil(percentile / 100 * float64(count)))
+	if rank < 1 {
+		return 0
+	}
+	if rank > count {
+		return count - 1
+	}
+
+	return rank - 1
+}
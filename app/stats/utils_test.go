@@ -0,0 +1,22 @@
+/*
+Package stats
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package stats
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// Tests the NearestRank returns the index of the percentile with the nearest rank method.
+func TestNearestRank(t *testing.T) {
+	assert.Equal(t, 0, NearestRank(0, 10))
+	assert.Equal(t, 0, NearestRank(5, 10))
+	assert.Equal(t, 0, NearestRank(10, 10))
+	assert.Equal(t, 1, NearestRank(11, 10))
+	assert.Equal(t, 4, NearestRank(50, 10))
+	assert.Equal(t, 9, NearestRank(95, 10))
+	assert.Equal(t, 9, NearestRank(100, 10))
+	assert.Equal(t, 0, NearestRank(50, 1))
+}
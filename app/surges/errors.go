@@ -0,0 +1,28 @@
+/*
+Package surges
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package surges
+
+import (
+	"errors"
+	baseAppErrors "github.com/iliaskaras/fare-estimation/app/infrastructure/errors"
+)
+
+type SurgeError struct {
+	baseAppErrors.BaseAppError
+}
+
+func NewSurgeError(err error, additionalInfo string) SurgeError {
+	return SurgeError{
+		BaseAppError: baseAppErrors.NewBaseAppError(err, additionalInfo),
+	}
+}
+
+var (
+	UnsupportedSurgeMode = errors.New("unsupported surge mode")
+	InvalidSurgeCap      = errors.New("invalid surge cap")
+	InvalidSurgeRule     = errors.New("invalid surge rule")
+	InvalidSurgeWindow   = errors.New("invalid surge window")
+	UnknownSurgeZone     = errors.New("unknown surge zone")
+)
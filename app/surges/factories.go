@@ -0,0 +1,20 @@
+/*
+Package surges
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package surges
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/infrastructure/configs"
+)
+
+// GetSurgeService is responsible for initializing and injecting all the dependencies
+// of the SurgeService, based on the surge schedule file type provided.
+func GetSurgeService(filePath string) (*SurgeService, error) {
+	configDecoder, err := configs.GetConfigDecoder(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSurgeService(configDecoder), nil
+}
@@ -0,0 +1,31 @@
+/*
+Package surges
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package surges
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/infrastructure/configs"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+// Tests the GetSurgeService return the SurgeService for the .yaml, .yml and .json files.
+func TestGetSurgeService(t *testing.T) {
+	for _, filePath := range []string{"surge.yaml", "surge.yml", "surge.json"} {
+		surgeService, err := GetSurgeService(filePath)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "*surges.SurgeService", reflect.TypeOf(surgeService).String())
+	}
+}
+
+// Tests the GetSurgeService return a ConfigError when the surge schedule file type is not supported.
+func TestGetSurgeServiceReturnErrorWhenFileTypeIsInvalid(t *testing.T) {
+	surgeService, err := GetSurgeService("surge.txt")
+	assert.Error(t, err)
+
+	assert.Nil(t, surgeService)
+	assert.IsType(t, configs.ConfigError{}, err)
+}
@@ -0,0 +1,172 @@
+/*
+Package surges
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package surges
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/zones"
+	"math"
+	"time"
+)
+
+// SurgeMode is the part of a ride's fare that the surge multiplier is applied on.
+type SurgeMode string
+
+const (
+	// MeteredSurge applies the multiplier of each RideSegment, found at its start position, on its metered amount.
+	MeteredSurge SurgeMode = "metered"
+	// RideSurge applies the multiplier found at the ride's first position on its whole fare, apart from the tolls.
+	RideSurge SurgeMode = "ride"
+)
+
+// SurgeWindow is a time window of the week that a SurgeRule applies in.
+// - Days: The days of the week that the window starts on, every day when empty.
+// - FromMinute, ToMinute: The minutes of the day that the window starts and ends at, the window
+// wrapping past midnight when it ends before it starts, and lasting the whole day when they are equal.
+type SurgeWindow struct {
+	Days       []time.Weekday
+	FromMinute int
+	ToMinute   int
+}
+
+func NewSurgeWindow(days []time.Weekday, fromMinute int, toMinute int) *SurgeWindow {
+	return &SurgeWindow{
+		Days:       days,
+		FromMinute: fromMinute,
+		ToMinute:   toMinute,
+	}
+}
+
+// Contains returns whether the local time falls in the SurgeWindow. The part of a window wrapping past
+// midnight belongs to the day that the window starts on.
+func (sw SurgeWindow) Contains(local time.Time) bool {
+	minute := local.Hour()*60 + local.Minute()
+	weekday := local.Weekday()
+
+	switch {
+	case sw.FromMinute == sw.ToMinute:
+	case sw.FromMinute < sw.ToMinute:
+		if minute < sw.FromMinute || minute >= sw.ToMinute {
+			return false
+		}
+	case minute >= sw.FromMinute:
+	case minute < sw.ToMinute:
+		weekday = (weekday + 6) % 7
+	default:
+		return false
+	}
+
+	if len(sw.Days) == 0 {
+		return true
+	}
+	for _, day := range sw.Days {
+		if day == weekday {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GridCell is a cell of the SurgeSchedule's grid, the cells being CellSizeDeg degrees of latitude
+// and longitude wide, counted from the origin.
+type GridCell struct {
+	Row int64
+	Col int64
+}
+
+// CellAt returns the GridCell of the grid of cellSizeDeg wide cells that the position falls in.
+func CellAt(cellSizeDeg float64, lat, lng float64) GridCell {
+	return GridCell{
+		Row: int64(math.Floor(lat / cellSizeDeg)),
+		Col: int64(math.Floor(lng / cellSizeDeg)),
+	}
+}
+
+// SurgeRule is a surge multiplier applying in a SurgeWindow, either in a zone, in a GridCell, or
+// everywhere when it has neither.
+type SurgeRule struct {
+	Name       string
+	Window     SurgeWindow
+	Zone       *zones.Zone
+	Cell       *GridCell
+	Multiplier float64
+}
+
+func NewSurgeRule(
+	name string,
+	window SurgeWindow,
+	zone *zones.Zone,
+	cell *GridCell,
+	multiplier float64,
+) *SurgeRule {
+	return &SurgeRule{
+		Name:       name,
+		Window:     window,
+		Zone:       zone,
+		Cell:       cell,
+		Multiplier: multiplier,
+	}
+}
+
+// SurgeSchedule is the schedule of the surge multipliers, by time window and area.
+// - Mode: The part of the ride's fare that the multiplier is applied on.
+// - Location: The timezone that the SurgeWindows are decided in.
+// - CellSizeDeg: The size in degrees of the cells of the grid that the SurgeRules may refer to.
+// - MaxMultiplier: The highest multiplier applied, zero when the multipliers are not capped.
+// - MaxSurgeAmount: The most that the surge adds to a ride's fare, zero when it is not capped.
+// - Rules: The SurgeRules, the highest multiplier of the ones that apply being applied.
+type SurgeSchedule struct {
+	Mode           SurgeMode
+	Location       *time.Location
+	CellSizeDeg    float64
+	MaxMultiplier  float64
+	MaxSurgeAmount float64
+	Rules          []SurgeRule
+}
+
+func NewSurgeSchedule(
+	mode SurgeMode,
+	location *time.Location,
+	cellSizeDeg float64,
+	maxMultiplier float64,
+	maxSurgeAmount float64,
+	rules []SurgeRule,
+) *SurgeSchedule {
+	return &SurgeSchedule{
+		Mode:           mode,
+		Location:       location,
+		CellSizeDeg:    cellSizeDeg,
+		MaxMultiplier:  maxMultiplier,
+		MaxSurgeAmount: maxSurgeAmount,
+		Rules:          rules,
+	}
+}
+
+// MultiplierAt returns the surge multiplier at the unix timestamp and the position, that is the highest
+// multiplier of the SurgeRules that apply, capped to the MaxMultiplier, or 1 when none of them applies.
+func (ss *SurgeSchedule) MultiplierAt(timestamp int64, lat, lng float64) float64 {
+	local := time.Unix(timestamp, 0).In(ss.Location)
+	multiplier := 1.0
+
+	for _, rule := range ss.Rules {
+		if rule.Multiplier <= multiplier || !rule.Window.Contains(local) {
+			continue
+		}
+		if rule.Zone != nil && !rule.Zone.Contains(lat, lng) {
+			continue
+		}
+		if rule.Cell != nil && *rule.Cell != CellAt(ss.CellSizeDeg, lat, lng) {
+			continue
+		}
+
+		multiplier = rule.Multiplier
+	}
+
+	if ss.MaxMultiplier > 0 {
+		multiplier = math.Min(multiplier, ss.MaxMultiplier)
+	}
+
+	return multiplier
+}
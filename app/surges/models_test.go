@@ -0,0 +1,92 @@
+/*
+Package surges
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package surges
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/zones"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// centre is the zone of the Athens centre.
+var centre = *zones.NewZone(
+	"centre",
+	[]zones.Polygon{
+		{
+			{
+				{Lat: 37.95, Lng: 23.70},
+				{Lat: 37.95, Lng: 23.76},
+				{Lat: 38.00, Lng: 23.76},
+				{Lat: 38.00, Lng: 23.70},
+				{Lat: 37.95, Lng: 23.70},
+			},
+		},
+	},
+	nil,
+)
+
+// Tests the SurgeWindow Contains method on windows within a day, wrapping past midnight and lasting the whole day.
+func TestSurgeWindowContains(t *testing.T) {
+	// Thursday 17 July 2014.
+	thursdayAt := func(hour, minute int) time.Time {
+		return time.Date(2014, 7, 17, hour, minute, 0, 0, time.UTC)
+	}
+
+	morning := NewSurgeWindow(nil, 7*60, 10*60)
+	assert.True(t, morning.Contains(thursdayAt(7, 0)))
+	assert.True(t, morning.Contains(thursdayAt(9, 59)))
+	assert.False(t, morning.Contains(thursdayAt(10, 0)))
+
+	// Wednesday and Thursday nights, from 22:00 up to 02:00.
+	nights := NewSurgeWindow([]time.Weekday{time.Wednesday, time.Thursday}, 22*60, 2*60)
+	assert.True(t, nights.Contains(thursdayAt(23, 0)))
+	// Thursday 01:00 belongs to the Wednesday night.
+	assert.True(t, nights.Contains(thursdayAt(1, 0)))
+	assert.False(t, nights.Contains(thursdayAt(2, 0)))
+	assert.False(t, nights.Contains(thursdayAt(12, 0)))
+	// Saturday 01:00 belongs to the Friday night.
+	assert.False(t, nights.Contains(time.Date(2014, 7, 19, 1, 0, 0, 0, time.UTC)))
+
+	thursdays := NewSurgeWindow([]time.Weekday{time.Thursday}, 0, 0)
+	assert.True(t, thursdays.Contains(thursdayAt(0, 0)))
+	assert.True(t, thursdays.Contains(thursdayAt(23, 59)))
+	assert.False(t, thursdays.Contains(time.Date(2014, 7, 18, 12, 0, 0, 0, time.UTC)))
+}
+
+// Tests the SurgeSchedule MultiplierAt method applies the highest multiplier of the rules in force, capped.
+func TestSurgeScheduleMultiplierAt(t *testing.T) {
+	// Thursday 17 July 2014 11:02:37 UTC.
+	timestamp := int64(1405594957)
+	cell := CellAt(0.01, 37.935, 23.625)
+
+	surgeSchedule := NewSurgeSchedule(
+		MeteredSurge,
+		time.UTC,
+		0.01,
+		2,
+		0,
+		[]SurgeRule{
+			*NewSurgeRule("midday", *NewSurgeWindow(nil, 11*60, 14*60), nil, nil, 1.2),
+			*NewSurgeRule("midday-centre", *NewSurgeWindow(nil, 11*60, 14*60), &centre, nil, 1.5),
+			*NewSurgeRule("midday-cell", *NewSurgeWindow(nil, 11*60, 14*60), nil, &cell, 3),
+			*NewSurgeRule("evening-centre", *NewSurgeWindow(nil, 18*60, 21*60), &centre, nil, 1.8),
+		},
+	)
+
+	assert.Equal(t, 1.5, surgeSchedule.MultiplierAt(timestamp, 37.966660, 23.728308))
+	assert.Equal(t, 1.2, surgeSchedule.MultiplierAt(timestamp, 37.900000, 23.800000))
+	// The cell multiplier of 3 is capped to 2.
+	assert.Equal(t, 2.0, surgeSchedule.MultiplierAt(timestamp, 37.935490, 23.625655))
+	// No rule is in force at 15:02.
+	assert.Equal(t, 1.0, surgeSchedule.MultiplierAt(timestamp+4*3600, 37.966660, 23.728308))
+}
+
+// Tests the CellAt returns the same GridCell for the positions of the same cell.
+func TestCellAt(t *testing.T) {
+	assert.Equal(t, GridCell{Row: 3793, Col: 2362}, CellAt(0.01, 37.935, 23.625))
+	assert.Equal(t, CellAt(0.01, 37.931, 23.621), CellAt(0.01, 37.939, 23.629))
+	assert.NotEqual(t, CellAt(0.01, 37.939, 23.629), CellAt(0.01, 37.941, 23.629))
+}
@@ -0,0 +1,253 @@
+/*
+Package surges
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package surges
+
+import (
+	"fmt"
+	"github.com/iliaskaras/fare-estimation/app/infrastructure/configs"
+	"github.com/iliaskaras/fare-estimation/app/tariffs"
+	"github.com/iliaskaras/fare-estimation/app/zones"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	windowTimeLayout = "15:04"
+)
+
+// surgeScheduleDefinition is the surge schedule file, referring to the zones of a GeoJSON file by their name.
+type surgeScheduleDefinition struct {
+	Zones          string                `json:"zones" yaml:"zones"`
+	Mode           string                `json:"mode" yaml:"mode"`
+	Timezone       string                `json:"timezone" yaml:"timezone"`
+	CellSize       float64               `json:"cell_size" yaml:"cell_size"`
+	MaxMultiplier  float64               `json:"max_multiplier" yaml:"max_multiplier"`
+	MaxSurgeAmount float64               `json:"max_surge_amount" yaml:"max_surge_amount"`
+	Rules          []surgeRuleDefinition `json:"rules" yaml:"rules"`
+}
+
+type surgeRuleDefinition struct {
+	Name       string           `json:"name" yaml:"name"`
+	Days       []string         `json:"days" yaml:"days"`
+	From       string           `json:"from" yaml:"from"`
+	To         string           `json:"to" yaml:"to"`
+	Zone       string           `json:"zone" yaml:"zone"`
+	Cell       *pointDefinition `json:"cell" yaml:"cell"`
+	Multiplier float64          `json:"multiplier" yaml:"multiplier"`
+}
+
+type pointDefinition struct {
+	Lat float64 `json:"lat" yaml:"lat"`
+	Lng float64 `json:"lng" yaml:"lng"`
+}
+
+type SurgeService struct {
+	configDecoder configs.ConfigDecoder
+}
+
+func NewSurgeService(configDecoder configs.ConfigDecoder) *SurgeService {
+	return &SurgeService{
+		configDecoder: configDecoder,
+	}
+}
+
+// Load reads the surge schedule file found in filePath, along with the GeoJSON zones file that it points
+// to, and returns its SurgeSchedule. The mode is either "metered", the default, or "ride", and the caps
+// must not be negative, the max_multiplier being at least 1 when given. Every rule requires a name and a
+// multiplier of at least 1, and applies from its from time up to its to time (HH:MM, the whole day when both
+// are omitted) on its days (every day when omitted), in its zone, in the grid cell of its cell position,
+// or everywhere when it has neither. A relative zones file path is resolved against the directory of the
+// surge schedule file, and it is only required when any of the rules refers to a zone, as the cell_size
+// is only required when any of them refers to a cell.
+func (ss *SurgeService) Load(filePath string) (*SurgeSchedule, error) {
+	var definition surgeScheduleDefinition
+
+	if err := ss.configDecoder.Decode(filePath, &definition); err != nil {
+		return nil, err
+	}
+
+	mode := MeteredSurge
+	if definition.Mode != "" {
+		mode = SurgeMode(definition.Mode)
+	}
+	if mode != MeteredSurge && mode != RideSurge {
+		return nil, NewSurgeError(
+			UnsupportedSurgeMode,
+			fmt.Sprintf("surge mode: %q must be one of: %s, %s", definition.Mode, MeteredSurge, RideSurge),
+		)
+	}
+
+	if definition.MaxMultiplier != 0 && definition.MaxMultiplier < 1 {
+		return nil, NewSurgeError(InvalidSurgeCap, "max_multiplier must be at least 1")
+	}
+	if definition.MaxSurgeAmount < 0 {
+		return nil, NewSurgeError(InvalidSurgeCap, "max_surge_amount must not be negative")
+	}
+
+	location, err := tariffs.LoadLocation(definition.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	zonesByName, err := loadZones(filePath, definition)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []SurgeRule
+
+	for _, ruleDefinition := range definition.Rules {
+		rule, err := newSurgeRule(ruleDefinition, definition.CellSize, zonesByName)
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, *rule)
+	}
+
+	return NewSurgeSchedule(
+		mode,
+		location,
+		definition.CellSize,
+		definition.MaxMultiplier,
+		definition.MaxSurgeAmount,
+		rules,
+	), nil
+}
+
+// newSurgeRule validates the ruleDefinition and returns its SurgeRule.
+func newSurgeRule(
+	ruleDefinition surgeRuleDefinition,
+	cellSizeDeg float64,
+	zonesByName map[string]zones.Zone,
+) (*SurgeRule, error) {
+	if ruleDefinition.Name == "" {
+		return nil, NewSurgeError(InvalidSurgeRule, "surge rule has no name")
+	}
+	if ruleDefinition.Multiplier < 1 {
+		return nil, NewSurgeError(
+			InvalidSurgeRule,
+			"surge rule: "+ruleDefinition.Name+" must have a multiplier of at least 1",
+		)
+	}
+
+	window, err := newSurgeWindow(ruleDefinition)
+	if err != nil {
+		return nil, err
+	}
+
+	var zone *zones.Zone
+	var cell *GridCell
+
+	switch {
+	case ruleDefinition.Zone != "" && ruleDefinition.Cell != nil:
+		return nil, NewSurgeError(
+			InvalidSurgeRule,
+			"surge rule: "+ruleDefinition.Name+" must have either a zone or a cell",
+		)
+	case ruleDefinition.Zone != "":
+		ruleZone, ok := zonesByName[ruleDefinition.Zone]
+		if !ok {
+			return nil, NewSurgeError(
+				UnknownSurgeZone,
+				"surge rule: "+ruleDefinition.Name+" zone: "+ruleDefinition.Zone+" is unknown",
+			)
+		}
+		zone = &ruleZone
+	case ruleDefinition.Cell != nil:
+		if cellSizeDeg <= 0 {
+			return nil, NewSurgeError(
+				InvalidSurgeRule,
+				"surge rule: "+ruleDefinition.Name+" refers to a cell, the cell_size must be above zero",
+			)
+		}
+		ruleCell := CellAt(cellSizeDeg, ruleDefinition.Cell.Lat, ruleDefinition.Cell.Lng)
+		cell = &ruleCell
+	}
+
+	return NewSurgeRule(ruleDefinition.Name, *window, zone, cell, ruleDefinition.Multiplier), nil
+}
+
+// newSurgeWindow returns the SurgeWindow of the days and the from and to times of the ruleDefinition.
+func newSurgeWindow(ruleDefinition surgeRuleDefinition) (*SurgeWindow, error) {
+	var days []time.Weekday
+	for _, day := range ruleDefinition.Days {
+		weekday, ok := weekdays[strings.ToLower(day)]
+		if !ok {
+			return nil, NewSurgeError(
+				InvalidSurgeWindow,
+				"surge rule: "+ruleDefinition.Name+" day: "+day+" is not a day of the week",
+			)
+		}
+		days = append(days, weekday)
+	}
+
+	if ruleDefinition.From == "" && ruleDefinition.To == "" {
+		return NewSurgeWindow(days, 0, 0), nil
+	}
+
+	from, errFrom := time.Parse(windowTimeLayout, ruleDefinition.From)
+	to, errTo := time.Parse(windowTimeLayout, ruleDefinition.To)
+	if errFrom != nil || errTo != nil {
+		return nil, NewSurgeError(
+			InvalidSurgeWindow,
+			"surge rule: "+ruleDefinition.Name+" from and to times must be formatted as HH:MM",
+		)
+	}
+
+	return NewSurgeWindow(days, from.Hour()*60+from.Minute(), to.Hour()*60+to.Minute()), nil
+}
+
+// loadZones returns the zones of the GeoJSON zones file that the surge schedule file points to, by their
+// name, none when none of the rules refers to a zone.
+func loadZones(filePath string, definition surgeScheduleDefinition) (map[string]zones.Zone, error) {
+	zonesByName := make(map[string]zones.Zone)
+
+	referencesZones := false
+	for _, rule := range definition.Rules {
+		if rule.Zone != "" {
+			referencesZones = true
+		}
+	}
+	if !referencesZones {
+		return zonesByName, nil
+	}
+
+	if definition.Zones == "" {
+		return nil, NewSurgeError(InvalidSurgeRule, "the surge schedule file must point to a zones file")
+	}
+
+	zonesPath := definition.Zones
+	if !filepath.IsAbs(zonesPath) {
+		zonesPath = filepath.Join(filepath.Dir(filePath), zonesPath)
+	}
+
+	zoneService, err := zones.GetZoneService(zonesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	loadedZones, err := zoneService.Load(zonesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, zone := range loadedZones {
+		zonesByName[zone.Name] = zone
+	}
+
+	return zonesByName, nil
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
@@ -0,0 +1,178 @@
+/*
+Package surges
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package surges
+
+import (
+	"github.com/Flaque/filet"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testZones = `{
+	"type": "FeatureCollection",
+	"features": [
+		{
+			"type": "Feature",
+			"properties": {"name": "centre"},
+			"geometry": {
+				"type": "Polygon",
+				"coordinates": [[[23.70, 37.95], [23.76, 37.95], [23.76, 38.00], [23.70, 38.00], [23.70, 37.95]]]
+			}
+		}
+	]
+}`
+
+// writeSurgeFiles creates a temporary zones file and a surge schedule file with the given content
+// next to it, returning the surge schedule file path.
+func writeSurgeFiles(t *testing.T, content string) string {
+	dir := filet.TmpDir(t, "")
+	if err := os.WriteFile(filepath.Join(dir, "zones.geojson"), []byte(testZones), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	filePath := filepath.Join(dir, "surge.yaml")
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return filePath
+}
+
+func loadSurgeSchedule(t *testing.T, filePath string) (*SurgeSchedule, error) {
+	surgeService, err := GetSurgeService(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return surgeService.Load(filePath)
+}
+
+// Tests the SurgeService.Load returns the SurgeSchedule of the surge schedule file, with its rules in order.
+func TestLoadSuccessfulExecution(t *testing.T) {
+	defer filet.CleanUp(t)
+	filePath := writeSurgeFiles(t, `
+zones: zones.geojson
+mode: ride
+timezone: Europe/Athens
+cell_size: 0.01
+max_multiplier: 2.5
+max_surge_amount: 10
+rules:
+  - name: friday-nights-centre
+    days: [friday, Saturday]
+    from: "22:00"
+    to: "02:00"
+    zone: centre
+    multiplier: 1.8
+  - name: airport-cell
+    cell: {lat: 37.935, lng: 23.944}
+    multiplier: 1.3
+`)
+
+	surgeSchedule, err := loadSurgeSchedule(t, filePath)
+	assert.NoError(t, err)
+
+	assert.Equal(t, RideSurge, surgeSchedule.Mode)
+	assert.Equal(t, "Europe/Athens", surgeSchedule.Location.String())
+	assert.Equal(t, 0.01, surgeSchedule.CellSizeDeg)
+	assert.Equal(t, 2.5, surgeSchedule.MaxMultiplier)
+	assert.Equal(t, 10.0, surgeSchedule.MaxSurgeAmount)
+	assert.Len(t, surgeSchedule.Rules, 2)
+
+	centreRule := surgeSchedule.Rules[0]
+	assert.Equal(t, "friday-nights-centre", centreRule.Name)
+	assert.Equal(t, *NewSurgeWindow([]time.Weekday{time.Friday, time.Saturday}, 22*60, 2*60), centreRule.Window)
+	assert.Equal(t, "centre", centreRule.Zone.Name)
+	assert.Nil(t, centreRule.Cell)
+	assert.Equal(t, 1.8, centreRule.Multiplier)
+
+	airportRule := surgeSchedule.Rules[1]
+	assert.Equal(t, *NewSurgeWindow(nil, 0, 0), airportRule.Window)
+	assert.Nil(t, airportRule.Zone)
+	assert.Equal(t, CellAt(0.01, 37.935, 23.944), *airportRule.Cell)
+}
+
+// Tests the SurgeService.Load defaults to the metered mode in UTC, without requiring a zones file.
+func TestLoadWithDefaults(t *testing.T) {
+	defer filet.CleanUp(t)
+	filePath := writeSurgeFiles(t, "rules:\n  - name: everywhere\n    multiplier: 1.2\n")
+
+	surgeSchedule, err := loadSurgeSchedule(t, filePath)
+	assert.NoError(t, err)
+
+	assert.Equal(t, MeteredSurge, surgeSchedule.Mode)
+	assert.Equal(t, time.UTC, surgeSchedule.Location)
+	assert.Equal(t, 1.2, surgeSchedule.Rules[0].Multiplier)
+}
+
+// Tests the SurgeService.Load return a SurgeError when the surge schedule or any of its rules is invalid.
+func TestLoadReturnErrorWhenSurgeScheduleIsInvalid(t *testing.T) {
+	defer filet.CleanUp(t)
+
+	testCases := []struct {
+		content       string
+		expectedError error
+	}{
+		{
+			"mode: trip\n",
+			NewSurgeError(UnsupportedSurgeMode, `surge mode: "trip" must be one of: metered, ride`),
+		},
+		{
+			"max_multiplier: 0.5\n",
+			NewSurgeError(InvalidSurgeCap, "max_multiplier must be at least 1"),
+		},
+		{
+			"max_surge_amount: -1\n",
+			NewSurgeError(InvalidSurgeCap, "max_surge_amount must not be negative"),
+		},
+		{
+			"rules:\n  - multiplier: 1.2\n",
+			NewSurgeError(InvalidSurgeRule, "surge rule has no name"),
+		},
+		{
+			"rules:\n  - name: a\n",
+			NewSurgeError(InvalidSurgeRule, "surge rule: a must have a multiplier of at least 1"),
+		},
+		{
+			"rules:\n  - name: a\n    multiplier: 0.8\n",
+			NewSurgeError(InvalidSurgeRule, "surge rule: a must have a multiplier of at least 1"),
+		},
+		{
+			"rules:\n  - name: a\n    days: [someday]\n    multiplier: 1.2\n",
+			NewSurgeError(InvalidSurgeWindow, "surge rule: a day: someday is not a day of the week"),
+		},
+		{
+			"rules:\n  - name: a\n    from: \"22:00\"\n    multiplier: 1.2\n",
+			NewSurgeError(InvalidSurgeWindow, "surge rule: a from and to times must be formatted as HH:MM"),
+		},
+		{
+			"zones: zones.geojson\nrules:\n  - name: a\n    zone: airport\n    multiplier: 1.2\n",
+			NewSurgeError(UnknownSurgeZone, "surge rule: a zone: airport is unknown"),
+		},
+		{
+			"rules:\n  - name: a\n    zone: centre\n    multiplier: 1.2\n",
+			NewSurgeError(InvalidSurgeRule, "the surge schedule file must point to a zones file"),
+		},
+		{
+			"rules:\n  - name: a\n    cell: {lat: 37.9, lng: 23.7}\n    multiplier: 1.2\n",
+			NewSurgeError(InvalidSurgeRule, "surge rule: a refers to a cell, the cell_size must be above zero"),
+		},
+		{
+			"zones: zones.geojson\ncell_size: 0.01\nrules:\n  - name: a\n    zone: centre\n    cell: {lat: 37.9, lng: 23.7}\n    multiplier: 1.2\n",
+			NewSurgeError(InvalidSurgeRule, "surge rule: a must have either a zone or a cell"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		surgeSchedule, err := loadSurgeSchedule(t, writeSurgeFiles(t, testCase.content))
+		assert.Error(t, err)
+
+		assert.Nil(t, surgeSchedule)
+		assert.Equal(t, testCase.expectedError, err)
+	}
+}
@@ -0,0 +1,37 @@
+/*
+Package tariffs
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package tariffs
+
+import (
+	"errors"
+	baseAppErrors "github.com/iliaskaras/fare-estimation/app/infrastructure/errors"
+)
+
+type TariffError struct {
+	baseAppErrors.BaseAppError
+}
+
+func NewTariffError(err error, additionalInfo string) TariffError {
+	return TariffError{
+		BaseAppError: baseAppErrors.NewBaseAppError(err, additionalInfo),
+	}
+}
+
+var (
+	MissingTariffValue     = errors.New("missing tariff value")
+	NegativeTariffValue    = errors.New("negative tariff value")
+	InvalidTimezone        = errors.New("invalid timezone")
+	UnsupportedDayType     = errors.New("unsupported day type")
+	MissingZoneTariff      = errors.New("missing zone tariff")
+	MismatchedCurrency     = errors.New("mismatched currency")
+	InvalidSpeeds          = errors.New("invalid speeds")
+	UnsupportedMaxSpeed    = errors.New("unsupported max speed")
+	UnsupportedBillingMode = errors.New("unsupported billing mode")
+	InvalidMeterDropAmount = errors.New("invalid meter drop amount")
+	MismatchedBillingMode  = errors.New("mismatched billing mode")
+	InvalidTariffVersion   = errors.New("invalid tariff version")
+	DuplicateTariffVersion = errors.New("duplicate tariff version")
+	UnversionedTariffZones = errors.New("unversioned tariff zones")
+)
@@ -0,0 +1,20 @@
+/*
+Package tariffs
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package tariffs
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/infrastructure/configs"
+)
+
+// GetTariffService is responsible for initializing and injecting all the dependencies
+// of the TariffService, based on the tariff file type provided.
+func GetTariffService(filePath string) (*TariffService, error) {
+	configDecoder, err := configs.GetConfigDecoder(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTariffService(configDecoder), nil
+}
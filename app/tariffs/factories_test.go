@@ -0,0 +1,33 @@
+/*
+Package tariffs
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package tariffs
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/infrastructure/configs"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+// Tests the GetTariffService initializes and returns the TariffService.
+func TestGetTariffService(t *testing.T) {
+	tariffService, err := GetTariffService("tariff.yaml")
+	assert.NoError(t, err)
+
+	returnedServiceType := reflect.TypeOf(tariffService).String()
+	expectedServiceType := "*tariffs.TariffService"
+
+	assert.Equal(t, expectedServiceType, returnedServiceType)
+}
+
+// Tests the GetTariffService return a ConfigError when the tariff file type is unsupported.
+func TestGetTariffServiceReturnErrorWhenFileTypeIsInvalid(t *testing.T) {
+	tariffService, err := GetTariffService("tariff.csv")
+	assert.Error(t, err)
+
+	_, ok := err.(configs.ConfigError)
+	assert.Equal(t, true, ok)
+	assert.Nil(t, tariffService)
+}
@@ -0,0 +1,166 @@
+/*
+Package tariffs
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package tariffs
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/calendars"
+	"github.com/iliaskaras/fare-estimation/app/money"
+	"github.com/iliaskaras/fare-estimation/app/rides"
+	"github.com/iliaskaras/fare-estimation/app/zones"
+	"time"
+)
+
+// The built-in tariff, used whenever no tariff file is provided.
+const (
+	StandardFare float64 = 1.30
+	MinimumFare  float64 = 3.47
+	Idle         float64 = 11.90
+	MovingDay    float64 = 0.74
+	MovingNight  float64 = 1.30
+)
+
+// DayType is the type of the calendar day that a RateTable applies to.
+type DayType string
+
+const (
+	Weekday DayType = "weekday"
+	Sunday  DayType = "sunday"
+	Holiday DayType = "holiday"
+)
+
+// BillingMode is the way that a Tariff charges the time and the distance of a ride.
+type BillingMode string
+
+const (
+	// SpeedSwitchBilling charges the distance of the ride segments above the idle speed, and the
+	// time of the rest of them.
+	SpeedSwitchBilling BillingMode = "speed_switch"
+	// MeterBilling charges whichever of the time and the distance amount is higher, as a taximeter
+	// does, so the distance above the crossover speed of the rates, and the time below it. The
+	// metered amount is charged in whole fare drops.
+	MeterBilling BillingMode = "meter"
+)
+
+// RateTable holds the rates charged during a DayType.
+// - Idle: The amount charged per hour of idle time.
+// - MovingDay: The amount charged per km while moving at day time.
+// - MovingNight: The amount charged per km while moving at night time.
+type RateTable struct {
+	Idle        float64
+	MovingDay   float64
+	MovingNight float64
+}
+
+func NewRateTable(idle, movingDay, movingNight float64) *RateTable {
+	return &RateTable{
+		idle,
+		movingDay,
+		movingNight,
+	}
+}
+
+// Tariff holds the prices that a fare is estimated with.
+// - StandardFare: The flag fall, charged once per ride.
+// - MinimumFare: The minimum amount a ride can cost.
+// - Idle: The amount charged per hour of idle time.
+// - MovingDay: The amount charged per km while moving at day time.
+// - MovingNight: The amount charged per km while moving at night time.
+// - Location: The timezone that day and night time are decided in, UTC by default.
+// - DayTypeRates: The RateTable of each DayType with rates of its own, the rest use the above rates.
+// - Holidays: The public holidays Calendar, nil when there are none.
+// - Currency: The money.Currency of the prices, EUR by default.
+// - Rounding: The money.RoundingMode that the fares are rounded with, half up by default.
+// - IdleSpeedKMH: The speed that a ride segment is charged as idle at or below, rides.MinimumHourKM by default.
+// - MaxSpeedKMH: The speed that a ride segment is rejected as erroneous above, rides.MaxKMPerHour by default,
+// set by the base Tariff alone, as the positions are filtered before the zone or the version is known.
+// - FreeWaitingSecs: The idle time per ride that is not charged, none by default.
+// - BillingMode: The BillingMode of the Tariff, SpeedSwitchBilling by default.
+// - MeterDropAmount: The amount of a fare drop, when the BillingMode is MeterBilling.
+type Tariff struct {
+	StandardFare    float64
+	MinimumFare     float64
+	Idle            float64
+	MovingDay       float64
+	MovingNight     float64
+	Location        *time.Location
+	DayTypeRates    map[DayType]RateTable
+	Holidays        *calendars.Calendar
+	Currency        money.Currency
+	Rounding        money.RoundingMode
+	IdleSpeedKMH    float64
+	MaxSpeedKMH     float64
+	FreeWaitingSecs float64
+	BillingMode     BillingMode
+	MeterDropAmount float64
+}
+
+func NewTariff(standardFare, minimumFare, idle, movingDay, movingNight float64) *Tariff {
+	return &Tariff{
+		StandardFare: standardFare,
+		MinimumFare:  minimumFare,
+		Idle:         idle,
+		MovingDay:    movingDay,
+		MovingNight:  movingNight,
+		Location:     time.UTC,
+		DayTypeRates: make(map[DayType]RateTable),
+		Currency:     money.EUR,
+		Rounding:     money.HalfUp,
+		IdleSpeedKMH: rides.MinimumHourKM,
+		MaxSpeedKMH:  rides.MaxKMPerHour,
+		BillingMode:  SpeedSwitchBilling,
+	}
+}
+
+// DefaultTariff returns the built-in Tariff.
+func DefaultTariff() *Tariff {
+	return NewTariff(
+		StandardFare,
+		MinimumFare,
+		Idle,
+		MovingDay,
+		MovingNight,
+	)
+}
+
+// Rates returns the RateTable of the provided DayType.
+func (t *Tariff) Rates(dayType DayType) RateTable {
+	if rateTable, ok := t.DayTypeRates[dayType]; ok {
+		return rateTable
+	}
+
+	return *NewRateTable(t.Idle, t.MovingDay, t.MovingNight)
+}
+
+// TariffZone maps a zones.Zone to the Tariff that the RideSegments starting in it are priced with.
+type TariffZone struct {
+	Zone   zones.Zone
+	Tariff *Tariff
+}
+
+func NewTariffZone(zone zones.Zone, tariff *Tariff) *TariffZone {
+	return &TariffZone{
+		zone,
+		tariff,
+	}
+}
+
+// TariffVersion is a version of the default Tariff, in force from its EffectiveFrom time until the
+// EffectiveFrom time of the next TariffVersion.
+// - ID: The identifier of the version, written next to the fares priced with it for auditing.
+// - EffectiveFrom: The time that the version comes into force.
+// - Tariff: The Tariff of the version.
+type TariffVersion struct {
+	ID            string
+	EffectiveFrom time.Time
+	Tariff        *Tariff
+}
+
+func NewTariffVersion(id string, effectiveFrom time.Time, tariff *Tariff) *TariffVersion {
+	return &TariffVersion{
+		ID:            id,
+		EffectiveFrom: effectiveFrom,
+		Tariff:        tariff,
+	}
+}
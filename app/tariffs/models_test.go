@@ -0,0 +1,20 @@
+/*
+Package tariffs
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package tariffs
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// Tests the Tariff Rates method falls back to the Tariff's own rates for a DayType without a RateTable.
+func TestTariffRates(t *testing.T) {
+	tariff := DefaultTariff()
+	tariff.DayTypeRates[Holiday] = *NewRateTable(13.90, 0.94, 1.50)
+
+	assert.Equal(t, *NewRateTable(Idle, MovingDay, MovingNight), tariff.Rates(Weekday))
+	assert.Equal(t, *NewRateTable(Idle, MovingDay, MovingNight), tariff.Rates(Sunday))
+	assert.Equal(t, *NewRateTable(13.90, 0.94, 1.50), tariff.Rates(Holiday))
+}
@@ -0,0 +1,471 @@
+/*
+Package tariffs
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package tariffs
+
+import (
+	"fmt"
+	"github.com/iliaskaras/fare-estimation/app/infrastructure/configs"
+	"github.com/iliaskaras/fare-estimation/app/money"
+	"github.com/iliaskaras/fare-estimation/app/zones"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	tariffProperty = "tariff"
+)
+
+var dayTypesWithRates = []DayType{Sunday, Holiday}
+
+var billingModes = []BillingMode{SpeedSwitchBilling, MeterBilling}
+
+// tariffDefinition is the Tariff as found in a tariff file. The values are pointers
+// so that a value missing from the file can be told apart from a zero value.
+type tariffDefinition struct {
+	StandardFare    *float64                       `json:"standard_fare" yaml:"standard_fare"`
+	MinimumFare     *float64                       `json:"minimum_fare" yaml:"minimum_fare"`
+	Idle            *float64                       `json:"idle" yaml:"idle"`
+	MovingDay       *float64                       `json:"moving_day" yaml:"moving_day"`
+	MovingNight     *float64                       `json:"moving_night" yaml:"moving_night"`
+	Timezone        string                         `json:"timezone" yaml:"timezone"`
+	Currency        string                         `json:"currency" yaml:"currency"`
+	Rounding        string                         `json:"rounding" yaml:"rounding"`
+	IdleSpeedKMH    *float64                       `json:"idle_speed_kmh" yaml:"idle_speed_kmh"`
+	MaxSpeedKMH     *float64                       `json:"max_speed_kmh" yaml:"max_speed_kmh"`
+	FreeWaitingSecs *float64                       `json:"free_waiting_secs" yaml:"free_waiting_secs"`
+	BillingMode     string                         `json:"billing_mode" yaml:"billing_mode"`
+	MeterDropAmount *float64                       `json:"meter_drop_amount" yaml:"meter_drop_amount"`
+	DayTypes        map[string]rateTableDefinition `json:"day_types" yaml:"day_types"`
+}
+
+// rateTableDefinition is the RateTable of a DayType as found in a tariff file.
+type rateTableDefinition struct {
+	Idle        *float64 `json:"idle" yaml:"idle"`
+	MovingDay   *float64 `json:"moving_day" yaml:"moving_day"`
+	MovingNight *float64 `json:"moving_night" yaml:"moving_night"`
+}
+
+// tariffVersionsDefinition is the list of TariffVersion as found in a tariff versions file.
+type tariffVersionsDefinition struct {
+	Versions []tariffVersionDefinition `json:"versions" yaml:"versions"`
+}
+
+// tariffVersionDefinition is a TariffVersion as found in a tariff versions file, pointing to its tariff file.
+type tariffVersionDefinition struct {
+	ID            string `json:"id" yaml:"id"`
+	EffectiveFrom string `json:"effective_from" yaml:"effective_from"`
+	Tariff        string `json:"tariff" yaml:"tariff"`
+}
+
+// tariffValue is a named value of a tariffDefinition, to be validated.
+type tariffValue struct {
+	name  string
+	value *float64
+}
+
+type TariffService struct {
+	configDecoder configs.ConfigDecoder
+}
+
+func NewTariffService(configDecoder configs.ConfigDecoder) *TariffService {
+	return &TariffService{
+		configDecoder: configDecoder,
+	}
+}
+
+// Load reads the tariff file found in filePath and returns the validated Tariff.
+// The Tariff is rejected if any of its rates is missing or negative, while the rest of
+// its values are optional, with the defaults of the DefaultTariff.
+func (ts *TariffService) Load(filePath string) (*Tariff, error) {
+	tariff, _, err := ts.load(filePath)
+
+	return tariff, err
+}
+
+// load reads the tariff file found in filePath and returns the validated Tariff, along with its tariffDefinition.
+func (ts *TariffService) load(filePath string) (*Tariff, *tariffDefinition, error) {
+	var definition tariffDefinition
+
+	if err := ts.configDecoder.Decode(filePath, &definition); err != nil {
+		return nil, nil, err
+	}
+
+	err := validateTariffValues(
+		[]tariffValue{
+			{"standard_fare", definition.StandardFare},
+			{"minimum_fare", definition.MinimumFare},
+			{"idle", definition.Idle},
+			{"moving_day", definition.MovingDay},
+			{"moving_night", definition.MovingNight},
+		},
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	location, err := LoadLocation(definition.Timezone)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tariff := NewTariff(
+		*definition.StandardFare,
+		*definition.MinimumFare,
+		*definition.Idle,
+		*definition.MovingDay,
+		*definition.MovingNight,
+	)
+	tariff.Location = location
+
+	if definition.Currency != "" {
+		if tariff.Currency, err = money.ParseCurrency(definition.Currency); err != nil {
+			return nil, nil, err
+		}
+	}
+	if definition.Rounding != "" {
+		if tariff.Rounding, err = money.ParseRoundingMode(definition.Rounding); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := loadSpeedsAndWaiting(tariff, definition); err != nil {
+		return nil, nil, err
+	}
+
+	if err := loadBillingMode(tariff, definition); err != nil {
+		return nil, nil, err
+	}
+
+	// The sunday and holiday DayTypes may have rates of their own, requiring all of them.
+	for rawDayType, rateTable := range definition.DayTypes {
+		dayType, err := parseDayTypeWithRates(rawDayType)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		err = validateTariffValues(
+			[]tariffValue{
+				{"day_types." + rawDayType + ".idle", rateTable.Idle},
+				{"day_types." + rawDayType + ".moving_day", rateTable.MovingDay},
+				{"day_types." + rawDayType + ".moving_night", rateTable.MovingNight},
+			},
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		tariff.DayTypeRates[dayType] = *NewRateTable(
+			*rateTable.Idle,
+			*rateTable.MovingDay,
+			*rateTable.MovingNight,
+		)
+	}
+
+	return tariff, &definition, nil
+}
+
+// LoadTariffZones loads the zones found in the zone file of zonesFilePath, along with the Tariff
+// of each zone, loaded from the tariff file that its tariff property points to. A relative tariff
+// file path is resolved against the directory of the zone file. The zones sharing a tariff file
+// share the same Tariff, which must not set a max speed. Every zone Tariff must be in the currency,
+// as the amounts of a ride crossing zones are added up, and in the billingMode, as the fare drops of
+// a ride are counted once over all of its zones.
+func LoadTariffZones(zonesFilePath string, currency money.Currency, billingMode BillingMode) ([]TariffZone, error) {
+	zoneService, err := zones.GetZoneService(zonesFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	loadedZones, err := zoneService.Load(zonesFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	loadedTariffs := make(map[string]*Tariff)
+	var tariffZones []TariffZone
+
+	for _, zone := range loadedZones {
+		tariffPath, _ := zone.Properties[tariffProperty].(string)
+		if tariffPath == "" {
+			return nil, NewTariffError(MissingZoneTariff, "zone: "+zone.Name+" has no tariff property")
+		}
+		if !filepath.IsAbs(tariffPath) {
+			tariffPath = filepath.Join(filepath.Dir(zonesFilePath), tariffPath)
+		}
+
+		tariff, ok := loadedTariffs[tariffPath]
+		if !ok {
+			if tariff, err = loadNestedTariff(tariffPath, "zone: "+zone.Name); err != nil {
+				return nil, err
+			}
+			loadedTariffs[tariffPath] = tariff
+		}
+
+		if tariff.Currency != currency {
+			return nil, NewTariffError(
+				MismatchedCurrency,
+				"zone: "+zone.Name+" tariff currency: "+tariff.Currency.Code+" must be the tariff currency: "+currency.Code,
+			)
+		}
+		if tariff.BillingMode != billingMode {
+			return nil, NewTariffError(
+				MismatchedBillingMode,
+				"zone: "+zone.Name+" tariff billing mode: "+string(tariff.BillingMode)+
+					" must be the tariff billing mode: "+string(billingMode),
+			)
+		}
+
+		tariffZones = append(tariffZones, *NewTariffZone(zone, tariff))
+	}
+
+	return tariffZones, nil
+}
+
+// LoadTariffVersions loads the versions found in the tariff versions file of versionsFilePath, along
+// with the Tariff of each version, loaded from the tariff file that it points to. A relative tariff
+// file path is resolved against the directory of the versions file, and the Tariff must not set a max
+// speed. Every version requires a unique id, and a unique effective_from time in RFC 3339 format,
+// e.g. 2022-03-01T00:00:00+02:00. The versions are returned sorted by their EffectiveFrom time.
+func LoadTariffVersions(versionsFilePath string) ([]TariffVersion, error) {
+	configDecoder, err := configs.GetConfigDecoder(versionsFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var definition tariffVersionsDefinition
+	if err := configDecoder.Decode(versionsFilePath, &definition); err != nil {
+		return nil, err
+	}
+	if len(definition.Versions) == 0 {
+		return nil, NewTariffError(InvalidTariffVersion, "tariff versions file: "+versionsFilePath+" has no versions")
+	}
+
+	seenIDs := make(map[string]bool)
+	seenEffectiveFroms := make(map[int64]string)
+	var tariffVersions []TariffVersion
+
+	for _, versionDefinition := range definition.Versions {
+		if versionDefinition.ID == "" {
+			return nil, NewTariffError(InvalidTariffVersion, "tariff version has no id")
+		}
+		if seenIDs[versionDefinition.ID] {
+			return nil, NewTariffError(
+				DuplicateTariffVersion,
+				"tariff version: "+versionDefinition.ID+" is defined more than once",
+			)
+		}
+		seenIDs[versionDefinition.ID] = true
+
+		effectiveFrom, err := time.Parse(time.RFC3339, versionDefinition.EffectiveFrom)
+		if err != nil {
+			return nil, NewTariffError(
+				InvalidTariffVersion,
+				"tariff version: "+versionDefinition.ID+" effective_from: "+versionDefinition.EffectiveFrom+
+					" is not an RFC 3339 time",
+			)
+		}
+		if otherID, ok := seenEffectiveFroms[effectiveFrom.Unix()]; ok {
+			return nil, NewTariffError(
+				DuplicateTariffVersion,
+				"tariff versions: "+otherID+" and "+versionDefinition.ID+" have the same effective_from",
+			)
+		}
+		seenEffectiveFroms[effectiveFrom.Unix()] = versionDefinition.ID
+
+		tariffPath := versionDefinition.Tariff
+		if tariffPath == "" {
+			return nil, NewTariffError(InvalidTariffVersion, "tariff version: "+versionDefinition.ID+" has no tariff")
+		}
+		if !filepath.IsAbs(tariffPath) {
+			tariffPath = filepath.Join(filepath.Dir(versionsFilePath), tariffPath)
+		}
+
+		tariff, err := loadNestedTariff(tariffPath, "tariff version: "+versionDefinition.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		tariffVersions = append(tariffVersions, *NewTariffVersion(versionDefinition.ID, effectiveFrom, tariff))
+	}
+
+	sort.Slice(tariffVersions, func(i, j int) bool {
+		return tariffVersions[i].EffectiveFrom.Before(tariffVersions[j].EffectiveFrom)
+	})
+
+	return tariffVersions, nil
+}
+
+// loadNestedTariff loads the tariff file found in tariffPath, of the zone or the version named by owner.
+// It must not set a max_speed_kmh, the positions being filtered with the max speed of the tariff alone.
+func loadNestedTariff(tariffPath, owner string) (*Tariff, error) {
+	tariffService, err := GetTariffService(tariffPath)
+	if err != nil {
+		return nil, err
+	}
+
+	tariff, definition, err := tariffService.load(tariffPath)
+	if err != nil {
+		return nil, err
+	}
+	if definition.MaxSpeedKMH != nil {
+		return nil, NewTariffError(
+			UnsupportedMaxSpeed,
+			owner+" tariff must not set max_speed_kmh, only the tariff's own max speed applies",
+		)
+	}
+
+	return tariff, nil
+}
+
+// VersionAt returns the TariffVersion in force at the provided unix timestamp, out of the tariffVersions
+// sorted by their EffectiveFrom time, or nil when the timestamp is before the first of them.
+func VersionAt(tariffVersions []TariffVersion, timestamp int64) *TariffVersion {
+	var inForce *TariffVersion
+	for i := range tariffVersions {
+		if tariffVersions[i].EffectiveFrom.Unix() > timestamp {
+			break
+		}
+		inForce = &tariffVersions[i]
+	}
+
+	return inForce
+}
+
+// loadSpeedsAndWaiting sets the optional speeds and free waiting time of the tariffDefinition on the Tariff.
+// None of them may be negative, and the maximum speed must be above the idle speed.
+func loadSpeedsAndWaiting(tariff *Tariff, definition tariffDefinition) error {
+	var values []tariffValue
+	if definition.IdleSpeedKMH != nil {
+		values = append(values, tariffValue{"idle_speed_kmh", definition.IdleSpeedKMH})
+		tariff.IdleSpeedKMH = *definition.IdleSpeedKMH
+	}
+	if definition.MaxSpeedKMH != nil {
+		values = append(values, tariffValue{"max_speed_kmh", definition.MaxSpeedKMH})
+		tariff.MaxSpeedKMH = *definition.MaxSpeedKMH
+	}
+	if definition.FreeWaitingSecs != nil {
+		values = append(values, tariffValue{"free_waiting_secs", definition.FreeWaitingSecs})
+		tariff.FreeWaitingSecs = *definition.FreeWaitingSecs
+	}
+
+	if err := validateTariffValues(values); err != nil {
+		return err
+	}
+
+	return ValidateSpeeds(tariff.IdleSpeedKMH, tariff.MaxSpeedKMH)
+}
+
+// loadBillingMode sets the optional billing mode of the tariffDefinition on the Tariff, speed_switch by
+// default, along with the meter drop amount above zero that the meter billing mode requires.
+func loadBillingMode(tariff *Tariff, definition tariffDefinition) error {
+	if definition.BillingMode == "" {
+		return nil
+	}
+
+	billingMode, err := parseBillingMode(definition.BillingMode)
+	if err != nil {
+		return err
+	}
+	tariff.BillingMode = billingMode
+
+	if billingMode != MeterBilling {
+		return nil
+	}
+
+	if definition.MeterDropAmount == nil || *definition.MeterDropAmount <= 0 {
+		return NewTariffError(
+			InvalidMeterDropAmount,
+			"tariff value: meter_drop_amount must be above zero for the meter billing mode",
+		)
+	}
+	tariff.MeterDropAmount = *definition.MeterDropAmount
+
+	return nil
+}
+
+// parseBillingMode returns the BillingMode of the provided name.
+func parseBillingMode(name string) (BillingMode, error) {
+	for _, billingMode := range billingModes {
+		if string(billingMode) == name {
+			return billingMode, nil
+		}
+	}
+
+	supportedBillingModes := make([]string, len(billingModes))
+	for i, billingMode := range billingModes {
+		supportedBillingModes[i] = string(billingMode)
+	}
+
+	return "", NewTariffError(
+		UnsupportedBillingMode,
+		"provided billing mode: "+name+", must be one of the: "+strings.Join(supportedBillingModes, ","),
+	)
+}
+
+// ValidateSpeeds checks that the maximum speed is above the idle speed, otherwise every ride
+// segment that is not rejected as erroneous would be charged as idle.
+func ValidateSpeeds(idleSpeedKMH, maxSpeedKMH float64) error {
+	if maxSpeedKMH <= idleSpeedKMH {
+		return NewTariffError(
+			InvalidSpeeds,
+			fmt.Sprintf("max speed: %v km/h must be above the idle speed: %v km/h", maxSpeedKMH, idleSpeedKMH),
+		)
+	}
+
+	return nil
+}
+
+// validateTariffValues checks that none of the values is missing or negative.
+func validateTariffValues(values []tariffValue) error {
+	for _, v := range values {
+		if v.value == nil {
+			return NewTariffError(MissingTariffValue, "tariff value: "+v.name+" is missing")
+		}
+		if *v.value < 0 {
+			return NewTariffError(
+				NegativeTariffValue,
+				fmt.Sprintf("tariff value: %s is negative: %v", v.name, *v.value),
+			)
+		}
+	}
+
+	return nil
+}
+
+// parseDayTypeWithRates returns the DayType of the provided name, if it is one that can have its own RateTable.
+func parseDayTypeWithRates(name string) (DayType, error) {
+	for _, dayType := range dayTypesWithRates {
+		if string(dayType) == name {
+			return dayType, nil
+		}
+	}
+
+	supportedDayTypes := make([]string, len(dayTypesWithRates))
+	for i, dayType := range dayTypesWithRates {
+		supportedDayTypes[i] = string(dayType)
+	}
+
+	return "", NewTariffError(
+		UnsupportedDayType,
+		"provided day type: "+name+", must be one of the: "+strings.Join(supportedDayTypes, ","),
+	)
+}
+
+// LoadLocation returns the time.Location of the provided IANA timezone, e.g. Europe/Athens.
+// An empty timezone is resolved to UTC.
+func LoadLocation(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.UTC, nil
+	}
+
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, NewTariffError(InvalidTimezone, "provided timezone: "+timezone+" is not a valid IANA timezone")
+	}
+
+	return location, nil
+}
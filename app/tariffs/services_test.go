@@ -0,0 +1,709 @@
+/*
+Package tariffs
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package tariffs
+
+import (
+	"github.com/Flaque/filet"
+	"github.com/iliaskaras/fare-estimation/app/infrastructure/configs"
+	"github.com/iliaskaras/fare-estimation/app/money"
+	"github.com/iliaskaras/fare-estimation/app/rides"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTariffFile creates a temporary tariff file with the given extension and content.
+func writeTariffFile(t *testing.T, extension string, content string) string {
+	dir := filet.TmpDir(t, "")
+	filePath := filepath.Join(dir, "tariff"+extension)
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return filePath
+}
+
+// Tests the TariffService.Load loads a Tariff out of a .yaml and a .json tariff file.
+func TestTariffServiceLoadSuccessfulExecution(t *testing.T) {
+	defer filet.CleanUp(t)
+
+	tariffFiles := []string{
+		writeTariffFile(
+			t,
+			".yaml",
+			"standard_fare: 1.50\n"+
+				"minimum_fare: 4.00\n"+
+				"idle: 12.50\n"+
+				"moving_day: 0.80\n"+
+				"moving_night: 1.40\n",
+		),
+		writeTariffFile(
+			t,
+			".json",
+			`{"standard_fare": 1.50, "minimum_fare": 4.00, "idle": 12.50, "moving_day": 0.80, "moving_night": 1.40}`,
+		),
+	}
+
+	for _, tariffFile := range tariffFiles {
+		tariffService, _ := GetTariffService(tariffFile)
+		tariff, err := tariffService.Load(tariffFile)
+		assert.NoError(t, err)
+
+		assert.Equal(t, NewTariff(1.50, 4.00, 12.50, 0.80, 1.40), tariff)
+	}
+}
+
+// Tests the TariffService.Load accepts a tariff value of zero, which is not the same as a missing one.
+func TestTariffServiceLoadAcceptsZeroValues(t *testing.T) {
+	defer filet.CleanUp(t)
+
+	tariffFile := writeTariffFile(
+		t,
+		".yaml",
+		"standard_fare: 0\n"+
+			"minimum_fare: 0\n"+
+			"idle: 0\n"+
+			"moving_day: 0.80\n"+
+			"moving_night: 1.40\n",
+	)
+
+	tariffService, _ := GetTariffService(tariffFile)
+	tariff, err := tariffService.Load(tariffFile)
+	assert.NoError(t, err)
+
+	assert.Equal(t, NewTariff(0, 0, 0, 0.80, 1.40), tariff)
+}
+
+// Tests the TariffService.Load return a TariffError when a tariff value is missing.
+func TestTariffServiceLoadReturnErrorWhenValueIsMissing(t *testing.T) {
+	defer filet.CleanUp(t)
+
+	tariffFile := writeTariffFile(
+		t,
+		".yaml",
+		"standard_fare: 1.50\n"+
+			"minimum_fare: 4.00\n"+
+			"moving_day: 0.80\n"+
+			"moving_night: 1.40\n",
+	)
+
+	tariffService, _ := GetTariffService(tariffFile)
+	tariff, err := tariffService.Load(tariffFile)
+	assert.Error(t, err)
+
+	assert.Nil(t, tariff)
+	assert.Equal(t, NewTariffError(MissingTariffValue, "tariff value: idle is missing"), err)
+}
+
+// Tests the TariffService.Load return a TariffError when a tariff value is negative.
+func TestTariffServiceLoadReturnErrorWhenValueIsNegative(t *testing.T) {
+	defer filet.CleanUp(t)
+
+	tariffFile := writeTariffFile(
+		t,
+		".json",
+		`{"standard_fare": 1.50, "minimum_fare": 4.00, "idle": 12.50, "moving_day": -0.80, "moving_night": 1.40}`,
+	)
+
+	tariffService, _ := GetTariffService(tariffFile)
+	tariff, err := tariffService.Load(tariffFile)
+	assert.Error(t, err)
+
+	assert.Nil(t, tariff)
+	assert.Equal(t, NewTariffError(NegativeTariffValue, "tariff value: moving_day is negative: -0.8"), err)
+}
+
+// Tests the TariffService.Load return a ConfigError when the tariff file cannot be decoded.
+func TestTariffServiceLoadReturnErrorWhenFileIsInvalid(t *testing.T) {
+	defer filet.CleanUp(t)
+
+	tariffFile := writeTariffFile(t, ".yaml", "standard_fare: [1.50\n")
+
+	tariffService, _ := GetTariffService(tariffFile)
+	tariff, err := tariffService.Load(tariffFile)
+	assert.Error(t, err)
+
+	_, ok := err.(configs.ConfigError)
+	assert.Equal(t, true, ok)
+	assert.Nil(t, tariff)
+}
+
+// Tests the TariffService.Load loads the Tariff timezone, and defaults to UTC when it is omitted.
+func TestTariffServiceLoadTimezone(t *testing.T) {
+	defer filet.CleanUp(t)
+
+	prices := "standard_fare: 1.30\n" +
+		"minimum_fare: 3.47\n" +
+		"idle: 11.90\n" +
+		"moving_day: 0.74\n" +
+		"moving_night: 1.30\n"
+
+	athensTariffFile := writeTariffFile(t, ".yaml", prices+"timezone: Europe/Athens\n")
+	tariffService, _ := GetTariffService(athensTariffFile)
+	tariff, err := tariffService.Load(athensTariffFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "Europe/Athens", tariff.Location.String())
+
+	utcTariffFile := writeTariffFile(t, ".yml", prices)
+	tariffService, _ = GetTariffService(utcTariffFile)
+	tariff, err = tariffService.Load(utcTariffFile)
+	assert.NoError(t, err)
+	assert.Equal(t, time.UTC, tariff.Location)
+}
+
+// Tests the TariffService.Load return a TariffError when the timezone is not a valid IANA timezone.
+func TestTariffServiceLoadReturnErrorWhenTimezoneIsInvalid(t *testing.T) {
+	defer filet.CleanUp(t)
+
+	tariffFile := writeTariffFile(
+		t,
+		".yaml",
+		"standard_fare: 1.30\n"+
+			"minimum_fare: 3.47\n"+
+			"idle: 11.90\n"+
+			"moving_day: 0.74\n"+
+			"moving_night: 1.30\n"+
+			"timezone: Europe/Atlantis\n",
+	)
+
+	tariffService, _ := GetTariffService(tariffFile)
+	tariff, err := tariffService.Load(tariffFile)
+	assert.Error(t, err)
+
+	assert.Nil(t, tariff)
+	assert.Equal(
+		t,
+		NewTariffError(InvalidTimezone, "provided timezone: Europe/Atlantis is not a valid IANA timezone"),
+		err,
+	)
+}
+
+// Tests the TariffService.Load loads the currency and the rounding mode of a tariff file, defaulting to EUR and half up.
+func TestTariffServiceLoadCurrencyAndRounding(t *testing.T) {
+	defer filet.CleanUp(t)
+
+	prices := "standard_fare: 1.30\n" +
+		"minimum_fare: 3.47\n" +
+		"idle: 11.90\n" +
+		"moving_day: 0.74\n" +
+		"moving_night: 1.30\n"
+
+	chfTariffFile := writeTariffFile(t, ".yaml", prices+"currency: chf\nrounding: up_to_0.05\n")
+	tariffService, _ := GetTariffService(chfTariffFile)
+	tariff, err := tariffService.Load(chfTariffFile)
+	assert.NoError(t, err)
+	assert.Equal(t, *money.NewCurrency("CHF", 2), tariff.Currency)
+	assert.Equal(t, money.UpToNickel, tariff.Rounding)
+
+	defaultTariffFile := writeTariffFile(t, ".yaml", prices)
+	tariffService, _ = GetTariffService(defaultTariffFile)
+	tariff, err = tariffService.Load(defaultTariffFile)
+	assert.NoError(t, err)
+	assert.Equal(t, money.EUR, tariff.Currency)
+	assert.Equal(t, money.HalfUp, tariff.Rounding)
+
+	invalidTariffFile := writeTariffFile(t, ".yaml", prices+"currency: XYZ\n")
+	tariffService, _ = GetTariffService(invalidTariffFile)
+	tariff, err = tariffService.Load(invalidTariffFile)
+	assert.Nil(t, tariff)
+	assert.Equal(t, money.NewMoneyError(money.UnsupportedCurrency, "provided currency: XYZ is not supported"), err)
+
+	invalidTariffFile = writeTariffFile(t, ".yaml", prices+"rounding: down\n")
+	tariffService, _ = GetTariffService(invalidTariffFile)
+	tariff, err = tariffService.Load(invalidTariffFile)
+	assert.Nil(t, tariff)
+	assert.Equal(
+		t,
+		money.NewMoneyError(
+			money.UnsupportedRoundingMode,
+			"provided rounding mode: down, must be one of the: half_up,half_even,up_to_0.05",
+		),
+		err,
+	)
+}
+
+// Tests the TariffService.Load loads the speeds and the free waiting time of a tariff file, with their defaults.
+func TestTariffServiceLoadSpeedsAndWaiting(t *testing.T) {
+	defer filet.CleanUp(t)
+
+	prices := "standard_fare: 1.30\n" +
+		"minimum_fare: 3.47\n" +
+		"idle: 11.90\n" +
+		"moving_day: 0.74\n" +
+		"moving_night: 1.30\n"
+
+	tariffFile := writeTariffFile(t, ".yaml", prices+"idle_speed_kmh: 6\nmax_speed_kmh: 130\nfree_waiting_secs: 120\n")
+	tariffService, _ := GetTariffService(tariffFile)
+	tariff, err := tariffService.Load(tariffFile)
+	assert.NoError(t, err)
+	assert.Equal(t, 6.0, tariff.IdleSpeedKMH)
+	assert.Equal(t, 130.0, tariff.MaxSpeedKMH)
+	assert.Equal(t, 120.0, tariff.FreeWaitingSecs)
+
+	defaultTariffFile := writeTariffFile(t, ".yaml", prices)
+	tariffService, _ = GetTariffService(defaultTariffFile)
+	tariff, err = tariffService.Load(defaultTariffFile)
+	assert.NoError(t, err)
+	assert.Equal(t, rides.MinimumHourKM, tariff.IdleSpeedKMH)
+	assert.Equal(t, rides.MaxKMPerHour, tariff.MaxSpeedKMH)
+	assert.Equal(t, 0.0, tariff.FreeWaitingSecs)
+
+	testCases := []struct {
+		content       string
+		expectedError TariffError
+	}{
+		{
+			content:       "free_waiting_secs: -1\n",
+			expectedError: NewTariffError(NegativeTariffValue, "tariff value: free_waiting_secs is negative: -1"),
+		},
+		{
+			content:       "idle_speed_kmh: -1\n",
+			expectedError: NewTariffError(NegativeTariffValue, "tariff value: idle_speed_kmh is negative: -1"),
+		},
+		{
+			content:       "idle_speed_kmh: 20\nmax_speed_kmh: 20\n",
+			expectedError: NewTariffError(InvalidSpeeds, "max speed: 20 km/h must be above the idle speed: 20 km/h"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		invalidTariffFile := writeTariffFile(t, ".yaml", prices+testCase.content)
+		tariffService, _ = GetTariffService(invalidTariffFile)
+		tariff, err = tariffService.Load(invalidTariffFile)
+		assert.Nil(t, tariff)
+		assert.Equal(t, testCase.expectedError, err)
+	}
+}
+
+// Tests the TariffService.Load loads the billing mode of a tariff file, requiring a meter drop amount for the meter.
+func TestTariffServiceLoadBillingMode(t *testing.T) {
+	defer filet.CleanUp(t)
+
+	prices := "standard_fare: 1.30\n" +
+		"minimum_fare: 3.47\n" +
+		"idle: 11.90\n" +
+		"moving_day: 0.74\n" +
+		"moving_night: 1.30\n"
+
+	meterTariffFile := writeTariffFile(t, ".yaml", prices+"billing_mode: meter\nmeter_drop_amount: 0.10\n")
+	tariffService, _ := GetTariffService(meterTariffFile)
+	tariff, err := tariffService.Load(meterTariffFile)
+	assert.NoError(t, err)
+	assert.Equal(t, MeterBilling, tariff.BillingMode)
+	assert.Equal(t, 0.10, tariff.MeterDropAmount)
+
+	defaultTariffFile := writeTariffFile(t, ".yaml", prices)
+	tariffService, _ = GetTariffService(defaultTariffFile)
+	tariff, err = tariffService.Load(defaultTariffFile)
+	assert.NoError(t, err)
+	assert.Equal(t, SpeedSwitchBilling, tariff.BillingMode)
+
+	testCases := []struct {
+		content       string
+		expectedError TariffError
+	}{
+		{
+			content: "billing_mode: tick\n",
+			expectedError: NewTariffError(
+				UnsupportedBillingMode,
+				"provided billing mode: tick, must be one of the: speed_switch,meter",
+			),
+		},
+		{
+			content: "billing_mode: meter\n",
+			expectedError: NewTariffError(
+				InvalidMeterDropAmount,
+				"tariff value: meter_drop_amount must be above zero for the meter billing mode",
+			),
+		},
+		{
+			content: "billing_mode: meter\nmeter_drop_amount: 0\n",
+			expectedError: NewTariffError(
+				InvalidMeterDropAmount,
+				"tariff value: meter_drop_amount must be above zero for the meter billing mode",
+			),
+		},
+	}
+
+	for _, testCase := range testCases {
+		invalidTariffFile := writeTariffFile(t, ".yaml", prices+testCase.content)
+		tariffService, _ = GetTariffService(invalidTariffFile)
+		tariff, err = tariffService.Load(invalidTariffFile)
+		assert.Nil(t, tariff)
+		assert.Equal(t, testCase.expectedError, err)
+	}
+}
+
+// Tests the LoadLocation resolves an empty timezone to UTC.
+func TestLoadLocationDefaultsToUTC(t *testing.T) {
+	location, err := LoadLocation("")
+	assert.NoError(t, err)
+
+	assert.Equal(t, time.UTC, location)
+}
+
+// Tests the TariffService.Load loads the RateTable of each DayType found in the day_types.
+func TestTariffServiceLoadDayTypes(t *testing.T) {
+	defer filet.CleanUp(t)
+
+	tariffFile := writeTariffFile(
+		t,
+		".yaml",
+		"standard_fare: 1.30\n"+
+			"minimum_fare: 3.47\n"+
+			"idle: 11.90\n"+
+			"moving_day: 0.74\n"+
+			"moving_night: 1.30\n"+
+			"day_types:\n"+
+			"  sunday:\n"+
+			"    idle: 12.90\n"+
+			"    moving_day: 0.84\n"+
+			"    moving_night: 1.40\n"+
+			"  holiday:\n"+
+			"    idle: 13.90\n"+
+			"    moving_day: 0.94\n"+
+			"    moving_night: 1.50\n",
+	)
+
+	tariffService, _ := GetTariffService(tariffFile)
+	tariff, err := tariffService.Load(tariffFile)
+	assert.NoError(t, err)
+
+	assert.Equal(t, *NewRateTable(11.90, 0.74, 1.30), tariff.Rates(Weekday))
+	assert.Equal(t, *NewRateTable(12.90, 0.84, 1.40), tariff.Rates(Sunday))
+	assert.Equal(t, *NewRateTable(13.90, 0.94, 1.50), tariff.Rates(Holiday))
+}
+
+// Tests the TariffService.Load return a TariffError when a day type is unsupported or is missing a rate.
+func TestTariffServiceLoadReturnErrorWhenDayTypeIsInvalid(t *testing.T) {
+	defer filet.CleanUp(t)
+
+	prices := "standard_fare: 1.30\n" +
+		"minimum_fare: 3.47\n" +
+		"idle: 11.90\n" +
+		"moving_day: 0.74\n" +
+		"moving_night: 1.30\n"
+
+	testCases := []struct {
+		dayTypes      string
+		expectedError TariffError
+	}{
+		{
+			dayTypes: "day_types:\n  saturday:\n    idle: 12.90\n    moving_day: 0.84\n    moving_night: 1.40\n",
+			expectedError: NewTariffError(
+				UnsupportedDayType,
+				"provided day type: saturday, must be one of the: sunday,holiday",
+			),
+		},
+		{
+			dayTypes: "day_types:\n  sunday:\n    idle: 12.90\n    moving_day: 0.84\n",
+			expectedError: NewTariffError(
+				MissingTariffValue,
+				"tariff value: day_types.sunday.moving_night is missing",
+			),
+		},
+		{
+			dayTypes: "day_types:\n  holiday:\n    idle: -12.90\n    moving_day: 0.84\n    moving_night: 1.40\n",
+			expectedError: NewTariffError(
+				NegativeTariffValue,
+				"tariff value: day_types.holiday.idle is negative: -12.9",
+			),
+		},
+	}
+
+	for _, testCase := range testCases {
+		tariffFile := writeTariffFile(t, ".yaml", prices+testCase.dayTypes)
+
+		tariffService, _ := GetTariffService(tariffFile)
+		tariff, err := tariffService.Load(tariffFile)
+		assert.Error(t, err)
+
+		assert.Nil(t, tariff)
+		assert.Equal(t, testCase.expectedError, err)
+	}
+}
+
+// Tests the LoadTariffZones loads each zone along with the Tariff that its tariff property points to.
+func TestLoadTariffZonesSuccessfulExecution(t *testing.T) {
+	defer filet.CleanUp(t)
+
+	dir := filet.TmpDir(t, "")
+	filet.File(
+		t,
+		filepath.Join(dir, "athens.yaml"),
+		"standard_fare: 1.30\nminimum_fare: 3.47\nidle: 11.90\nmoving_day: 0.74\nmoving_night: 1.30\n",
+	)
+	filet.File(
+		t,
+		filepath.Join(dir, "thessaloniki.json"),
+		`{"standard_fare": 1.20, "minimum_fare": 3.30, "idle": 11.00, "moving_day": 0.70, "moving_night": 1.20}`,
+	)
+	zonesFilePath := filepath.Join(dir, "zones.geojson")
+	filet.File(
+		t,
+		zonesFilePath,
+		`{"type": "FeatureCollection", "features": [
+			{"type": "Feature", "properties": {"name": "athens", "tariff": "athens.yaml"},
+			 "geometry": {"type": "Polygon", "coordinates": [[[23.65, 37.90], [23.80, 37.90], [23.80, 38.05], [23.65, 37.90]]]}},
+			{"type": "Feature", "properties": {"name": "thessaloniki", "tariff": "thessaloniki.json"},
+			 "geometry": {"type": "Polygon", "coordinates": [[[22.85, 40.55], [23.05, 40.55], [23.05, 40.70], [22.85, 40.55]]]}},
+			{"type": "Feature", "properties": {"name": "piraeus", "tariff": "athens.yaml"},
+			 "geometry": {"type": "Polygon", "coordinates": [[[23.60, 37.93], [23.65, 37.93], [23.65, 37.96], [23.60, 37.93]]]}}
+		]}`,
+	)
+
+	tariffZones, err := LoadTariffZones(zonesFilePath, money.EUR, SpeedSwitchBilling)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 3, len(tariffZones))
+	assert.Equal(t, "athens", tariffZones[0].Zone.Name)
+	assert.Equal(t, DefaultTariff(), tariffZones[0].Tariff)
+	assert.Equal(t, "thessaloniki", tariffZones[1].Zone.Name)
+	assert.Equal(t, NewTariff(1.20, 3.30, 11.00, 0.70, 1.20), tariffZones[1].Tariff)
+	assert.Equal(t, "piraeus", tariffZones[2].Zone.Name)
+	assert.Same(t, tariffZones[0].Tariff, tariffZones[2].Tariff)
+}
+
+// Tests the LoadTariffZones return a TariffError when a zone has no tariff property.
+func TestLoadTariffZonesReturnErrorWhenZoneHasNoTariff(t *testing.T) {
+	defer filet.CleanUp(t)
+
+	dir := filet.TmpDir(t, "")
+	zonesFilePath := filepath.Join(dir, "zones.geojson")
+	filet.File(
+		t,
+		zonesFilePath,
+		`{"type": "FeatureCollection", "features": [
+			{"type": "Feature", "properties": {"name": "athens"},
+			 "geometry": {"type": "Polygon", "coordinates": [[[23.65, 37.90], [23.80, 37.90], [23.80, 38.05], [23.65, 37.90]]]}}
+		]}`,
+	)
+
+	tariffZones, err := LoadTariffZones(zonesFilePath, money.EUR, SpeedSwitchBilling)
+	assert.Error(t, err)
+
+	assert.Nil(t, tariffZones)
+	assert.Equal(t, NewTariffError(MissingZoneTariff, "zone: athens has no tariff property"), err)
+}
+
+// Tests the LoadTariffZones return a TariffError when a zone Tariff is not in the currency.
+func TestLoadTariffZonesReturnErrorWhenCurrencyIsMismatched(t *testing.T) {
+	defer filet.CleanUp(t)
+
+	dir := filet.TmpDir(t, "")
+	filet.File(
+		t,
+		filepath.Join(dir, "bucharest.yaml"),
+		"standard_fare: 1.60\nminimum_fare: 4.00\nidle: 15.00\nmoving_day: 0.90\nmoving_night: 1.10\ncurrency: RON\n",
+	)
+	zonesFilePath := filepath.Join(dir, "zones.geojson")
+	filet.File(
+		t,
+		zonesFilePath,
+		`{"type": "FeatureCollection", "features": [
+			{"type": "Feature", "properties": {"name": "bucharest", "tariff": "bucharest.yaml"},
+			 "geometry": {"type": "Polygon", "coordinates": [[[26.00, 44.40], [26.20, 44.40], [26.20, 44.50], [26.00, 44.40]]]}}
+		]}`,
+	)
+
+	tariffZones, err := LoadTariffZones(zonesFilePath, money.EUR, SpeedSwitchBilling)
+	assert.Error(t, err)
+
+	assert.Nil(t, tariffZones)
+	assert.Equal(
+		t,
+		NewTariffError(MismatchedCurrency, "zone: bucharest tariff currency: RON must be the tariff currency: EUR"),
+		err,
+	)
+}
+
+// Tests the LoadTariffZones return a TariffError when a zone Tariff is not in the billing mode.
+func TestLoadTariffZonesReturnErrorWhenBillingModeIsMismatched(t *testing.T) {
+	defer filet.CleanUp(t)
+
+	dir := filet.TmpDir(t, "")
+	filet.File(
+		t,
+		filepath.Join(dir, "athens.yaml"),
+		"standard_fare: 1.30\nminimum_fare: 3.47\nidle: 11.90\nmoving_day: 0.74\nmoving_night: 1.30\n"+
+			"billing_mode: meter\nmeter_drop_amount: 0.10\n",
+	)
+	zonesFilePath := filepath.Join(dir, "zones.geojson")
+	filet.File(
+		t,
+		zonesFilePath,
+		`{"type": "FeatureCollection", "features": [
+			{"type": "Feature", "properties": {"name": "athens", "tariff": "athens.yaml"},
+			 "geometry": {"type": "Polygon", "coordinates": [[[23.65, 37.90], [23.80, 37.90], [23.80, 38.05], [23.65, 37.90]]]}}
+		]}`,
+	)
+
+	tariffZones, err := LoadTariffZones(zonesFilePath, money.EUR, SpeedSwitchBilling)
+	assert.Error(t, err)
+
+	assert.Nil(t, tariffZones)
+	assert.Equal(
+		t,
+		NewTariffError(
+			MismatchedBillingMode,
+			"zone: athens tariff billing mode: meter must be the tariff billing mode: speed_switch",
+		),
+		err,
+	)
+
+	tariffZones, err = LoadTariffZones(zonesFilePath, money.EUR, MeterBilling)
+	assert.NoError(t, err)
+	assert.Len(t, tariffZones, 1)
+}
+
+// Tests the LoadTariffZones return a TariffError when a zone Tariff sets a max speed.
+func TestLoadTariffZonesReturnErrorWhenMaxSpeedIsSet(t *testing.T) {
+	defer filet.CleanUp(t)
+
+	dir := filet.TmpDir(t, "")
+	filet.File(
+		t,
+		filepath.Join(dir, "athens.yaml"),
+		"standard_fare: 1.30\nminimum_fare: 3.47\nidle: 11.90\nmoving_day: 0.74\nmoving_night: 1.30\nmax_speed_kmh: 130\n",
+	)
+	zonesFilePath := filepath.Join(dir, "zones.geojson")
+	filet.File(
+		t,
+		zonesFilePath,
+		`{"type": "FeatureCollection", "features": [
+			{"type": "Feature", "properties": {"name": "athens", "tariff": "athens.yaml"},
+			 "geometry": {"type": "Polygon", "coordinates": [[[23.65, 37.90], [23.80, 37.90], [23.80, 38.05], [23.65, 37.90]]]}}
+		]}`,
+	)
+
+	tariffZones, err := LoadTariffZones(zonesFilePath, money.EUR, SpeedSwitchBilling)
+	assert.Error(t, err)
+
+	assert.Nil(t, tariffZones)
+	assert.Equal(
+		t,
+		NewTariffError(
+			UnsupportedMaxSpeed,
+			"zone: athens tariff must not set max_speed_kmh, only the tariff's own max speed applies",
+		),
+		err,
+	)
+}
+
+// Tests the LoadTariffVersions loads each version along with the Tariff it points to, sorted by their effective_from.
+func TestLoadTariffVersionsSuccessfulExecution(t *testing.T) {
+	defer filet.CleanUp(t)
+
+	dir := filet.TmpDir(t, "")
+	filet.File(
+		t,
+		filepath.Join(dir, "2022-01.yaml"),
+		"standard_fare: 1.30\nminimum_fare: 3.47\nidle: 11.90\nmoving_day: 0.74\nmoving_night: 1.30\n",
+	)
+	filet.File(
+		t,
+		filepath.Join(dir, "2022-03.json"),
+		`{"standard_fare": 1.40, "minimum_fare": 3.60, "idle": 12.50, "moving_day": 0.80, "moving_night": 1.40}`,
+	)
+	versionsFilePath := filepath.Join(dir, "versions.yaml")
+	filet.File(
+		t,
+		versionsFilePath,
+		"versions:\n"+
+			"  - id: 2022-03\n    effective_from: 2022-03-15T00:00:00+02:00\n    tariff: 2022-03.json\n"+
+			"  - id: 2022-01\n    effective_from: 2022-01-01T00:00:00+02:00\n    tariff: 2022-01.yaml\n",
+	)
+
+	tariffVersions, err := LoadTariffVersions(versionsFilePath)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, len(tariffVersions))
+	assert.Equal(t, "2022-01", tariffVersions[0].ID)
+	assert.Equal(t, int64(1640988000), tariffVersions[0].EffectiveFrom.Unix())
+	assert.Equal(t, DefaultTariff(), tariffVersions[0].Tariff)
+	assert.Equal(t, "2022-03", tariffVersions[1].ID)
+	assert.Equal(t, int64(1647295200), tariffVersions[1].EffectiveFrom.Unix())
+	assert.Equal(t, NewTariff(1.40, 3.60, 12.50, 0.80, 1.40), tariffVersions[1].Tariff)
+}
+
+// Tests the LoadTariffVersions return a TariffError when a version is invalid or defined more than once.
+func TestLoadTariffVersionsReturnErrorWhenVersionIsInvalid(t *testing.T) {
+	defer filet.CleanUp(t)
+
+	dir := filet.TmpDir(t, "")
+	filet.File(
+		t,
+		filepath.Join(dir, "tariff.yaml"),
+		"standard_fare: 1.30\nminimum_fare: 3.47\nidle: 11.90\nmoving_day: 0.74\nmoving_night: 1.30\n",
+	)
+	filet.File(
+		t,
+		filepath.Join(dir, "fast.yaml"),
+		"standard_fare: 1.30\nminimum_fare: 3.47\nidle: 11.90\nmoving_day: 0.74\nmoving_night: 1.30\nmax_speed_kmh: 130\n",
+	)
+
+	for content, expectedErr := range map[string]error{
+		"versions: []\n": NewTariffError(
+			InvalidTariffVersion,
+			"tariff versions file: "+filepath.Join(dir, "versions.yaml")+" has no versions",
+		),
+		"versions:\n  - effective_from: 2022-01-01T00:00:00Z\n    tariff: tariff.yaml\n": NewTariffError(
+			InvalidTariffVersion,
+			"tariff version has no id",
+		),
+		"versions:\n  - id: a\n    effective_from: 2022-01-01\n    tariff: tariff.yaml\n": NewTariffError(
+			InvalidTariffVersion,
+			"tariff version: a effective_from: 2022-01-01 is not an RFC 3339 time",
+		),
+		"versions:\n  - id: a\n    effective_from: 2022-01-01T00:00:00Z\n": NewTariffError(
+			InvalidTariffVersion,
+			"tariff version: a has no tariff",
+		),
+		"versions:\n" +
+			"  - id: a\n    effective_from: 2022-01-01T00:00:00Z\n    tariff: tariff.yaml\n" +
+			"  - id: a\n    effective_from: 2022-02-01T00:00:00Z\n    tariff: tariff.yaml\n": NewTariffError(
+			DuplicateTariffVersion,
+			"tariff version: a is defined more than once",
+		),
+		"versions:\n" +
+			"  - id: a\n    effective_from: 2022-01-01T02:00:00+02:00\n    tariff: tariff.yaml\n" +
+			"  - id: b\n    effective_from: 2022-01-01T00:00:00Z\n    tariff: tariff.yaml\n": NewTariffError(
+			DuplicateTariffVersion,
+			"tariff versions: a and b have the same effective_from",
+		),
+		"versions:\n  - id: a\n    effective_from: 2022-01-01T00:00:00Z\n    tariff: fast.yaml\n": NewTariffError(
+			UnsupportedMaxSpeed,
+			"tariff version: a tariff must not set max_speed_kmh, only the tariff's own max speed applies",
+		),
+	} {
+		versionsFilePath := filepath.Join(dir, "versions.yaml")
+		if err := os.WriteFile(versionsFilePath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		tariffVersions, err := LoadTariffVersions(versionsFilePath)
+		assert.Error(t, err)
+
+		assert.Nil(t, tariffVersions)
+		assert.Equal(t, expectedErr, err)
+	}
+}
+
+// Tests the VersionAt returns the TariffVersion in force at the timestamp, or nil before the first of them.
+func TestVersionAt(t *testing.T) {
+	tariffVersions := []TariffVersion{
+		*NewTariffVersion("2022-01", time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), DefaultTariff()),
+		*NewTariffVersion("2022-03", time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC), DefaultTariff()),
+	}
+
+	assert.Nil(t, VersionAt(tariffVersions, 1640995199))
+	assert.Equal(t, "2022-01", VersionAt(tariffVersions, 1640995200).ID)
+	assert.Equal(t, "2022-01", VersionAt(tariffVersions, 1646092799).ID)
+	assert.Equal(t, "2022-03", VersionAt(tariffVersions, 1646092800).ID)
+	assert.Same(t, &tariffVersions[1], VersionAt(tariffVersions, 1700000000))
+	assert.Nil(t, VersionAt(nil, 1700000000))
+}
@@ -0,0 +1,28 @@
+/*
+Package tolls
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package tolls
+
+import (
+	"errors"
+	baseAppErrors "github.com/iliaskaras/fare-estimation/app/infrastructure/errors"
+)
+
+type TollError struct {
+	baseAppErrors.BaseAppError
+}
+
+func NewTollError(err error, additionalInfo string) TollError {
+	return TollError{
+		BaseAppError: baseAppErrors.NewBaseAppError(err, additionalInfo),
+	}
+}
+
+var (
+	MissingTollZones    = errors.New("missing toll zones")
+	UnknownTollZone     = errors.New("unknown toll zone")
+	InvalidTollGate     = errors.New("invalid toll gate")
+	InvalidTollAmount   = errors.New("invalid toll amount")
+	InvalidTollDebounce = errors.New("invalid toll debounce")
+)
@@ -0,0 +1,20 @@
+/*
+Package tolls
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package tolls
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/infrastructure/configs"
+)
+
+// GetTollService is responsible for initializing and injecting all the dependencies
+// of the TollService, based on the tolls file type provided.
+func GetTollService(filePath string) (*TollService, error) {
+	configDecoder, err := configs.GetConfigDecoder(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTollService(configDecoder), nil
+}
@@ -0,0 +1,29 @@
+/*
+Package tolls
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package tolls
+
+import (
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+// Tests the GetTollService return the TollService for the .yaml, .yml and .json files.
+func TestGetTollService(t *testing.T) {
+	for _, filePath := range []string{"tolls.yaml", "tolls.yml", "tolls.json"} {
+		tollService, err := GetTollService(filePath)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "*tolls.TollService", reflect.TypeOf(tollService).String())
+	}
+}
+
+// Tests the GetTollService return an error when the tolls file type is unsupported.
+func TestGetTollServiceReturnErrorWhenFileTypeIsInvalid(t *testing.T) {
+	tollService, err := GetTollService("tolls.csv")
+	assert.Error(t, err)
+
+	assert.Nil(t, tollService)
+}
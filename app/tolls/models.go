@@ -0,0 +1,57 @@
+/*
+Package tolls
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package tolls
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/zones"
+)
+
+// DefaultDebounceSecs is the time after a crossing of a Toll's gate within which it is not charged again.
+const DefaultDebounceSecs int64 = 300
+
+// Toll is a toll gate, or any other surcharge point, that adds its Amount to the fare of each ride
+// crossing it. The gate is either a Zone, crossed when a ride passes into or through its polygons,
+// or a Line, crossed when a ride passes over any of its sections.
+// - DebounceSecs: The time after a crossing within which the gate is not charged again.
+type Toll struct {
+	Name         string
+	Zone         *zones.Zone
+	Line         []zones.Point
+	Amount       float64
+	DebounceSecs int64
+}
+
+func NewToll(name string, zone *zones.Zone, line []zones.Point, amount float64, debounceSecs int64) *Toll {
+	return &Toll{
+		name,
+		zone,
+		line,
+		amount,
+		debounceSecs,
+	}
+}
+
+// Crosses returns whether the straight path between the from and the to positions crosses the Toll's gate.
+// A Zone gate is crossed when the path enters it, or passes through it without any of its ends falling in.
+func (t *Toll) Crosses(from, to zones.Point) bool {
+	if t.Zone != nil {
+		if t.Zone.Contains(from.Lat, from.Lng) {
+			return false
+		}
+		if t.Zone.Contains(to.Lat, to.Lng) {
+			return true
+		}
+
+		for _, polygon := range t.Zone.Polygons {
+			if len(polygon) > 0 && pathCrossesRing(polygon[0], from, to) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return pathCrossesLine(t.Line, from, to)
+}
@@ -0,0 +1,60 @@
+/*
+Package tolls
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package tolls
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/zones"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// Tests the Toll Crosses method on a line gate.
+func TestTollCrossesLine(t *testing.T) {
+	toll := NewToll(
+		"metamorfosi",
+		nil,
+		[]zones.Point{{Lat: 38.00, Lng: 23.60}, {Lat: 38.05, Lng: 23.60}, {Lat: 38.05, Lng: 23.65}},
+		2.80,
+		DefaultDebounceSecs,
+	)
+
+	// Over the first and the second section of the line, in both directions.
+	assert.Equal(t, true, toll.Crosses(zones.Point{Lat: 38.02, Lng: 23.59}, zones.Point{Lat: 38.02, Lng: 23.61}))
+	assert.Equal(t, true, toll.Crosses(zones.Point{Lat: 38.02, Lng: 23.61}, zones.Point{Lat: 38.02, Lng: 23.59}))
+	assert.Equal(t, true, toll.Crosses(zones.Point{Lat: 38.04, Lng: 23.62}, zones.Point{Lat: 38.06, Lng: 23.62}))
+	// Starting on the line, while ending on it is left to the path starting there, and standing on it.
+	assert.Equal(t, true, toll.Crosses(zones.Point{Lat: 38.02, Lng: 23.60}, zones.Point{Lat: 38.02, Lng: 23.61}))
+	assert.Equal(t, false, toll.Crosses(zones.Point{Lat: 38.02, Lng: 23.59}, zones.Point{Lat: 38.02, Lng: 23.60}))
+	assert.Equal(t, false, toll.Crosses(zones.Point{Lat: 38.02, Lng: 23.60}, zones.Point{Lat: 38.02, Lng: 23.60}))
+	// Ending on the end of a section.
+	assert.Equal(t, false, toll.Crosses(zones.Point{Lat: 38.05, Lng: 23.59}, zones.Point{Lat: 38.05, Lng: 23.60}))
+	// Along one side of the line, and past its end.
+	assert.Equal(t, false, toll.Crosses(zones.Point{Lat: 38.01, Lng: 23.59}, zones.Point{Lat: 38.04, Lng: 23.59}))
+	assert.Equal(t, false, toll.Crosses(zones.Point{Lat: 37.99, Lng: 23.59}, zones.Point{Lat: 37.99, Lng: 23.61}))
+}
+
+// Tests the Toll Crosses method on a zone gate.
+func TestTollCrossesZone(t *testing.T) {
+	zone := zones.NewZone(
+		"elefsina-gate",
+		[]zones.Polygon{{{
+			{Lat: 38.00, Lng: 23.60},
+			{Lat: 38.00, Lng: 23.61},
+			{Lat: 38.01, Lng: 23.61},
+			{Lat: 38.01, Lng: 23.60},
+			{Lat: 38.00, Lng: 23.60},
+		}}},
+		nil,
+	)
+	toll := NewToll("elefsina", zone, nil, 2.80, DefaultDebounceSecs)
+
+	// Entering the zone, and passing through it.
+	assert.Equal(t, true, toll.Crosses(zones.Point{Lat: 38.005, Lng: 23.59}, zones.Point{Lat: 38.005, Lng: 23.605}))
+	assert.Equal(t, true, toll.Crosses(zones.Point{Lat: 38.005, Lng: 23.59}, zones.Point{Lat: 38.005, Lng: 23.62}))
+	// Leaving the zone, moving within it and passing by it.
+	assert.Equal(t, false, toll.Crosses(zones.Point{Lat: 38.005, Lng: 23.605}, zones.Point{Lat: 38.005, Lng: 23.62}))
+	assert.Equal(t, false, toll.Crosses(zones.Point{Lat: 38.003, Lng: 23.605}, zones.Point{Lat: 38.007, Lng: 23.605}))
+	assert.Equal(t, false, toll.Crosses(zones.Point{Lat: 38.02, Lng: 23.59}, zones.Point{Lat: 38.02, Lng: 23.62}))
+}
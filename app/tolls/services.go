@@ -0,0 +1,178 @@
+/*
+Package tolls
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package tolls
+
+import (
+	"fmt"
+	"github.com/iliaskaras/fare-estimation/app/infrastructure/configs"
+	"github.com/iliaskaras/fare-estimation/app/rides"
+	"github.com/iliaskaras/fare-estimation/app/zones"
+	"path/filepath"
+)
+
+// tollsDefinition is the tolls file, referring to the gate zones of a GeoJSON file by their name.
+type tollsDefinition struct {
+	Zones string           `json:"zones" yaml:"zones"`
+	Tolls []tollDefinition `json:"tolls" yaml:"tolls"`
+}
+
+type tollDefinition struct {
+	Name         string            `json:"name" yaml:"name"`
+	Zone         string            `json:"zone" yaml:"zone"`
+	Line         []pointDefinition `json:"line" yaml:"line"`
+	Amount       *float64          `json:"amount" yaml:"amount"`
+	DebounceSecs *int64            `json:"debounce_secs" yaml:"debounce_secs"`
+}
+
+type pointDefinition struct {
+	Lat float64 `json:"lat" yaml:"lat"`
+	Lng float64 `json:"lng" yaml:"lng"`
+}
+
+type TollService struct {
+	configDecoder configs.ConfigDecoder
+}
+
+func NewTollService(configDecoder configs.ConfigDecoder) *TollService {
+	return &TollService{
+		configDecoder: configDecoder,
+	}
+}
+
+// Load reads the tolls file found in filePath, along with the GeoJSON zones file that it points to,
+// and returns its Toll. Every toll must have either a zone or a line of at least two points as its
+// gate, and an amount, while its debounce_secs, the DefaultDebounceSecs if not given, must not be
+// negative. A relative zones file path is resolved against the directory of the tolls file, and it
+// is only required when any of the tolls refers to a zone.
+func (ts *TollService) Load(filePath string) ([]Toll, error) {
+	var definition tollsDefinition
+
+	if err := ts.configDecoder.Decode(filePath, &definition); err != nil {
+		return nil, err
+	}
+
+	zonesByName, err := loadZones(filePath, definition)
+	if err != nil {
+		return nil, err
+	}
+
+	var tolls []Toll
+
+	for _, toll := range definition.Tolls {
+		if (toll.Zone == "") == (len(toll.Line) == 0) {
+			return nil, NewTollError(InvalidTollGate, "toll: "+toll.Name+" must have either a zone or a line")
+		}
+
+		var zone *zones.Zone
+		var line []zones.Point
+
+		if toll.Zone != "" {
+			tollZone, ok := zonesByName[toll.Zone]
+			if !ok {
+				return nil, NewTollError(UnknownTollZone, "toll: "+toll.Name+" zone: "+toll.Zone+" is unknown")
+			}
+			zone = &tollZone
+		} else {
+			if len(toll.Line) < 2 {
+				return nil, NewTollError(InvalidTollGate, "toll: "+toll.Name+" line must have at least two points")
+			}
+			for _, point := range toll.Line {
+				line = append(line, *zones.NewPoint(point.Lat, point.Lng))
+			}
+		}
+
+		if toll.Amount == nil || *toll.Amount < 0 {
+			return nil, NewTollError(
+				InvalidTollAmount,
+				fmt.Sprintf("toll: %s must have an amount that is not negative", toll.Name),
+			)
+		}
+
+		debounceSecs := DefaultDebounceSecs
+		if toll.DebounceSecs != nil {
+			if *toll.DebounceSecs < 0 {
+				return nil, NewTollError(
+					InvalidTollDebounce,
+					fmt.Sprintf("toll: %s must have a debounce_secs that is not negative", toll.Name),
+				)
+			}
+			debounceSecs = *toll.DebounceSecs
+		}
+
+		tolls = append(tolls, *NewToll(toll.Name, zone, line, *toll.Amount, debounceSecs))
+	}
+
+	return tolls, nil
+}
+
+// Detect returns the tolls crossed by the ride, in the order they are crossed, checking the path
+// between the positions of each of its filtered RideSegments. A toll crossed more than once is
+// returned once for every crossing, apart from the crossings within the DebounceSecs of the
+// toll's previous crossing, so that GPS jitter around a gate is not charged again.
+func Detect(tolls []Toll, rideSegments []rides.RideSegment) []Toll {
+	var crossedTolls []Toll
+	lastCrossings := make(map[int]int64)
+
+	for _, rideSegment := range rideSegments {
+		from := *zones.NewPoint(rideSegment.RidePositions[0].Lat, rideSegment.RidePositions[0].Lng)
+		to := *zones.NewPoint(rideSegment.RidePositions[1].Lat, rideSegment.RidePositions[1].Lng)
+		timestamp := rideSegment.RidePositions[0].Timestamp
+
+		for i := range tolls {
+			if !tolls[i].Crosses(from, to) {
+				continue
+			}
+
+			lastCrossing, crossed := lastCrossings[i]
+			lastCrossings[i] = timestamp
+			if crossed && timestamp-lastCrossing < tolls[i].DebounceSecs {
+				continue
+			}
+
+			crossedTolls = append(crossedTolls, tolls[i])
+		}
+	}
+
+	return crossedTolls
+}
+
+// loadZones loads the zones file that the tolls definition points to, keyed by the zone names.
+func loadZones(filePath string, definition tollsDefinition) (map[string]zones.Zone, error) {
+	zonesByName := make(map[string]zones.Zone)
+
+	if definition.Zones == "" {
+		for _, toll := range definition.Tolls {
+			if toll.Zone != "" {
+				return nil, NewTollError(
+					MissingTollZones,
+					"toll: "+toll.Name+" refers to a zone, but the tolls file does not point to a zones file",
+				)
+			}
+		}
+
+		return zonesByName, nil
+	}
+
+	zonesPath := definition.Zones
+	if !filepath.IsAbs(zonesPath) {
+		zonesPath = filepath.Join(filepath.Dir(filePath), zonesPath)
+	}
+
+	zoneService, err := zones.GetZoneService(zonesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	loadedZones, err := zoneService.Load(zonesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, zone := range loadedZones {
+		zonesByName[zone.Name] = zone
+	}
+
+	return zonesByName, nil
+}
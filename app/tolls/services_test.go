@@ -0,0 +1,275 @@
+/*
+Package tolls
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package tolls
+
+import (
+	"github.com/Flaque/filet"
+	"github.com/iliaskaras/fare-estimation/app/infrastructure/configs"
+	"github.com/iliaskaras/fare-estimation/app/rides"
+	"github.com/iliaskaras/fare-estimation/app/zones"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testZones = `{
+	"type": "FeatureCollection",
+	"features": [
+		{
+			"type": "Feature",
+			"properties": {"name": "elefsina-gate"},
+			"geometry": {
+				"type": "Polygon",
+				"coordinates": [[[23.60, 38.00], [23.61, 38.00], [23.61, 38.01], [23.60, 38.01], [23.60, 38.00]]]
+			}
+		}
+	]
+}`
+
+// writeTollsFiles creates a temporary zones file and a tolls file with the given content next to it,
+// returning the tolls file path.
+func writeTollsFiles(t *testing.T, content string) string {
+	dir := filet.TmpDir(t, "")
+	if err := os.WriteFile(filepath.Join(dir, "zones.geojson"), []byte(testZones), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	filePath := filepath.Join(dir, "tolls.yaml")
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return filePath
+}
+
+// Tests the TollService.Load loads the zone and the line Toll of a tolls file.
+func TestTollServiceLoadSuccessfulExecution(t *testing.T) {
+	defer filet.CleanUp(t)
+	filePath := writeTollsFiles(
+		t,
+		"zones: zones.geojson\n"+
+			"tolls:\n"+
+			"  - name: elefsina\n"+
+			"    zone: elefsina-gate\n"+
+			"    amount: 2.80\n"+
+			"  - name: metamorfosi\n"+
+			"    line:\n"+
+			"      - {lat: 38.00, lng: 23.70}\n"+
+			"      - {lat: 38.05, lng: 23.70}\n"+
+			"    amount: 1.90\n"+
+			"    debounce_secs: 60\n",
+	)
+
+	tollService, _ := GetTollService(filePath)
+	tolls, err := tollService.Load(filePath)
+	assert.NoError(t, err)
+
+	assert.Len(t, tolls, 2)
+	assert.Equal(t, "elefsina", tolls[0].Name)
+	assert.Equal(t, "elefsina-gate", tolls[0].Zone.Name)
+	assert.Nil(t, tolls[0].Line)
+	assert.Equal(t, 2.80, tolls[0].Amount)
+	assert.Equal(t, DefaultDebounceSecs, tolls[0].DebounceSecs)
+	assert.Equal(t, "metamorfosi", tolls[1].Name)
+	assert.Nil(t, tolls[1].Zone)
+	assert.Equal(t, []zones.Point{{Lat: 38.00, Lng: 23.70}, {Lat: 38.05, Lng: 23.70}}, tolls[1].Line)
+	assert.Equal(t, 1.90, tolls[1].Amount)
+	assert.Equal(t, int64(60), tolls[1].DebounceSecs)
+}
+
+// Tests the TollService.Load returns a TollError on invalid tolls.
+func TestTollServiceLoadReturnTollErrorOnInvalidTolls(t *testing.T) {
+	defer filet.CleanUp(t)
+
+	testCases := []struct {
+		content       string
+		expectedError TollError
+	}{
+		{
+			content: "tolls:\n" +
+				"  - name: elefsina\n" +
+				"    zone: elefsina-gate\n" +
+				"    amount: 2.80\n",
+			expectedError: NewTollError(
+				MissingTollZones,
+				"toll: elefsina refers to a zone, but the tolls file does not point to a zones file",
+			),
+		},
+		{
+			content: "zones: zones.geojson\n" +
+				"tolls:\n" +
+				"  - name: afidnes\n" +
+				"    zone: afidnes-gate\n" +
+				"    amount: 2.80\n",
+			expectedError: NewTollError(UnknownTollZone, "toll: afidnes zone: afidnes-gate is unknown"),
+		},
+		{
+			content: "tolls:\n" +
+				"  - name: metamorfosi\n" +
+				"    amount: 1.90\n",
+			expectedError: NewTollError(InvalidTollGate, "toll: metamorfosi must have either a zone or a line"),
+		},
+		{
+			content: "zones: zones.geojson\n" +
+				"tolls:\n" +
+				"  - name: elefsina\n" +
+				"    zone: elefsina-gate\n" +
+				"    line:\n" +
+				"      - {lat: 38.00, lng: 23.70}\n" +
+				"      - {lat: 38.05, lng: 23.70}\n" +
+				"    amount: 2.80\n",
+			expectedError: NewTollError(InvalidTollGate, "toll: elefsina must have either a zone or a line"),
+		},
+		{
+			content: "tolls:\n" +
+				"  - name: metamorfosi\n" +
+				"    line:\n" +
+				"      - {lat: 38.00, lng: 23.70}\n" +
+				"    amount: 1.90\n",
+			expectedError: NewTollError(InvalidTollGate, "toll: metamorfosi line must have at least two points"),
+		},
+		{
+			content: "tolls:\n" +
+				"  - name: metamorfosi\n" +
+				"    line:\n" +
+				"      - {lat: 38.00, lng: 23.70}\n" +
+				"      - {lat: 38.05, lng: 23.70}\n",
+			expectedError: NewTollError(InvalidTollAmount, "toll: metamorfosi must have an amount that is not negative"),
+		},
+		{
+			content: "tolls:\n" +
+				"  - name: metamorfosi\n" +
+				"    line:\n" +
+				"      - {lat: 38.00, lng: 23.70}\n" +
+				"      - {lat: 38.05, lng: 23.70}\n" +
+				"    amount: -1.90\n",
+			expectedError: NewTollError(InvalidTollAmount, "toll: metamorfosi must have an amount that is not negative"),
+		},
+		{
+			content: "tolls:\n" +
+				"  - name: metamorfosi\n" +
+				"    line:\n" +
+				"      - {lat: 38.00, lng: 23.70}\n" +
+				"      - {lat: 38.05, lng: 23.70}\n" +
+				"    amount: 1.90\n" +
+				"    debounce_secs: -60\n",
+			expectedError: NewTollError(
+				InvalidTollDebounce,
+				"toll: metamorfosi must have a debounce_secs that is not negative",
+			),
+		},
+	}
+
+	for _, testCase := range testCases {
+		filePath := writeTollsFiles(t, testCase.content)
+		configDecoder, _ := configs.GetConfigDecoder(filePath)
+
+		tolls, err := NewTollService(configDecoder).Load(filePath)
+		assert.Error(t, err)
+
+		assert.Nil(t, tolls)
+		assert.Equal(t, testCase.expectedError, err)
+	}
+}
+
+// rideSegmentsOf returns the RideSegments between each of the consecutive positions.
+func rideSegmentsOf(positions []rides.RidePosition) []rides.RideSegment {
+	var rideSegments []rides.RideSegment
+	for i := 1; i < len(positions); i++ {
+		rideSegments = append(
+			rideSegments,
+			rides.RideSegment{RideID: 1, RidePositions: [2]rides.RidePosition{positions[i-1], positions[i]}},
+		)
+	}
+
+	return rideSegments
+}
+
+// Tests the Detect returns the tolls crossed by the ride segments, once for every crossing.
+func TestDetect(t *testing.T) {
+	line := *NewToll(
+		"metamorfosi",
+		nil,
+		[]zones.Point{{Lat: 38.00, Lng: 23.70}, {Lat: 38.05, Lng: 23.70}},
+		1.90,
+		DefaultDebounceSecs,
+	)
+	zone := *NewToll(
+		"elefsina",
+		zones.NewZone(
+			"elefsina-gate",
+			[]zones.Polygon{{{{Lat: 38.00, Lng: 23.60}, {Lat: 38.00, Lng: 23.61}, {Lat: 38.01, Lng: 23.61}, {Lat: 38.00, Lng: 23.60}}}},
+			nil,
+		),
+		nil,
+		2.80,
+		DefaultDebounceSecs,
+	)
+	tolls := []Toll{line, zone}
+
+	rideSegments := rideSegmentsOf([]rides.RidePosition{
+		{Id: 1, Lat: 38.02, Lng: 23.71, Timestamp: 1405594957},
+		{Id: 1, Lat: 38.02, Lng: 23.69, Timestamp: 1405595557},
+		{Id: 1, Lat: 38.03, Lng: 23.59, Timestamp: 1405596157},
+		{Id: 1, Lat: 38.003, Lng: 23.607, Timestamp: 1405596757},
+		{Id: 1, Lat: 38.02, Lng: 23.72, Timestamp: 1405597357},
+	})
+
+	assert.Equal(t, []Toll{line, zone, line}, Detect(tolls, rideSegments))
+	assert.Nil(t, Detect(tolls, rideSegments[1:2]))
+	assert.Nil(t, Detect(nil, rideSegments))
+}
+
+// Tests the Detect charges a position lying exactly on a gate once, for the ride segment starting at it.
+func TestDetectPositionOnGate(t *testing.T) {
+	line := *NewToll(
+		"metamorfosi",
+		nil,
+		[]zones.Point{{Lat: 38.00, Lng: 23.70}, {Lat: 38.05, Lng: 23.70}},
+		1.90,
+		0,
+	)
+
+	rideSegments := rideSegmentsOf([]rides.RidePosition{
+		{Id: 1, Lat: 38.02, Lng: 23.69, Timestamp: 1405594957},
+		{Id: 1, Lat: 38.02, Lng: 23.70, Timestamp: 1405595017},
+		{Id: 1, Lat: 38.02, Lng: 23.71, Timestamp: 1405595077},
+	})
+
+	assert.Equal(t, []Toll{line}, Detect([]Toll{line}, rideSegments))
+	assert.Nil(t, Detect([]Toll{line}, rideSegments[:1]))
+}
+
+// Tests the Detect does not charge a gate again for the crossings within its DebounceSecs of the
+// previous one, as with GPS jitter around the gate.
+func TestDetectDebouncesCrossings(t *testing.T) {
+	line := *NewToll(
+		"metamorfosi",
+		nil,
+		[]zones.Point{{Lat: 38.00, Lng: 23.70}, {Lat: 38.05, Lng: 23.70}},
+		1.90,
+		DefaultDebounceSecs,
+	)
+	west := rides.RidePosition{Id: 1, Lat: 38.02, Lng: 23.699}
+	east := rides.RidePosition{Id: 1, Lat: 38.02, Lng: 23.701}
+
+	var positions []rides.RidePosition
+	for i, timestamp := range []int64{0, 10, 20, 30, 40, 400, 410, 1000} {
+		position := west
+		if i%2 == 1 {
+			position = east
+		}
+		position.Timestamp = 1405594957 + timestamp
+		positions = append(positions, position)
+	}
+
+	// The jitter up to 40 is charged once, each crossing being within the DebounceSecs of the previous
+	// one, and so is the jitter from 400 on, being long after it.
+	assert.Equal(t, []Toll{line, line}, Detect([]Toll{line}, rideSegmentsOf(positions)))
+
+	line.DebounceSecs = 0
+	assert.Len(t, Detect([]Toll{line}, rideSegmentsOf(positions)), 7)
+}
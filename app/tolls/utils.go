@@ -0,0 +1,66 @@
+/*
+Package tolls
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package tolls
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/zones"
+	"math"
+)
+
+// pathCrossesRing returns whether the path between the from and the to positions crosses any edge of the linear ring.
+func pathCrossesRing(ring []zones.Point, from, to zones.Point) bool {
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		if segmentsIntersect(from, to, ring[j], ring[i]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pathCrossesLine returns whether the path between the from and the to positions crosses any section of the line.
+func pathCrossesLine(line []zones.Point, from, to zones.Point) bool {
+	for i := 1; i < len(line); i++ {
+		if segmentsIntersect(from, to, line[i-1], line[i]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// segmentsIntersect returns whether the path p1-p2 and the segment q1-q2 intersect, using the orientation
+// of their ends on the lat/lng plane, which is accurate enough for the length of a ride segment. The path
+// leaves its end p2 out, so that a position lying on q1-q2 is only counted by the path starting at it.
+func segmentsIntersect(p1, p2, q1, q2 zones.Point) bool {
+	if p1 == p2 {
+		return false
+	}
+
+	d1 := orientation(q1, q2, p1)
+	d2 := orientation(q1, q2, p2)
+	d3 := orientation(p1, p2, q1)
+	d4 := orientation(p1, p2, q2)
+
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+
+	return (d1 == 0 && onSegment(q1, q2, p1)) ||
+		(d3 == 0 && q1 != p2 && onSegment(p1, p2, q1)) ||
+		(d4 == 0 && q2 != p2 && onSegment(p1, p2, q2))
+}
+
+// orientation returns the cross product of a-b and a-c, positive when c is on the left of a-b,
+// negative when it is on its right and zero when the three points are collinear.
+func orientation(a, b, c zones.Point) float64 {
+	return (b.Lng-a.Lng)*(c.Lat-a.Lat) - (b.Lat-a.Lat)*(c.Lng-a.Lng)
+}
+
+// onSegment returns whether c, being collinear with a-b, falls within the a-b segment.
+func onSegment(a, b, c zones.Point) bool {
+	return c.Lat >= math.Min(a.Lat, b.Lat) && c.Lat <= math.Max(a.Lat, b.Lat) &&
+		c.Lng >= math.Min(a.Lng, b.Lng) && c.Lng <= math.Max(a.Lng, b.Lng)
+}
@@ -0,0 +1,25 @@
+/*
+Package uncertainties
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package uncertainties
+
+import (
+	"errors"
+	baseAppErrors "github.com/iliaskaras/fare-estimation/app/infrastructure/errors"
+)
+
+type UncertaintyError struct {
+	baseAppErrors.BaseAppError
+}
+
+func NewUncertaintyError(err error, additionalInfo string) UncertaintyError {
+	return UncertaintyError{
+		BaseAppError: baseAppErrors.NewBaseAppError(err, additionalInfo),
+	}
+}
+
+var (
+	InvalidAccuracy = errors.New("invalid accuracy")
+	InvalidSamples  = errors.New("invalid samples")
+)
@@ -0,0 +1,62 @@
+/*
+Package uncertainties
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package uncertainties
+
+import (
+	"fmt"
+	"github.com/iliaskaras/fare-estimation/app/distances"
+	"github.com/iliaskaras/fare-estimation/app/fares"
+	"github.com/iliaskaras/fare-estimation/app/rides"
+	"github.com/iliaskaras/fare-estimation/app/tariffs"
+)
+
+// GetUncertaintyService is responsible for initializing and injecting all the dependencies of the
+// UncertaintyService, cleaning the RidePositions with the positionFilters, with the max speed of the config's
+// Tariff unless the positionFilterConfig has one, and estimating the fares with the FareCalculator of the
+// fare strategy. The accuracyMeters and the samples must be above zero.
+func GetUncertaintyService(
+	fareStrategy string,
+	config fares.FareCalculatorConfig,
+	positionFilters string,
+	positionFilterConfig rides.PositionFilterConfig,
+	accuracyMeters float64,
+	samples int,
+	seed int64,
+) (*UncertaintyService, error) {
+	if accuracyMeters <= 0 {
+		return nil, NewUncertaintyError(
+			InvalidAccuracy,
+			fmt.Sprintf("gps accuracy: %v meters must be above zero", accuracyMeters),
+		)
+	}
+	if samples <= 0 {
+		return nil, NewUncertaintyError(InvalidSamples, fmt.Sprintf("samples: %d must be above zero", samples))
+	}
+
+	if config.Tariff == nil {
+		config.Tariff = tariffs.DefaultTariff()
+	}
+
+	fareCalculator, err := fares.GetFareCalculator(fareStrategy, config)
+	if err != nil {
+		return nil, err
+	}
+
+	distanceCalculator, err := distances.GetDistanceCalculatorService(distances.HaversineMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	if positionFilterConfig.MaxKMPerHour <= 0 {
+		positionFilterConfig.MaxKMPerHour = config.Tariff.MaxSpeedKMH
+	}
+
+	ridePositionService, err := rides.GetRidePositionService(distanceCalculator, positionFilters, positionFilterConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewUncertaintyService(ridePositionService, fareCalculator, accuracyMeters, samples, seed), nil
+}
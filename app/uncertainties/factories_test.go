@@ -0,0 +1,77 @@
+/*
+Package uncertainties
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package uncertainties
+
+import (
+	"errors"
+	"github.com/iliaskaras/fare-estimation/app/fares"
+	"github.com/iliaskaras/fare-estimation/app/rides"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+// Tests the GetUncertaintyService initializes and returns the UncertaintyService.
+func TestGetUncertaintyService(t *testing.T) {
+	uncertaintyService, err := GetUncertaintyService(
+		fares.TariffStrategy,
+		fares.FareCalculatorConfig{},
+		rides.SpeedPositionFilter,
+		rides.PositionFilterConfig{},
+		10,
+		100,
+		1,
+	)
+	assert.NoError(t, err)
+
+	returnedServiceType := reflect.TypeOf(uncertaintyService).String()
+	expectedServiceType := "*uncertainties.UncertaintyService"
+
+	assert.Equal(t, expectedServiceType, returnedServiceType)
+	assert.Equal(t, 10.0, uncertaintyService.accuracyMeters)
+	assert.Equal(t, 100, uncertaintyService.samples)
+}
+
+// Tests the GetUncertaintyService return an UncertaintyError when the accuracy or the samples are not above zero.
+func TestGetUncertaintyServiceReturnErrorWhenSettingsAreInvalid(t *testing.T) {
+	uncertaintyService, err := GetUncertaintyService(
+		fares.TariffStrategy,
+		fares.FareCalculatorConfig{},
+		rides.SpeedPositionFilter,
+		rides.PositionFilterConfig{},
+		0,
+		100,
+		1,
+	)
+	assert.Nil(t, uncertaintyService)
+	assert.Equal(t, NewUncertaintyError(InvalidAccuracy, "gps accuracy: 0 meters must be above zero"), err)
+
+	uncertaintyService, err = GetUncertaintyService(
+		fares.TariffStrategy,
+		fares.FareCalculatorConfig{},
+		rides.SpeedPositionFilter,
+		rides.PositionFilterConfig{},
+		10,
+		-1,
+		1,
+	)
+	assert.Nil(t, uncertaintyService)
+	assert.Equal(t, NewUncertaintyError(InvalidSamples, "samples: -1 must be above zero"), err)
+}
+
+// Tests the GetUncertaintyService return a rides.PositionFilterError when a position filter is not supported.
+func TestGetUncertaintyServiceReturnErrorWhenPositionFilterIsInvalid(t *testing.T) {
+	uncertaintyService, err := GetUncertaintyService(
+		fares.TariffStrategy,
+		fares.FareCalculatorConfig{},
+		"speed,particle",
+		rides.PositionFilterConfig{},
+		10,
+		100,
+		1,
+	)
+	assert.Nil(t, uncertaintyService)
+	assert.True(t, errors.Is(err, rides.UnsupportedPositionFilter))
+}
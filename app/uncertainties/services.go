@@ -0,0 +1,116 @@
+/*
+Package uncertainties
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package uncertainties
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/fares"
+	"github.com/iliaskaras/fare-estimation/app/money"
+	"github.com/iliaskaras/fare-estimation/app/rides"
+	"math/rand"
+	"sort"
+)
+
+// The default Monte Carlo settings, which the command line can override.
+// - DefaultSamples: The number of times that the RidePositions of each ride are perturbed.
+// - DefaultSeed: The seed of the random perturbations, so that the fare ranges are reproducible.
+const (
+	DefaultSamples int   = 100
+	DefaultSeed    int64 = 1
+)
+
+type UncertaintyService struct {
+	ridePositionService *rides.RidePositionService
+	fareCalculator      fares.FareCalculator
+	accuracyMeters      float64
+	samples             int
+	seed                int64
+}
+
+func NewUncertaintyService(
+	ridePositionService *rides.RidePositionService,
+	fareCalculator fares.FareCalculator,
+	accuracyMeters float64,
+	samples int,
+	seed int64,
+) *UncertaintyService {
+	return &UncertaintyService{
+		ridePositionService: ridePositionService,
+		fareCalculator:      fareCalculator,
+		accuracyMeters:      accuracyMeters,
+		samples:             samples,
+		seed:                seed,
+	}
+}
+
+// Estimate filters the RidePositions of each RideID on segment speed and estimates their Fare, as the
+// RidePositionService and the FareService do, along with the Fare's FareUncertainty under GPS noise.
+// - Receiver of the channel ridePositionsChan,
+// - Pusher to the channel faresChan, where all the estimated Fare are pushed.
+func (us *UncertaintyService) Estimate(
+	ridePositionsChan <-chan []rides.RidePosition,
+	faresChan chan<- fares.Fare,
+) {
+	for ridePositions := range ridePositionsChan {
+		rideSegments := us.ridePositionService.RecordRide(ridePositions)
+		// Case where the RideID had only one RidePosition left in the input file.
+		if rideSegments == nil {
+			continue
+		}
+
+		fare := us.fareCalculator.Calculate(rideSegments)
+		fare.Uncertainty = us.Range(ridePositions)
+
+		faresChan <- fare
+	}
+}
+
+// Summary returns the rides.FilterSummary of the rides estimated, leaving out their perturbed samples,
+// to be read once Estimate has returned.
+func (us *UncertaintyService) Summary() rides.FilterSummary {
+	return us.ridePositionService.Summary()
+}
+
+// Range returns the FareUncertainty of a single RideID's RidePositions, by perturbing each of them within
+// the accuracy radius, re-filtering them on segment speed and re-estimating the fare, as many times as
+// the samples, and taking the 5th, 50th and 95th percentile of the fares. The perturbations are seeded
+// with the seed and the RideID, so that they are reproducible. The samples left without any RideSegment
+// are skipped, and nil is returned when every sample is.
+func (us *UncertaintyService) Range(ridePositions []rides.RidePosition) *fares.FareUncertainty {
+	if len(ridePositions) == 0 {
+		return nil
+	}
+
+	random := rand.New(rand.NewSource(rideSeed(us.seed, ridePositions[0].Id)))
+	perturbedRidePositions := make([]rides.RidePosition, len(ridePositions))
+	var amounts []money.Money
+
+	for sample := 0; sample < us.samples; sample++ {
+		for i, ridePosition := range ridePositions {
+			perturbedRidePositions[i] = perturb(ridePosition, us.accuracyMeters, random)
+		}
+
+		rideSegments := us.ridePositionService.FilterRide(perturbedRidePositions)
+		if rideSegments == nil {
+			continue
+		}
+
+		amounts = append(amounts, us.fareCalculator.Calculate(rideSegments).Estimation())
+	}
+
+	if len(amounts) == 0 {
+		return nil
+	}
+
+	sort.Slice(amounts, func(i, j int) bool {
+		return amounts[i].Cmp(amounts[j]) < 0
+	})
+
+	return &fares.FareUncertainty{
+		P5:      percentile(amounts, 5),
+		P50:     percentile(amounts, 50),
+		P95:     percentile(amounts, 95),
+		Samples: len(amounts),
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Package uncertainties
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package uncertainties
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/distances"
+	"github.com/iliaskaras/fare-estimation/app/fares"
+	"github.com/iliaskaras/fare-estimation/app/rides"
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"testing"
+)
+
+// ridePositions are the RidePositions of a ride moving at day time, with its second position being erroneous.
+var ridePositions = []rides.RidePosition{
+	{Id: 1, Lat: 37.966660, Lng: 23.728308, Timestamp: 1405594957},
+	{Id: 1, Lat: 37.866660, Lng: 23.728308, Timestamp: 1405594966},
+	{Id: 1, Lat: 37.966613, Lng: 23.728375, Timestamp: 1405594984},
+	{Id: 1, Lat: 37.954302, Lng: 23.713370, Timestamp: 1405595284},
+	{Id: 1, Lat: 37.938042, Lng: 23.692308, Timestamp: 1405595562},
+}
+
+// mustGetUncertaintyService returns the UncertaintyService of the built-in tariff.
+func mustGetUncertaintyService(t *testing.T, accuracyMeters float64, samples int, seed int64) *UncertaintyService {
+	uncertaintyService, err := GetUncertaintyService(
+		fares.TariffStrategy,
+		fares.FareCalculatorConfig{},
+		rides.SpeedPositionFilter,
+		rides.PositionFilterConfig{RecoveryRejections: rides.RecoveryRejections},
+		accuracyMeters,
+		samples,
+		seed,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return uncertaintyService
+}
+
+// Tests the UncertaintyService.Range returns the percentiles of the perturbed fares, around the fare.
+func TestRangeSuccessfulExecution(t *testing.T) {
+	uncertaintyService := mustGetUncertaintyService(t, 10, 200, DefaultSeed)
+	fare := uncertaintyService.fareCalculator.Calculate(uncertaintyService.ridePositionService.FilterRide(ridePositions))
+
+	fareUncertainty := uncertaintyService.Range(ridePositions)
+
+	assert.Equal(t, 200, fareUncertainty.Samples)
+	assert.True(t, fareUncertainty.P5.Cmp(fareUncertainty.P50) <= 0)
+	assert.True(t, fareUncertainty.P50.Cmp(fareUncertainty.P95) <= 0)
+	assert.True(t, fareUncertainty.P5.Cmp(fareUncertainty.P95) < 0)
+	assert.InDelta(t, fare.Estimation().Float64(), fareUncertainty.P50.Float64(), 0.1)
+}
+
+// Tests the UncertaintyService.Range is reproducible with the same seed, and varies with the seed.
+func TestRangeIsReproducible(t *testing.T) {
+	fareUncertainty := mustGetUncertaintyService(t, 10, 50, 7).Range(ridePositions)
+
+	assert.Equal(t, fareUncertainty, mustGetUncertaintyService(t, 10, 50, 7).Range(ridePositions))
+	assert.NotEqual(t, fareUncertainty, mustGetUncertaintyService(t, 10, 50, 8).Range(ridePositions))
+}
+
+// Tests the UncertaintyService.Range returns nil when no sample has a RideSegment left.
+func TestRangeWhenNoSampleHasRideSegments(t *testing.T) {
+	uncertaintyService := mustGetUncertaintyService(t, 10, 10, DefaultSeed)
+
+	assert.Nil(t, uncertaintyService.Range(ridePositions[:1]))
+	assert.Nil(t, uncertaintyService.Range(nil))
+}
+
+// Tests the UncertaintyService.Estimate filters and estimates the Fare of each ride along with its FareUncertainty.
+func TestEstimateSuccessfulExecution(t *testing.T) {
+	ridePositionsChan := make(chan []rides.RidePosition)
+	faresChan := make(chan fares.Fare)
+	uncertaintyService := mustGetUncertaintyService(t, 10, 20, DefaultSeed)
+
+	go func() {
+		ridePositionsChan <- ridePositions
+		ridePositionsChan <- ridePositions[:1]
+		close(ridePositionsChan)
+	}()
+
+	go func() {
+		uncertaintyService.Estimate(ridePositionsChan, faresChan)
+		close(faresChan)
+	}()
+
+	var estimatedFares []fares.Fare
+	for fare := range faresChan {
+		estimatedFares = append(estimatedFares, fare)
+	}
+
+	expectedFare := uncertaintyService.fareCalculator.Calculate(
+		uncertaintyService.ridePositionService.FilterRide(ridePositions),
+	)
+
+	assert.Len(t, estimatedFares, 1)
+	assert.Equal(t, expectedFare.Estimation(), estimatedFares[0].Estimation())
+	assert.Equal(t, uncertaintyService.Range(ridePositions), estimatedFares[0].Uncertainty)
+}
+
+// Tests the perturb moves the RidePosition within the accuracy radius.
+func TestPerturbStaysWithinTheAccuracy(t *testing.T) {
+	distanceCalculator, _ := distances.GetDistanceCalculatorService(distances.HaversineMethod)
+	random := rand.New(rand.NewSource(DefaultSeed))
+
+	for i := 0; i < 1000; i++ {
+		perturbed := perturb(ridePositions[0], 25, random)
+
+		assert.Equal(t, ridePositions[0].Id, perturbed.Id)
+		assert.Equal(t, ridePositions[0].Timestamp, perturbed.Timestamp)
+		assert.LessOrEqual(
+			t,
+			distanceCalculator.GetDistance(ridePositions[0].Lat, ridePositions[0].Lng, perturbed.Lat, perturbed.Lng),
+			0.025+1e-9,
+		)
+	}
+}
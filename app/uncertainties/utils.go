@@ -0,0 +1,44 @@
+/*
+Package uncertainties
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package uncertainties
+
+import (
+	"github.com/iliaskaras/fare-estimation/app/money"
+	"github.com/iliaskaras/fare-estimation/app/rides"
+	"github.com/iliaskaras/fare-estimation/app/stats"
+	"math"
+	"math/rand"
+)
+
+const (
+	earthMetersRadius = 6371000
+)
+
+// perturb returns the RidePosition moved to a random point within the accuracyMeters radius around it,
+// uniformly distributed over the disc.
+func perturb(ridePosition rides.RidePosition, accuracyMeters float64, random *rand.Rand) rides.RidePosition {
+	distance := accuracyMeters * math.Sqrt(random.Float64())
+	bearing := 2 * math.Pi * random.Float64()
+
+	latOffset := distance * math.Cos(bearing) / earthMetersRadius * 180 / math.Pi
+	lngOffset := distance * math.Sin(bearing) / earthMetersRadius * 180 / math.Pi /
+		math.Cos(ridePosition.Lat*math.Pi/180)
+
+	ridePosition.Lat += latOffset
+	ridePosition.Lng += lngOffset
+
+	return ridePosition
+}
+
+// rideSeed returns the seed of the random numbers of a RideID, so that the perturbations of a ride do
+// not depend on the order that the rides are processed in.
+func rideSeed(seed int64, rideID int) int64 {
+	return seed*1000003 + int64(rideID)
+}
+
+// percentile returns the percentile of the sorted amounts, with the nearest rank method.
+func percentile(sortedAmounts []money.Money, p float64) money.Money {
+	return sortedAmounts[stats.NearestRank(p, len(sortedAmounts))]
+}
@@ -0,0 +1,27 @@
+/*
+Package zones
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package zones
+
+import (
+	"errors"
+	baseAppErrors "github.com/iliaskaras/fare-estimation/app/infrastructure/errors"
+)
+
+type ZoneError struct {
+	baseAppErrors.BaseAppError
+}
+
+func NewZoneError(err error, additionalInfo string) ZoneError {
+	return ZoneError{
+		BaseAppError: baseAppErrors.NewBaseAppError(err, additionalInfo),
+	}
+}
+
+var (
+	UnsupportedZoneFileType = errors.New("unsupported zone file type")
+	UnsupportedGeometryType = errors.New("unsupported geometry type")
+	InvalidGeoJSON          = errors.New("invalid geojson")
+	MissingZoneName         = errors.New("missing zone name")
+)
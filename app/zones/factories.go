@@ -0,0 +1,27 @@
+/*
+Package zones
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package zones
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+var supportedZoneFileTypes = []string{".geojson", ".json"}
+
+// GetZoneService is responsible for returning the ZoneService, if the zone file type provided is supported.
+func GetZoneService(filePath string) (*ZoneService, error) {
+	fileExtension := strings.ToLower(filepath.Ext(filePath))
+
+	if fileExtension == ".geojson" || fileExtension == ".json" {
+		return NewZoneService(), nil
+	}
+
+	return nil, NewZoneError(
+		UnsupportedZoneFileType,
+		"provided zone file type: "+fileExtension+", "+
+			"must be one of the: "+strings.Join(supportedZoneFileTypes[:], ",")+" \n",
+	)
+}
@@ -0,0 +1,38 @@
+/*
+Package zones
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package zones
+
+import (
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Tests the GetZoneService return the ZoneService for the .geojson and .json zone files.
+func TestGetZoneService(t *testing.T) {
+	for _, filePath := range []string{"zones.geojson", "zones.json"} {
+		zoneService, err := GetZoneService(filePath)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "*zones.ZoneService", reflect.TypeOf(zoneService).String())
+	}
+}
+
+// Tests the GetZoneService return a ZoneError when the zone file type is unsupported.
+func TestGetZoneServiceReturnZoneErrorWhenFileTypeIsInvalid(t *testing.T) {
+	zoneService, err := GetZoneService("zones.kml")
+	assert.Error(t, err)
+
+	assert.Nil(t, zoneService)
+	assert.Equal(
+		t,
+		NewZoneError(
+			UnsupportedZoneFileType,
+			"provided zone file type: .kml, "+
+				"must be one of the: "+strings.Join(supportedZoneFileTypes[:], ",")+" \n",
+		), err,
+	)
+}
@@ -0,0 +1,48 @@
+/*
+Package zones
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package zones
+
+// Point is a position in degrees.
+type Point struct {
+	Lat float64
+	Lng float64
+}
+
+func NewPoint(lat, lng float64) *Point {
+	return &Point{
+		lat,
+		lng,
+	}
+}
+
+// Polygon is made of linear rings, the first one being its exterior and the rest its holes.
+type Polygon [][]Point
+
+// Zone is a named area made of one or more Polygon.
+// - Properties: The properties of the zone's GeoJSON feature, besides its name.
+type Zone struct {
+	Name       string
+	Polygons   []Polygon
+	Properties map[string]interface{}
+}
+
+func NewZone(name string, polygons []Polygon, properties map[string]interface{}) *Zone {
+	return &Zone{
+		name,
+		polygons,
+		properties,
+	}
+}
+
+// Contains returns whether the position falls in any of the Zone's Polygon.
+func (z *Zone) Contains(lat, lng float64) bool {
+	for _, polygon := range z.Polygons {
+		if polygonContains(polygon, lat, lng) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,72 @@
+/*
+Package zones
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package zones
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// squareRing returns a closed linear ring of the square between the provided corners.
+func squareRing(minLat, minLng, maxLat, maxLng float64) []Point {
+	return []Point{
+		{Lat: minLat, Lng: minLng},
+		{Lat: minLat, Lng: maxLng},
+		{Lat: maxLat, Lng: maxLng},
+		{Lat: maxLat, Lng: minLng},
+		{Lat: minLat, Lng: minLng},
+	}
+}
+
+// Tests the Zone Contains method on positions inside, outside and in the hole of its Polygon.
+func TestZoneContains(t *testing.T) {
+	zone := NewZone(
+		"athens",
+		[]Polygon{
+			{
+				squareRing(37.90, 23.65, 38.05, 23.80),
+				// Hole.
+				squareRing(37.95, 23.70, 37.97, 23.72),
+			},
+			// Second Polygon of a MultiPolygon.
+			{
+				squareRing(37.93, 23.90, 37.95, 23.95),
+			},
+		},
+		nil,
+	)
+
+	assert.Equal(t, true, zone.Contains(37.975, 23.735))
+	assert.Equal(t, true, zone.Contains(37.94, 23.93))
+	assert.Equal(t, false, zone.Contains(37.96, 23.71))
+	assert.Equal(t, false, zone.Contains(38.10, 23.735))
+	assert.Equal(t, false, zone.Contains(37.975, 23.85))
+}
+
+// Tests the Zone Contains method on a concave Polygon.
+func TestZoneContainsConcavePolygon(t *testing.T) {
+	// An L shaped Polygon, missing its upper right quarter.
+	zone := NewZone(
+		"piraeus",
+		[]Polygon{
+			{
+				{
+					{Lat: 0, Lng: 0},
+					{Lat: 0, Lng: 2},
+					{Lat: 1, Lng: 2},
+					{Lat: 1, Lng: 1},
+					{Lat: 2, Lng: 1},
+					{Lat: 2, Lng: 0},
+					{Lat: 0, Lng: 0},
+				},
+			},
+		},
+		nil,
+	)
+
+	assert.Equal(t, true, zone.Contains(0.5, 1.5))
+	assert.Equal(t, true, zone.Contains(1.5, 0.5))
+	assert.Equal(t, false, zone.Contains(1.5, 1.5))
+}
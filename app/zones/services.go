@@ -0,0 +1,145 @@
+/*
+Package zones
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package zones
+
+import (
+	"encoding/json"
+	baseAppErrors "github.com/iliaskaras/fare-estimation/app/infrastructure/errors"
+	"os"
+)
+
+const (
+	nameProperty = "name"
+)
+
+// featureCollection is a GeoJSON FeatureCollection, holding only what is needed for the zones.
+type featureCollection struct {
+	Type     string    `json:"type"`
+	Features []feature `json:"features"`
+}
+
+type feature struct {
+	Type       string                 `json:"type"`
+	Properties map[string]interface{} `json:"properties"`
+	Geometry   geometry               `json:"geometry"`
+}
+
+type geometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+type ZoneService struct{}
+
+func NewZoneService() *ZoneService {
+	return &ZoneService{}
+}
+
+// Load parses a GeoJSON FeatureCollection file, returning a Zone for each of its Polygon or
+// MultiPolygon features, in the order they are found in the file. Each feature must have a
+// name property, and the rest of its properties are kept in the Zone's Properties.
+func (zs *ZoneService) Load(filePath string) ([]Zone, error) {
+	if filePath == "" {
+		return nil, baseAppErrors.NewBaseAppError(
+			baseAppErrors.InvalidInputError,
+			"zone file path is missing",
+		)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, NewZoneError(err, "unable to open the zone file")
+	}
+
+	var collection featureCollection
+	if err := json.Unmarshal(content, &collection); err != nil {
+		return nil, NewZoneError(InvalidGeoJSON, "unable to decode the zone file: "+err.Error())
+	}
+	if collection.Type != "FeatureCollection" {
+		return nil, NewZoneError(InvalidGeoJSON, "the zone file must be a FeatureCollection")
+	}
+
+	var zones []Zone
+
+	for _, feature := range collection.Features {
+		name, _ := feature.Properties[nameProperty].(string)
+		if name == "" {
+			return nil, NewZoneError(MissingZoneName, "every zone feature must have a name property")
+		}
+
+		polygons, err := decodePolygons(feature.Geometry)
+		if err != nil {
+			return nil, NewZoneError(err, "zone: "+name+" has an invalid geometry")
+		}
+
+		properties := make(map[string]interface{})
+		for key, value := range feature.Properties {
+			if key != nameProperty {
+				properties[key] = value
+			}
+		}
+
+		zones = append(zones, *NewZone(name, polygons, properties))
+	}
+
+	return zones, nil
+}
+
+// Find returns the first of the zones that the position falls in, or nil if it falls in none of them.
+func Find(zones []Zone, lat, lng float64) *Zone {
+	for i := range zones {
+		if zones[i].Contains(lat, lng) {
+			return &zones[i]
+		}
+	}
+
+	return nil
+}
+
+// decodePolygons decodes the coordinates of a Polygon or MultiPolygon geometry. The
+// GeoJSON positions are ordered as [longitude, latitude].
+func decodePolygons(geometry geometry) ([]Polygon, error) {
+	var rawPolygons [][][][]float64
+
+	switch geometry.Type {
+	case "Polygon":
+		var rawPolygon [][][]float64
+		if err := json.Unmarshal(geometry.Coordinates, &rawPolygon); err != nil {
+			return nil, InvalidGeoJSON
+		}
+		rawPolygons = [][][][]float64{rawPolygon}
+	case "MultiPolygon":
+		if err := json.Unmarshal(geometry.Coordinates, &rawPolygons); err != nil {
+			return nil, InvalidGeoJSON
+		}
+	default:
+		return nil, UnsupportedGeometryType
+	}
+
+	polygons := make([]Polygon, len(rawPolygons))
+
+	for i, rawPolygon := range rawPolygons {
+		polygon := make(Polygon, len(rawPolygon))
+
+		for j, rawRing := range rawPolygon {
+			if len(rawRing) < 3 {
+				return nil, InvalidGeoJSON
+			}
+
+			ring := make([]Point, len(rawRing))
+			for k, rawPosition := range rawRing {
+				if len(rawPosition) < 2 {
+					return nil, InvalidGeoJSON
+				}
+				ring[k] = *NewPoint(rawPosition[1], rawPosition[0])
+			}
+			polygon[j] = ring
+		}
+
+		polygons[i] = polygon
+	}
+
+	return polygons, nil
+}
@@ -0,0 +1,117 @@
+/*
+Package zones
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package zones
+
+import (
+	"github.com/Flaque/filet"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// Tests the ZoneService.Load loads the Polygon and MultiPolygon features of a GeoJSON file.
+func TestZoneServiceLoadSuccessfulExecution(t *testing.T) {
+	defer filet.CleanUp(t)
+	testZoneFile := filet.TmpFile(
+		t,
+		"",
+		`{
+			"type": "FeatureCollection",
+			"features": [
+				{
+					"type": "Feature",
+					"properties": {"name": "athens", "tariff": "athens.yaml"},
+					"geometry": {
+						"type": "Polygon",
+						"coordinates": [[[23.65, 37.90], [23.80, 37.90], [23.80, 38.05], [23.65, 38.05], [23.65, 37.90]]]
+					}
+				},
+				{
+					"type": "Feature",
+					"properties": {"name": "islands"},
+					"geometry": {
+						"type": "MultiPolygon",
+						"coordinates": [
+							[[[23.40, 37.70], [23.50, 37.70], [23.50, 37.80], [23.40, 37.70]]],
+							[[[23.30, 37.40], [23.35, 37.40], [23.35, 37.45], [23.30, 37.40]]]
+						]
+					}
+				}
+			]
+		}`,
+	)
+
+	zones, err := NewZoneService().Load(testZoneFile.Name())
+	assert.NoError(t, err)
+
+	expectedZones := []Zone{
+		*NewZone(
+			"athens",
+			[]Polygon{{squareRing(37.90, 23.65, 38.05, 23.80)}},
+			map[string]interface{}{"tariff": "athens.yaml"},
+		),
+		*NewZone(
+			"islands",
+			[]Polygon{
+				{{{Lat: 37.70, Lng: 23.40}, {Lat: 37.70, Lng: 23.50}, {Lat: 37.80, Lng: 23.50}, {Lat: 37.70, Lng: 23.40}}},
+				{{{Lat: 37.40, Lng: 23.30}, {Lat: 37.40, Lng: 23.35}, {Lat: 37.45, Lng: 23.35}, {Lat: 37.40, Lng: 23.30}}},
+			},
+			map[string]interface{}{},
+		),
+	}
+
+	assert.Equal(t, expectedZones, zones)
+}
+
+// Tests the ZoneService.Load return a ZoneError on the features it cannot turn into a Zone.
+func TestZoneServiceLoadReturnErrorOnInvalidFeatures(t *testing.T) {
+	defer filet.CleanUp(t)
+
+	testCases := []struct {
+		content       string
+		expectedError ZoneError
+	}{
+		{
+			content:       `{"type": "Feature"}`,
+			expectedError: NewZoneError(InvalidGeoJSON, "the zone file must be a FeatureCollection"),
+		},
+		{
+			content: `{"type": "FeatureCollection", "features": [{"type": "Feature", "properties": {}, ` +
+				`"geometry": {"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 0]]]}}]}`,
+			expectedError: NewZoneError(MissingZoneName, "every zone feature must have a name property"),
+		},
+		{
+			content: `{"type": "FeatureCollection", "features": [{"type": "Feature", "properties": {"name": "gate"}, ` +
+				`"geometry": {"type": "Point", "coordinates": [0, 0]}}]}`,
+			expectedError: NewZoneError(UnsupportedGeometryType, "zone: gate has an invalid geometry"),
+		},
+		{
+			content: `{"type": "FeatureCollection", "features": [{"type": "Feature", "properties": {"name": "line"}, ` +
+				`"geometry": {"type": "Polygon", "coordinates": [[[0, 0], [1, 0]]]}}]}`,
+			expectedError: NewZoneError(InvalidGeoJSON, "zone: line has an invalid geometry"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testZoneFile := filet.TmpFile(t, "", testCase.content)
+
+		zones, err := NewZoneService().Load(testZoneFile.Name())
+		assert.Error(t, err)
+
+		assert.Nil(t, zones)
+		assert.Equal(t, testCase.expectedError, err)
+	}
+}
+
+// Tests the Find returns the first Zone that the position falls in.
+func TestFind(t *testing.T) {
+	zones := []Zone{
+		*NewZone("centre", []Polygon{{squareRing(37.95, 23.70, 38.00, 23.76)}}, nil),
+		*NewZone("athens", []Polygon{{squareRing(37.90, 23.65, 38.05, 23.80)}}, nil),
+	}
+
+	assert.Equal(t, "centre", Find(zones, 37.975, 23.735).Name)
+	assert.Equal(t, "athens", Find(zones, 37.92, 23.66).Name)
+	assert.Nil(t, Find(zones, 38.10, 23.735))
+}
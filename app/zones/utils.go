@@ -0,0 +1,38 @@
+/*
+Package zones
+Copyright © 2022 Ilias Karatsin <hlias.karas.apps@gmail.com>
+*/
+package zones
+
+// polygonContains returns whether the position falls in the Polygon's exterior ring and in none of its holes.
+func polygonContains(polygon Polygon, lat, lng float64) bool {
+	if len(polygon) == 0 || !ringContains(polygon[0], lat, lng) {
+		return false
+	}
+
+	for _, hole := range polygon[1:] {
+		if ringContains(hole, lat, lng) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ringContains returns whether the position falls in the linear ring, using the ray casting
+// algorithm on the lat/lng plane, which is accurate enough for city sized areas.
+func ringContains(ring []Point, lat, lng float64) bool {
+	contains := false
+
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a := ring[i]
+		b := ring[j]
+
+		if (a.Lat > lat) != (b.Lat > lat) &&
+			lng < (b.Lng-a.Lng)*(lat-a.Lat)/(b.Lat-a.Lat)+a.Lng {
+			contains = !contains
+		}
+	}
+
+	return contains
+}
@@ -6,6 +6,9 @@ package main
 
 import (
 	"github.com/iliaskaras/fare-estimation/app/cmd"
+	// Embeds the IANA timezone database, so that the tariff timezones resolve
+	// on systems without one installed.
+	_ "time/tzdata"
 )
 
 func main() {